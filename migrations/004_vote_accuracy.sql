@@ -0,0 +1,30 @@
+-- Migration 004: Vote Accuracy Tracking
+-- RealTube - Crowdsourced AI video flagging
+-- Depends on: 001_core_tables.sql, 002_channels_users.sql
+
+BEGIN;
+
+-- ============================================================
+-- VOTE VERDICTS
+-- ============================================================
+
+-- accurate: whether the vote agreed with its video's settled consensus
+--           (NULL until the video settles for the first time)
+-- judged_at: when the verdict was last evaluated; a vote is re-judged
+--            once its video changes again after this timestamp
+ALTER TABLE votes
+    ADD COLUMN accurate  BOOLEAN,
+    ADD COLUMN judged_at TIMESTAMPTZ;
+
+CREATE INDEX idx_votes_unjudged ON votes(video_id) WHERE judged_at IS NULL;
+
+-- ============================================================
+-- USER ACCURACY COUNTERS
+-- ============================================================
+
+-- judged_votes is the denominator of accuracy_rate: only votes on settled
+-- videos count, so pending votes don't drag a user's accuracy down.
+ALTER TABLE users
+    ADD COLUMN judged_votes INTEGER DEFAULT 0;
+
+COMMIT;
@@ -0,0 +1,25 @@
+-- Migration 005: User Vote Totals
+-- RealTube - Crowdsourced AI video flagging
+-- Depends on: 001_core_tables.sql, 002_channels_users.sql
+
+BEGIN;
+
+-- ============================================================
+-- BACKFILL users.total_votes
+-- ============================================================
+
+-- total_votes feeds the trust volume factor and the 10-vote accuracy
+-- minimum. It is maintained by vote submission/deletion from now on;
+-- bring existing rows in line with the votes table once.
+UPDATE users u
+SET total_votes = COALESCE(v.cnt, 0)
+FROM (
+    SELECT user_id, COUNT(*) AS cnt
+    FROM votes
+    GROUP BY user_id
+) v
+WHERE v.user_id = u.user_id;
+
+CREATE INDEX idx_users_total_votes ON users(user_id) WHERE total_votes > 0;
+
+COMMIT;
@@ -0,0 +1,36 @@
+-- Migration 006: Trust History & Inactivity Decay
+-- RealTube - Crowdsourced AI video flagging
+-- Depends on: 002_channels_users.sql
+
+BEGIN;
+
+-- ============================================================
+-- USER TRUST HISTORY TABLE
+-- ============================================================
+
+-- One row per trust recomputation that changed the user's trust score or
+-- activity factor (unchanged recomputations are not recorded).
+CREATE TABLE user_trust_history (
+    id              BIGSERIAL PRIMARY KEY,
+    user_id         VARCHAR(64) NOT NULL REFERENCES users(user_id) ON DELETE CASCADE,
+    trust_score     FLOAT NOT NULL,
+    accuracy_rate   FLOAT NOT NULL,
+    total_votes     INTEGER NOT NULL,
+    activity_factor FLOAT NOT NULL,
+    recorded_at     TIMESTAMPTZ DEFAULT NOW()
+);
+
+CREATE INDEX idx_user_trust_history_user ON user_trust_history(user_id, recorded_at DESC);
+
+-- ============================================================
+-- INACTIVITY DECAY STATE
+-- ============================================================
+
+-- When a dormant user votes again, the decay reached during dormancy is
+-- frozen in dormancy_factor and recovers linearly from reactivated_at,
+-- so a revived account does not immediately vote at full weight.
+ALTER TABLE users
+    ADD COLUMN dormancy_factor FLOAT DEFAULT 1.0,
+    ADD COLUMN reactivated_at  TIMESTAMPTZ;
+
+COMMIT;
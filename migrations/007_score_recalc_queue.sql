@@ -0,0 +1,47 @@
+-- Migration 007: Durable Score Recalculation Queue
+-- RealTube - Crowdsourced AI video flagging
+-- Depends on: 003_cache_triggers.sql
+
+BEGIN;
+
+-- ============================================================
+-- SCORE RECALCULATION QUEUE
+-- ============================================================
+
+-- One row per video waiting for its score to be recalculated. The score
+-- worker deletes rows in the same transaction that rescores the video, so a
+-- crash or lost NOTIFY leaves the row in place for the next drain.
+CREATE TABLE score_recalc_queue (
+    video_id        VARCHAR(16) PRIMARY KEY REFERENCES videos(video_id) ON DELETE CASCADE,
+    queued_at       TIMESTAMPTZ DEFAULT NOW()
+);
+
+CREATE INDEX idx_score_recalc_queue_queued ON score_recalc_queue(queued_at);
+
+-- ============================================================
+-- VOTE CHANGE TRIGGER
+-- ============================================================
+
+-- Enqueue the video, then NOTIFY as a wake-up hint only.
+CREATE OR REPLACE FUNCTION notify_vote_change() RETURNS TRIGGER AS $$
+BEGIN
+  INSERT INTO score_recalc_queue (video_id) VALUES (NEW.video_id)
+  ON CONFLICT (video_id) DO NOTHING;
+  PERFORM pg_notify('vote_changes', NEW.video_id);
+  RETURN NEW;
+END;
+$$ LANGUAGE plpgsql;
+
+-- Only changes that affect the score queue a recalculation; accuracy
+-- verdicts written by the accuracy worker do not.
+DROP TRIGGER vote_inserted ON votes;
+CREATE TRIGGER vote_inserted AFTER INSERT OR UPDATE OF category, trust_weight ON votes
+FOR EACH ROW EXECUTE FUNCTION notify_vote_change();
+
+-- Rescore every voted video once, in case notifications were lost before
+-- the queue existed.
+INSERT INTO score_recalc_queue (video_id)
+SELECT DISTINCT video_id FROM votes
+ON CONFLICT (video_id) DO NOTHING;
+
+COMMIT;
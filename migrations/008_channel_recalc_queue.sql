@@ -0,0 +1,22 @@
+-- Migration 008: Channel Recalculation Queue
+-- RealTube - Crowdsourced AI video flagging
+-- Depends on: 007_score_recalc_queue.sql
+
+BEGIN;
+
+-- ============================================================
+-- CHANNEL RECALCULATION QUEUE
+-- ============================================================
+
+-- One row per channel whose videos were rescored since the channel score was
+-- last computed. Filled by the score worker in the transaction that rescores
+-- the videos; drained by the channel worker every few seconds. A channel
+-- rescored many times within one drain window is recalculated once.
+CREATE TABLE channel_recalc_queue (
+    channel_id      VARCHAR(32) PRIMARY KEY,
+    queued_at       TIMESTAMPTZ DEFAULT NOW()
+);
+
+CREATE INDEX idx_channel_recalc_queue_queued ON channel_recalc_queue(queued_at);
+
+COMMIT;
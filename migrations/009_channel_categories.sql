@@ -0,0 +1,24 @@
+-- Migration 009: Per-Category Channel Scores
+-- RealTube - Crowdsourced AI video flagging
+-- Depends on: 002_channels_users.sql
+
+BEGIN;
+
+-- ============================================================
+-- CHANNEL CATEGORIES TABLE
+-- ============================================================
+
+-- Per-category aggregates over a channel's tracked videos (total_votes > 0),
+-- maintained by the channel worker alongside channels.top_category.
+-- flagged_videos: tracked videos whose weighted_score in this category >= 50
+-- weighted_score: average weighted_score of this category across all tracked
+--                 videos (videos without votes in the category count as 0)
+CREATE TABLE channel_categories (
+    channel_id      VARCHAR(32) REFERENCES channels(channel_id) ON DELETE CASCADE,
+    category        VARCHAR(20) NOT NULL,
+    flagged_videos  INTEGER DEFAULT 0,
+    weighted_score  FLOAT DEFAULT 0.0,
+    PRIMARY KEY (channel_id, category)
+);
+
+COMMIT;
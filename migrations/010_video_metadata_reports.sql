@@ -0,0 +1,27 @@
+-- Migration 010: Video Metadata Reports
+-- RealTube - Crowdsourced AI video flagging
+-- Depends on: 001_core_tables.sql, 002_channels_users.sql
+
+BEGIN;
+
+-- ============================================================
+-- VIDEO METADATA REPORTS
+-- ============================================================
+
+-- Metadata reported by the extension alongside a vote, one row per voter.
+-- NULL means the field was not reported. videos.channel_id, title,
+-- video_duration and is_short hold the reconciled values: for each field,
+-- the value backed by the highest total trust_weight of the reporters'
+-- current votes wins.
+CREATE TABLE video_metadata_reports (
+    video_id        VARCHAR(16) REFERENCES videos(video_id) ON DELETE CASCADE,
+    user_id         VARCHAR(64) REFERENCES users(user_id) ON DELETE CASCADE,
+    channel_id      VARCHAR(32),
+    title           TEXT,
+    video_duration  FLOAT,
+    is_short        BOOLEAN,
+    reported_at     TIMESTAMPTZ DEFAULT NOW(),
+    PRIMARY KEY (video_id, user_id)
+);
+
+COMMIT;
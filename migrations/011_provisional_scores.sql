@@ -0,0 +1,26 @@
+-- Migration 011: Provisional Scores
+-- RealTube - Crowdsourced AI video flagging
+-- Depends on: 001_core_tables.sql
+
+BEGIN;
+
+-- ============================================================
+-- PROVISIONAL MARKER
+-- ============================================================
+
+-- TRUE while a video's score is the preliminary 60% inherited from an
+-- auto-flagged channel rather than computed from votes. The channel worker
+-- sets it on vote-less rows of auto_flag_new channels; the score worker
+-- clears it whenever it recalculates the video from its votes.
+ALTER TABLE videos ADD COLUMN provisional BOOLEAN NOT NULL DEFAULT FALSE;
+
+-- Rows given the preliminary score before the marker existed.
+UPDATE videos v
+SET provisional = TRUE
+FROM channels c
+WHERE c.channel_id = v.channel_id
+  AND c.auto_flag_new
+  AND v.total_votes = 0
+  AND v.score = 60;
+
+COMMIT;
@@ -0,0 +1,26 @@
+-- Migration 012: Moderation
+-- RealTube - Crowdsourced AI video flagging
+-- Depends on: 002_channels_users.sql, 003_cache_triggers.sql
+
+BEGIN;
+
+-- ============================================================
+-- HIDDEN CHANNELS
+-- ============================================================
+
+-- Hidden channels are left out of channel lookups, sync and provisional
+-- scores. Their videos are not affected.
+ALTER TABLE channels ADD COLUMN hidden BOOLEAN NOT NULL DEFAULT FALSE;
+
+-- ============================================================
+-- VIP ACTION DETAILS
+-- ============================================================
+
+-- Action-specific audit data, e.g. the previous and new score of an
+-- override_score action.
+ALTER TABLE vip_actions ADD COLUMN details JSONB;
+
+CREATE INDEX idx_vip_actions_target ON vip_actions(target_type, target_id, created_at);
+CREATE INDEX idx_vip_actions_created ON vip_actions(created_at);
+
+COMMIT;
@@ -0,0 +1,28 @@
+-- Migration 013: Admin API Keys
+-- RealTube - Crowdsourced AI video flagging
+-- Depends on: 002_channels_users.sql
+
+BEGIN;
+
+-- ============================================================
+-- ADMIN KEYS
+-- ============================================================
+
+-- API keys for privileged endpoints, created with the adminkey command.
+-- Only the SHA256 of a key is stored; the key itself is shown once at
+-- creation. Revoked keys are kept so vip_actions rows still resolve.
+CREATE TABLE admin_keys (
+    id              BIGSERIAL PRIMARY KEY,
+    name            VARCHAR(64) NOT NULL,
+    key_prefix      VARCHAR(12) NOT NULL,
+    key_hash        VARCHAR(64) NOT NULL UNIQUE,
+    role            VARCHAR(16) NOT NULL CHECK (role IN ('moderator', 'admin', 'read-only-ops')),
+    created_at      TIMESTAMPTZ DEFAULT NOW(),
+    last_used_at    TIMESTAMPTZ,
+    revoked_at      TIMESTAMPTZ
+);
+
+-- Moderation actions taken with an admin key rather than as a VIP user
+ALTER TABLE vip_actions ADD COLUMN admin_key_id BIGINT REFERENCES admin_keys(id);
+
+COMMIT;
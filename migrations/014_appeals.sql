@@ -0,0 +1,46 @@
+-- Migration 014: Appeals
+-- RealTube - Crowdsourced AI video flagging
+-- Depends on: 013_admin_keys.sql
+
+BEGIN;
+
+-- ============================================================
+-- APPEALS
+-- ============================================================
+
+-- Disputes of a video or channel flag, processed by moderators. Lifecycle:
+-- open -> under_review -> upheld | rejected (open can be resolved directly).
+CREATE TABLE appeals (
+    id                  BIGSERIAL PRIMARY KEY,
+    target_type         VARCHAR(16) NOT NULL CHECK (target_type IN ('video', 'channel')),
+    target_id           VARCHAR(32) NOT NULL,
+    user_id             VARCHAR(64) NOT NULL,
+    reason              TEXT NOT NULL,
+    status              VARCHAR(16) NOT NULL DEFAULT 'open'
+                        CHECK (status IN ('open', 'under_review', 'upheld', 'rejected')),
+    reviewer_user_id    VARCHAR(64) REFERENCES users(user_id),
+    reviewer_key_id     BIGINT REFERENCES admin_keys(id),
+    resolution          TEXT,
+    action_id           BIGINT REFERENCES vip_actions(id),
+    created_at          TIMESTAMPTZ DEFAULT NOW(),
+    updated_at          TIMESTAMPTZ DEFAULT NOW(),
+    resolved_at         TIMESTAMPTZ
+);
+
+-- At most one pending appeal per target
+CREATE UNIQUE INDEX idx_appeals_pending_target ON appeals(target_type, target_id)
+    WHERE status IN ('open', 'under_review');
+
+-- Moderator queue
+CREATE INDEX idx_appeals_status ON appeals(status, id);
+
+-- ============================================================
+-- DISPUTED MARKER
+-- ============================================================
+
+-- Set while the video or channel has a pending appeal, and sent in sync
+ALTER TABLE videos ADD COLUMN disputed BOOLEAN NOT NULL DEFAULT FALSE;
+ALTER TABLE channels ADD COLUMN disputed BOOLEAN NOT NULL DEFAULT FALSE;
+ALTER TABLE sync_cache ADD COLUMN disputed BOOLEAN NOT NULL DEFAULT FALSE;
+
+COMMIT;
@@ -0,0 +1,15 @@
+-- Migration 015: IP Hash Vote Windows
+-- RealTube - Crowdsourced AI video flagging
+-- Depends on: 002_channels_users.sql
+
+BEGIN;
+
+-- ============================================================
+-- IP HASH VOTE WINDOWS
+-- ============================================================
+
+-- Start of the 24-hour window counted by vote_count_24h. A vote after the
+-- window has passed starts a new one and clears rate_limited.
+ALTER TABLE ip_hashes ADD COLUMN window_start TIMESTAMPTZ NOT NULL DEFAULT NOW();
+
+COMMIT;
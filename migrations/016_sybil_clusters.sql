@@ -0,0 +1,51 @@
+-- Migration 016: Sybil Clusters
+-- RealTube - Crowdsourced AI video flagging
+-- Depends on: 013_admin_keys.sql, 015_ip_hash_windows.sql
+
+BEGIN;
+
+-- ============================================================
+-- SYBIL CLUSTERS
+-- ============================================================
+
+-- Groups of user IDs that vote from the same IP hash on the same videos,
+-- found by the sybil worker. While a cluster is dampened, its members split
+-- one voter's weight. Lifecycle: pending -> dampened -> dismissed (pending
+-- can be dismissed directly).
+CREATE TABLE sybil_clusters (
+    id                  BIGSERIAL PRIMARY KEY,
+    ip_hash             VARCHAR(64) NOT NULL,
+    user_count          INTEGER NOT NULL,
+    shared_videos       INTEGER NOT NULL,
+    status              VARCHAR(16) NOT NULL DEFAULT 'pending'
+                        CHECK (status IN ('pending', 'dampened', 'dismissed')),
+    reviewer_user_id    VARCHAR(64) REFERENCES users(user_id),
+    reviewer_key_id     BIGINT REFERENCES admin_keys(id),
+    detected_at         TIMESTAMPTZ DEFAULT NOW(),
+    updated_at          TIMESTAMPTZ DEFAULT NOW(),
+    reviewed_at         TIMESTAMPTZ
+);
+
+-- At most one active cluster per IP hash; later detections extend it
+CREATE UNIQUE INDEX idx_sybil_clusters_active_ip ON sybil_clusters(ip_hash)
+    WHERE status IN ('pending', 'dampened');
+
+-- Moderator queue
+CREATE INDEX idx_sybil_clusters_status ON sybil_clusters(status, id);
+
+CREATE TABLE sybil_cluster_members (
+    cluster_id      BIGINT NOT NULL REFERENCES sybil_clusters(id) ON DELETE CASCADE,
+    user_id         VARCHAR(64) NOT NULL REFERENCES users(user_id),
+    PRIMARY KEY (cluster_id, user_id)
+);
+
+CREATE INDEX idx_sybil_cluster_members_user ON sybil_cluster_members(user_id);
+
+-- The size of the largest dampened cluster the user belongs to: their vote
+-- weight is divided by it. 1 when the user is in no dampened cluster.
+ALTER TABLE users ADD COLUMN sybil_split INTEGER NOT NULL DEFAULT 1;
+
+-- Detection groups the recent votes of each IP hash by video
+CREATE INDEX idx_votes_ip_hash ON votes(ip_hash, video_id) WHERE ip_hash IS NOT NULL;
+
+COMMIT;
@@ -0,0 +1,44 @@
+-- Migration 017: Brigades
+-- RealTube - Crowdsourced AI video flagging
+-- Depends on: 013_admin_keys.sql
+
+BEGIN;
+
+-- ============================================================
+-- BRIGADES
+-- ============================================================
+
+-- Vote spikes on a video or a channel's videos, found by the score worker.
+-- While a brigade is frozen, its videos keep their pre-spike score. Frozen
+-- brigades expire at expires_at unless held by a moderator (no expiry).
+-- Lifecycle: frozen -> released (by a moderator) | expired.
+CREATE TABLE brigades (
+    id                  BIGSERIAL PRIMARY KEY,
+    target_type         VARCHAR(16) NOT NULL CHECK (target_type IN ('video', 'channel')),
+    target_id           VARCHAR(32) NOT NULL,
+    reason              VARCHAR(16) NOT NULL CHECK (reason IN ('velocity', 'new_accounts')),
+    window_votes        INTEGER NOT NULL,
+    baseline_votes      INTEGER NOT NULL,
+    new_account_votes   INTEGER NOT NULL,
+    frozen_score        FLOAT NOT NULL,
+    status              VARCHAR(16) NOT NULL DEFAULT 'frozen'
+                        CHECK (status IN ('frozen', 'released', 'expired')),
+    reviewer_user_id    VARCHAR(64) REFERENCES users(user_id),
+    reviewer_key_id     BIGINT REFERENCES admin_keys(id),
+    detected_at         TIMESTAMPTZ DEFAULT NOW(),
+    expires_at          TIMESTAMPTZ,
+    reviewed_at         TIMESTAMPTZ
+);
+
+-- At most one frozen brigade per target
+CREATE UNIQUE INDEX idx_brigades_frozen_target ON brigades(target_type, target_id)
+    WHERE status = 'frozen';
+
+-- Moderator queue
+CREATE INDEX idx_brigades_status ON brigades(status, id);
+
+-- Set while a frozen brigade covers the video: the score worker leaves its
+-- score alone until the brigade is released or expires
+ALTER TABLE videos ADD COLUMN frozen BOOLEAN NOT NULL DEFAULT FALSE;
+
+COMMIT;
@@ -0,0 +1,21 @@
+-- Migration 018: Score Change Timestamp
+-- RealTube - Crowdsourced AI video flagging
+-- Depends on: 004_vote_accuracy.sql
+
+BEGIN;
+
+-- ============================================================
+-- VIDEO SCORE CHANGES
+-- ============================================================
+
+-- score_changed_at: when the score last moved enough to matter. Unlike
+-- last_updated, rescores that leave the score in place (e.g. trust
+-- re-weighting) don't touch it, so videos can settle for vote accuracy.
+ALTER TABLE videos
+    ADD COLUMN score_changed_at TIMESTAMPTZ NOT NULL DEFAULT NOW();
+
+UPDATE videos SET score_changed_at = COALESCE(last_updated, first_reported, NOW());
+
+CREATE INDEX idx_videos_score_changed_at ON videos(score_changed_at);
+
+COMMIT;
@@ -0,0 +1,124 @@
+// Command adminkey manages the admin API keys that authenticate callers of
+// the privileged endpoints (/api/vip, /api/admin, /api/ops).
+//
+//	adminkey create -name=grafana -role=read-only-ops
+//	adminkey list
+//	adminkey revoke -id=3
+//
+// Roles are moderator, admin and read-only-ops. A new key is printed once on
+// stdout and only its hash is stored, so it can't be shown again: revoke it
+// and create another if it is lost. The database comes from the same
+// environment as the server (DATABASE_URL or DB_*).
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"text/tabwriter"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+
+	"github.com/mathieu-neron/RealTube/realtube-go/internal/config"
+	"github.com/mathieu-neron/RealTube/realtube-go/internal/db"
+	"github.com/mathieu-neron/RealTube/realtube-go/internal/middleware"
+	"github.com/mathieu-neron/RealTube/realtube-go/internal/model"
+	"github.com/mathieu-neron/RealTube/realtube-go/internal/repository"
+	"github.com/mathieu-neron/RealTube/realtube-go/internal/service"
+)
+
+const usage = `usage:
+  adminkey create -name=NAME -role=ROLE
+  adminkey list
+  adminkey revoke -id=ID`
+
+func main() {
+	if len(os.Args) < 2 {
+		log.Fatal(usage)
+	}
+
+	ctx := context.Background()
+	cmd, args := os.Args[1], os.Args[2:]
+
+	switch cmd {
+	case "create":
+		fs := flag.NewFlagSet("create", flag.ExitOnError)
+		name := fs.String("name", "", "what the key is for, e.g. grafana")
+		role := fs.String("role", "", "moderator, admin or read-only-ops")
+		fs.Parse(args)
+
+		keyName, errMsg := middleware.ValidateKeyName(*name)
+		if errMsg != "" {
+			log.Fatalf("adminkey: %s", errMsg)
+		}
+		if !model.ValidRoles[*role] {
+			log.Fatalf("adminkey: unknown role %q (moderator, admin, read-only-ops)", *role)
+		}
+
+		auth := newAuthService(ctx)
+		key, k, err := auth.CreateKey(ctx, keyName, *role)
+		if err != nil {
+			log.Fatalf("adminkey: %v", err)
+		}
+		log.Printf("adminkey: created key %d (%s, %s); it will not be shown again", k.ID, k.Name, k.Role)
+		fmt.Println(key)
+
+	case "list":
+		fs := flag.NewFlagSet("list", flag.ExitOnError)
+		fs.Parse(args)
+
+		keys, err := newAuthService(ctx).ListKeys(ctx)
+		if err != nil {
+			log.Fatalf("adminkey: %v", err)
+		}
+		w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+		fmt.Fprintln(w, "ID\tNAME\tPREFIX\tROLE\tCREATED\tLAST USED\tREVOKED")
+		for _, k := range keys {
+			fmt.Fprintf(w, "%d\t%s\t%s\t%s\t%s\t%s\t%s\n",
+				k.ID, k.Name, k.KeyPrefix, k.Role, formatTime(&k.CreatedAt), formatTime(k.LastUsedAt), formatTime(k.RevokedAt))
+		}
+		w.Flush()
+
+	case "revoke":
+		fs := flag.NewFlagSet("revoke", flag.ExitOnError)
+		id := fs.Int64("id", 0, "ID of the key to revoke (see adminkey list)")
+		fs.Parse(args)
+
+		if *id <= 0 {
+			log.Fatal("adminkey: -id is required")
+		}
+		err := newAuthService(ctx).RevokeKey(ctx, *id)
+		if errors.Is(err, pgx.ErrNoRows) {
+			log.Fatalf("adminkey: no active key with ID %d", *id)
+		}
+		if err != nil {
+			log.Fatalf("adminkey: %v", err)
+		}
+		log.Printf("adminkey: revoked key %d", *id)
+
+	default:
+		log.Fatal(usage)
+	}
+}
+
+// newAuthService connects to the database. The pool lives until the
+// command exits.
+func newAuthService(ctx context.Context) *service.AuthService {
+	cfg := config.Load()
+	pool, err := db.NewPool(ctx, cfg.DatabaseURL)
+	if err != nil {
+		log.Fatalf("adminkey: %v", err)
+	}
+	return service.NewAuthService(repository.NewAdminKeyRepo(pool), repository.NewUserRepo(pool))
+}
+
+func formatTime(t *time.Time) string {
+	if t == nil {
+		return "-"
+	}
+	return t.UTC().Format(time.RFC3339)
+}
@@ -0,0 +1,62 @@
+// Command iphash rewrites the raw client IPs that earlier versions of the
+// vote handler stored in votes.ip_hash, replacing each with its salted hash
+// (the same HashIP digest new votes get). It is safe to run more than once:
+// values that are already hashes are left alone.
+//
+//	iphash -dry-run
+//	iphash -batch=500
+//
+// Run it with the server's IP_HASH_SALT (or ip_hash_salt secret), and with
+// the same database environment as the server (DATABASE_URL or DB_*).
+package main
+
+import (
+	"context"
+	"flag"
+	"log"
+
+	"github.com/mathieu-neron/RealTube/realtube-go/internal/config"
+	"github.com/mathieu-neron/RealTube/realtube-go/internal/db"
+	"github.com/mathieu-neron/RealTube/realtube-go/internal/repository"
+	"github.com/mathieu-neron/RealTube/realtube-go/internal/service"
+)
+
+func main() {
+	cfg := config.Load()
+
+	batch := flag.Int("batch", 1000, "distinct IPs rewritten per statement")
+	dryRun := flag.Bool("dry-run", false, "only count the votes that store a raw IP")
+	flag.Parse()
+
+	if *batch < 1 {
+		log.Fatal("iphash: -batch must be at least 1")
+	}
+	if cfg.IPHashSalt == "" {
+		log.Fatal("iphash: IP_HASH_SALT is not set; hashes made without a salt can be reversed")
+	}
+
+	ctx := context.Background()
+	pool, err := db.NewPool(ctx, cfg.DatabaseURL)
+	if err != nil {
+		log.Fatalf("iphash: %v", err)
+	}
+	defer pool.Close()
+
+	repo := repository.NewIPHashRepo(pool)
+
+	raw, err := repo.CountRawVoteIPs(ctx)
+	if err != nil {
+		log.Fatalf("iphash: %v", err)
+	}
+	log.Printf("iphash: %d votes store a raw IP", raw)
+	if *dryRun || raw == 0 {
+		return
+	}
+
+	ips := service.NewIPHashService(repo, cfg.IPHashSalt, "", 0)
+	n, err := ips.HashStoredIPs(ctx, *batch)
+	if err != nil {
+		log.Fatalf("iphash: %v (%d votes rewritten)", err, n)
+	}
+	log.Printf("iphash: done, %d votes rewritten", n)
+}
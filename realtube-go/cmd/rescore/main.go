@@ -0,0 +1,130 @@
+// Command rescore replays video and channel scoring over the votes and users
+// tables with an alternate set of trust/scoring parameters, and reports which
+// videos and channels would cross the flag thresholds compared to the current
+// configuration. It never writes to the database.
+//
+// Point DATABASE_URL at a production replica, or at a database restored from
+// a public export, then run for example:
+//
+//	rescore -strategy=wilson -format=csv -out=diff.csv
+//	rescore -age-weight=0.2 -accuracy-weight=0.6 -channel-min-score=75
+//
+// The baseline parameters come from the same environment as the server
+// (SCORING_STRATEGY, TRUST_DECAY_*, ...). Every flag defaults to the
+// baseline value, so only the parameters being evaluated need to be set.
+package main
+
+import (
+	"context"
+	"flag"
+	"io"
+	"log"
+	"math"
+	"os"
+
+	"github.com/mathieu-neron/RealTube/realtube-go/internal/config"
+	"github.com/mathieu-neron/RealTube/realtube-go/internal/db"
+	"github.com/mathieu-neron/RealTube/realtube-go/internal/repository"
+	"github.com/mathieu-neron/RealTube/realtube-go/internal/service"
+)
+
+func main() {
+	cfg := config.Load()
+	trustWeights := service.DefaultTrustWeights
+	autoFlag := repository.DefaultAutoFlagThresholds
+
+	strategyName := flag.String("strategy", cfg.ScoringStrategy, "alternate scoring strategy (ratio, wilson)")
+	confidenceZ := flag.Float64("z", cfg.ScoringConfidenceZ, "alternate z-score for confidence-aware strategies")
+	ageWeight := flag.Float64("age-weight", trustWeights.Age, "alternate trust age factor weight")
+	accuracyWeight := flag.Float64("accuracy-weight", trustWeights.Accuracy, "alternate trust accuracy factor weight")
+	volumeWeight := flag.Float64("volume-weight", trustWeights.Volume, "alternate trust volume factor weight")
+	channelMinScore := flag.Float64("channel-min-score", autoFlag.MinScore, "alternate channel score required for auto-flagging")
+	channelMinFlagged := flag.Int("channel-min-flagged", autoFlag.MinFlagged, "alternate flagged video count required for auto-flagging")
+	threshold := flag.Float64("threshold", service.FlagThreshold, "video score at which a video counts as flagged")
+	format := flag.String("format", "json", "report format (json, csv)")
+	out := flag.String("out", "", "report file (default stdout)")
+	all := flag.Bool("all", false, "report every video and channel whose score changed, not only threshold crossings")
+	flag.Parse()
+
+	if *format != "json" && *format != "csv" {
+		log.Fatalf("rescore: unknown format %q", *format)
+	}
+
+	altWeights := service.TrustWeights{Age: *ageWeight, Accuracy: *accuracyWeight, Volume: *volumeWeight}
+	if sum := altWeights.Age + altWeights.Accuracy + altWeights.Volume; math.Abs(sum-1) > 1e-9 {
+		log.Printf("rescore: warning: trust factor weights sum to %.4f, not 1", sum)
+	}
+
+	baseStrategy, err := service.NewScoringStrategy(cfg.ScoringStrategy, cfg.ScoringConfidenceZ)
+	if err != nil {
+		log.Fatalf("rescore: baseline scoring configuration: %v", err)
+	}
+	altStrategy, err := service.NewScoringStrategy(*strategyName, *confidenceZ)
+	if err != nil {
+		log.Fatalf("rescore: alternate scoring configuration: %v", err)
+	}
+
+	ctx := context.Background()
+	pool, err := db.NewPool(ctx, cfg.DatabaseURL)
+	if err != nil {
+		log.Fatalf("rescore: %v", err)
+	}
+	defer pool.Close()
+
+	baseTrust := service.NewTrustService(service.TrustDecay{
+		GraceDays:    cfg.TrustDecayGraceDays,
+		HalfLifeDays: cfg.TrustDecayHalfLifeDays,
+		RecoveryDays: cfg.TrustDecayRecoveryDays,
+	})
+
+	altAutoFlag := repository.AutoFlagThresholds{MinScore: *channelMinScore, MinFlagged: *channelMinFlagged}
+
+	r := &replayer{
+		pool:      pool,
+		threshold: *threshold,
+		all:       *all,
+		baseline: scenario{
+			params:   newParams(baseStrategy.Name(), cfg.ScoringConfidenceZ, baseTrust.Weights(), autoFlag),
+			trust:    baseTrust,
+			score:    service.NewScoreService(pool, baseStrategy),
+			autoFlag: autoFlag,
+		},
+		alternate: scenario{
+			params:   newParams(altStrategy.Name(), *confidenceZ, altWeights, altAutoFlag),
+			trust:    baseTrust.WithWeights(altWeights),
+			score:    service.NewScoreService(pool, altStrategy),
+			autoFlag: altAutoFlag,
+		},
+	}
+
+	rep, err := r.run(ctx)
+	if err != nil {
+		log.Fatalf("rescore: %v", err)
+	}
+
+	s := rep.Summary
+	log.Printf("rescore: %d videos replayed — flagged %d → %d (+%d / -%d)",
+		s.VideosScored, s.VideosFlaggedBaseline, s.VideosFlaggedAlternate, s.VideosNewlyFlagged, s.VideosUnflagged)
+	log.Printf("rescore: %d channels replayed — auto-flagged %d → %d (+%d / -%d)",
+		s.ChannelsScored, s.ChannelsAutoFlaggedBaseline, s.ChannelsAutoFlaggedAlternate,
+		s.ChannelsNewlyAutoFlagged, s.ChannelsNoLongerAutoFlagged)
+
+	var w io.Writer = os.Stdout
+	if *out != "" {
+		f, err := os.Create(*out)
+		if err != nil {
+			log.Fatalf("rescore: %v", err)
+		}
+		defer f.Close()
+		w = f
+	}
+
+	if *format == "csv" {
+		err = writeCSV(w, rep)
+	} else {
+		err = writeJSON(w, rep)
+	}
+	if err != nil {
+		log.Fatalf("rescore: write report: %v", err)
+	}
+}
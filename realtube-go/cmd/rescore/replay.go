@@ -0,0 +1,258 @@
+package main
+
+import (
+	"context"
+	"math"
+	"sort"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+
+	"github.com/mathieu-neron/RealTube/realtube-go/internal/model"
+	"github.com/mathieu-neron/RealTube/realtube-go/internal/repository"
+	"github.com/mathieu-neron/RealTube/realtube-go/internal/service"
+)
+
+// scenario is one set of parameters under which scores are replayed.
+type scenario struct {
+	params   params
+	trust    *service.TrustService
+	score    *service.ScoreService
+	autoFlag repository.AutoFlagThresholds
+}
+
+// replayer recomputes every vote weight, video score and channel score under
+// a baseline and an alternate scenario.
+type replayer struct {
+	pool      *pgxpool.Pool
+	baseline  scenario
+	alternate scenario
+	threshold float64
+	all       bool
+}
+
+// channelTally accumulates a channel's video scores under one scenario, as
+// the channel worker's aggregate query does. Videos scoring threshold or
+// more count as flagged.
+type channelTally struct {
+	threshold  float64
+	flagged    int
+	tracked    int
+	flaggedSum float64
+}
+
+func (t *channelTally) add(videoScore float64) {
+	t.tracked++
+	if videoScore >= t.threshold {
+		t.flagged++
+		t.flaggedSum += videoScore
+	}
+}
+
+func (t *channelTally) score() float64 {
+	var avg float64
+	if t.flagged > 0 {
+		avg = t.flaggedSum / float64(t.flagged)
+	}
+	return repository.ComputeChannelScorePure(t.flagged, t.tracked, avg)
+}
+
+func (r *replayer) run(ctx context.Context) (*report, error) {
+	weights, err := r.userWeights(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	rep := &report{
+		Baseline:  r.baseline.params,
+		Alternate: r.alternate.params,
+		Videos:    []videoDiff{},
+		Channels:  []channelDiff{},
+	}
+	baseChannels := make(map[string]*channelTally)
+	altChannels := make(map[string]*channelTally)
+
+	err = r.replayVideos(ctx, weights, func(videoID, channelID string, pinned bool, base, alt float64) {
+		if channelID != "" {
+			if _, ok := baseChannels[channelID]; !ok {
+				baseChannels[channelID] = &channelTally{threshold: r.threshold}
+				altChannels[channelID] = &channelTally{threshold: r.threshold}
+			}
+			baseChannels[channelID].add(base)
+			altChannels[channelID].add(alt)
+		}
+		if pinned {
+			return
+		}
+
+		d := videoDiff{
+			VideoID:          videoID,
+			ChannelID:        channelID,
+			BaselineScore:    round2(base),
+			AlternateScore:   round2(alt),
+			BaselineFlagged:  base >= r.threshold,
+			AlternateFlagged: alt >= r.threshold,
+		}
+		rep.Summary.addVideo(d)
+		if d.BaselineFlagged != d.AlternateFlagged || (r.all && d.BaselineScore != d.AlternateScore) {
+			rep.Videos = append(rep.Videos, d)
+		}
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	locked, err := r.lockedChannels(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	channelIDs := make([]string, 0, len(baseChannels))
+	for id := range baseChannels {
+		channelIDs = append(channelIDs, id)
+	}
+	sort.Strings(channelIDs)
+
+	for _, id := range channelIDs {
+		base, alt := baseChannels[id], altChannels[id]
+		d := channelDiff{
+			ChannelID:              id,
+			TrackedVideos:          base.tracked,
+			BaselineScore:          base.score(),
+			AlternateScore:         alt.score(),
+			BaselineFlaggedVideos:  base.flagged,
+			AlternateFlaggedVideos: alt.flagged,
+		}
+		d.BaselineAutoFlag = r.baseline.autoFlag.ShouldAutoFlag(d.BaselineScore, base.flagged) && !locked[id]
+		d.AlternateAutoFlag = r.alternate.autoFlag.ShouldAutoFlag(d.AlternateScore, alt.flagged) && !locked[id]
+
+		rep.Summary.addChannel(d)
+		if d.BaselineAutoFlag != d.AlternateAutoFlag || (r.all && d.BaselineScore != d.AlternateScore) {
+			rep.Channels = append(rep.Channels, d)
+		}
+	}
+
+	return rep, nil
+}
+
+// userWeights returns the effective vote weight of every user who has voted,
+// under the baseline ([0]) and alternate ([1]) trust parameters.
+func (r *replayer) userWeights(ctx context.Context) (map[string][2]float64, error) {
+	rows, err := r.pool.Query(ctx, `
+		SELECT user_id, accuracy_rate, total_votes, first_seen, last_active,
+		       is_vip, is_shadowbanned, dormancy_factor, reactivated_at, sybil_split
+		FROM users u
+		WHERE EXISTS (SELECT 1 FROM votes v WHERE v.user_id = u.user_id)`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	weights := make(map[string][2]float64)
+	for rows.Next() {
+		var u model.User
+		err := rows.Scan(&u.UserID, &u.AccuracyRate, &u.TotalVotes, &u.FirstSeen, &u.LastActive,
+			&u.IsVIP, &u.IsShadowbanned, &u.DormancyFactor, &u.ReactivatedAt, &u.SybilSplit)
+		if err != nil {
+			return nil, err
+		}
+		weights[u.UserID] = [2]float64{
+			r.baseline.trust.EffectiveWeight(&u),
+			r.alternate.trust.EffectiveWeight(&u),
+		}
+	}
+	return weights, rows.Err()
+}
+
+// replayVideos streams all votes grouped by video and calls fn with the
+// video's score under both scenarios. Videos locked or overridden by a VIP,
+// or frozen by a brigade, are not rescored by the score worker: they are
+// reported as pinned, with their current score under both scenarios.
+func (r *replayer) replayVideos(ctx context.Context, weights map[string][2]float64,
+	fn func(videoID, channelID string, pinned bool, base, alt float64)) error {
+	rows, err := r.pool.Query(ctx, `
+		SELECT vo.video_id, COALESCE(v.channel_id, ''), COALESCE(v.locked, FALSE) OR v.frozen, COALESCE(v.score, 0), vo.user_id, vo.category
+		FROM votes vo
+		JOIN videos v ON v.video_id = vo.video_id
+		ORDER BY vo.video_id`)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	var videoID, channelID string
+	var pinned bool
+	var current float64
+	base := make(map[string]float64)
+	alt := make(map[string]float64)
+
+	flush := func() {
+		if videoID == "" {
+			return
+		}
+		if pinned {
+			fn(videoID, channelID, true, current, current)
+		} else {
+			fn(videoID, channelID, false,
+				r.baseline.score.Score(categoryScores(base)),
+				r.alternate.score.Score(categoryScores(alt)))
+		}
+		clear(base)
+		clear(alt)
+	}
+
+	for rows.Next() {
+		var vid, chID, userID, category string
+		var vidPinned bool
+		var vidScore float64
+		if err := rows.Scan(&vid, &chID, &vidPinned, &vidScore, &userID, &category); err != nil {
+			return err
+		}
+		if vid != videoID {
+			flush()
+			videoID, channelID, pinned, current = vid, chID, vidPinned, vidScore
+		}
+		w := weights[userID]
+		base[category] += w[0]
+		alt[category] += w[1]
+	}
+	if err := rows.Err(); err != nil {
+		return err
+	}
+	flush()
+	return nil
+}
+
+// lockedChannels returns the IDs of channels whose auto-flag status is
+// pinned by a VIP lock.
+func (r *replayer) lockedChannels(ctx context.Context) (map[string]bool, error) {
+	rows, err := r.pool.Query(ctx, `SELECT channel_id FROM channels WHERE locked`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	locked := make(map[string]bool)
+	for rows.Next() {
+		var id string
+		if err := rows.Scan(&id); err != nil {
+			return nil, err
+		}
+		locked[id] = true
+	}
+	return locked, rows.Err()
+}
+
+// categoryScores converts per-category weight sums into the input of
+// ScoreService.Score, in a stable order.
+func categoryScores(sums map[string]float64) []service.CategoryScore {
+	categories := make([]service.CategoryScore, 0, len(sums))
+	for category, sum := range sums {
+		categories = append(categories, service.CategoryScore{Category: category, WeightSum: sum})
+	}
+	sort.Slice(categories, func(i, j int) bool { return categories[i].Category < categories[j].Category })
+	return categories
+}
+
+func round2(v float64) float64 {
+	return math.Round(v*100) / 100
+}
@@ -0,0 +1,140 @@
+package main
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"io"
+	"strconv"
+
+	"github.com/mathieu-neron/RealTube/realtube-go/internal/repository"
+	"github.com/mathieu-neron/RealTube/realtube-go/internal/service"
+)
+
+// params describes the parameter set of a scenario in the report.
+type params struct {
+	Strategy          string  `json:"strategy"`
+	ConfidenceZ       float64 `json:"confidenceZ"`
+	AgeWeight         float64 `json:"ageWeight"`
+	AccuracyWeight    float64 `json:"accuracyWeight"`
+	VolumeWeight      float64 `json:"volumeWeight"`
+	ChannelMinScore   float64 `json:"channelMinScore"`
+	ChannelMinFlagged int     `json:"channelMinFlagged"`
+}
+
+func newParams(strategy string, z float64, w service.TrustWeights, t repository.AutoFlagThresholds) params {
+	return params{
+		Strategy:          strategy,
+		ConfidenceZ:       z,
+		AgeWeight:         w.Age,
+		AccuracyWeight:    w.Accuracy,
+		VolumeWeight:      w.Volume,
+		ChannelMinScore:   t.MinScore,
+		ChannelMinFlagged: t.MinFlagged,
+	}
+}
+
+type videoDiff struct {
+	VideoID          string  `json:"videoId"`
+	ChannelID        string  `json:"channelId,omitempty"`
+	BaselineScore    float64 `json:"baselineScore"`
+	AlternateScore   float64 `json:"alternateScore"`
+	BaselineFlagged  bool    `json:"baselineFlagged"`
+	AlternateFlagged bool    `json:"alternateFlagged"`
+}
+
+type channelDiff struct {
+	ChannelID              string  `json:"channelId"`
+	TrackedVideos          int     `json:"trackedVideos"`
+	BaselineScore          float64 `json:"baselineScore"`
+	AlternateScore         float64 `json:"alternateScore"`
+	BaselineFlaggedVideos  int     `json:"baselineFlaggedVideos"`
+	AlternateFlaggedVideos int     `json:"alternateFlaggedVideos"`
+	BaselineAutoFlag       bool    `json:"baselineAutoFlag"`
+	AlternateAutoFlag      bool    `json:"alternateAutoFlag"`
+}
+
+type summary struct {
+	VideosScored           int `json:"videosScored"`
+	VideosFlaggedBaseline  int `json:"videosFlaggedBaseline"`
+	VideosFlaggedAlternate int `json:"videosFlaggedAlternate"`
+	VideosNewlyFlagged     int `json:"videosNewlyFlagged"`
+	VideosUnflagged        int `json:"videosUnflagged"`
+
+	ChannelsScored               int `json:"channelsScored"`
+	ChannelsAutoFlaggedBaseline  int `json:"channelsAutoFlaggedBaseline"`
+	ChannelsAutoFlaggedAlternate int `json:"channelsAutoFlaggedAlternate"`
+	ChannelsNewlyAutoFlagged     int `json:"channelsNewlyAutoFlagged"`
+	ChannelsNoLongerAutoFlagged  int `json:"channelsNoLongerAutoFlagged"`
+}
+
+func (s *summary) addVideo(d videoDiff) {
+	s.VideosScored++
+	if d.BaselineFlagged {
+		s.VideosFlaggedBaseline++
+	}
+	if d.AlternateFlagged {
+		s.VideosFlaggedAlternate++
+	}
+	if d.AlternateFlagged && !d.BaselineFlagged {
+		s.VideosNewlyFlagged++
+	}
+	if d.BaselineFlagged && !d.AlternateFlagged {
+		s.VideosUnflagged++
+	}
+}
+
+func (s *summary) addChannel(d channelDiff) {
+	s.ChannelsScored++
+	if d.BaselineAutoFlag {
+		s.ChannelsAutoFlaggedBaseline++
+	}
+	if d.AlternateAutoFlag {
+		s.ChannelsAutoFlaggedAlternate++
+	}
+	if d.AlternateAutoFlag && !d.BaselineAutoFlag {
+		s.ChannelsNewlyAutoFlagged++
+	}
+	if d.BaselineAutoFlag && !d.AlternateAutoFlag {
+		s.ChannelsNoLongerAutoFlagged++
+	}
+}
+
+// report is the diff between the baseline and alternate scenarios.
+type report struct {
+	Baseline  params        `json:"baseline"`
+	Alternate params        `json:"alternate"`
+	Summary   summary       `json:"summary"`
+	Videos    []videoDiff   `json:"videos"`
+	Channels  []channelDiff `json:"channels"`
+}
+
+func writeJSON(w io.Writer, rep *report) error {
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(rep)
+}
+
+// writeCSV writes one row per video and channel in the report. For channels,
+// the flagged columns hold the auto-flag status.
+func writeCSV(w io.Writer, rep *report) error {
+	cw := csv.NewWriter(w)
+	cw.Write([]string{"type", "id", "channel_id", "baseline_score", "alternate_score", "baseline_flagged", "alternate_flagged"})
+
+	for _, v := range rep.Videos {
+		cw.Write([]string{"video", v.VideoID, v.ChannelID,
+			formatScore(v.BaselineScore), formatScore(v.AlternateScore),
+			strconv.FormatBool(v.BaselineFlagged), strconv.FormatBool(v.AlternateFlagged)})
+	}
+	for _, c := range rep.Channels {
+		cw.Write([]string{"channel", c.ChannelID, c.ChannelID,
+			formatScore(c.BaselineScore), formatScore(c.AlternateScore),
+			strconv.FormatBool(c.BaselineAutoFlag), strconv.FormatBool(c.AlternateAutoFlag)})
+	}
+
+	cw.Flush()
+	return cw.Error()
+}
+
+func formatScore(v float64) string {
+	return strconv.FormatFloat(v, 'f', 2, 64)
+}
@@ -13,6 +13,7 @@ import (
 	"github.com/mathieu-neron/RealTube/realtube-go/internal/db"
 	"github.com/mathieu-neron/RealTube/realtube-go/internal/handler"
 	"github.com/mathieu-neron/RealTube/realtube-go/internal/middleware"
+	"github.com/mathieu-neron/RealTube/realtube-go/internal/model"
 	"github.com/mathieu-neron/RealTube/realtube-go/internal/repository"
 	"github.com/mathieu-neron/RealTube/realtube-go/internal/router"
 	"github.com/mathieu-neron/RealTube/realtube-go/internal/service"
@@ -39,14 +40,60 @@ func main() {
 	voteRepo := repository.NewVoteRepo(pool)
 	channelRepo := repository.NewChannelRepo(pool)
 	userRepo := repository.NewUserRepo(pool)
+	moderationRepo := repository.NewModerationRepo(pool)
+	adminKeyRepo := repository.NewAdminKeyRepo(pool)
+	appealRepo := repository.NewAppealRepo(pool)
+	ipHashRepo := repository.NewIPHashRepo(pool)
+	sybilRepo := repository.NewSybilRepo(pool)
+	brigadeRepo := repository.NewBrigadeRepo(pool)
 
 	// Services
-	videoSvc := service.NewVideoService(videoRepo, cacheSvc)
-	scoreSvc := service.NewScoreService(pool)
-	voteSvc := service.NewVoteService(voteRepo, cacheSvc)
+	videoSvc := service.NewVideoService(videoRepo, channelRepo, cacheSvc)
+	scoringStrategy, err := service.NewScoringStrategy(cfg.ScoringStrategy, cfg.ScoringConfidenceZ)
+	if err != nil {
+		log.Fatal().Err(err).Msg("invalid scoring configuration")
+	}
+	scoreSvc := service.NewScoreService(pool, scoringStrategy)
+	trustSvc := service.NewTrustService(service.TrustDecay{
+		GraceDays:    cfg.TrustDecayGraceDays,
+		HalfLifeDays: cfg.TrustDecayHalfLifeDays,
+		RecoveryDays: cfg.TrustDecayRecoveryDays,
+	})
+	ipHashSvc := service.NewIPHashService(ipHashRepo, cfg.IPHashSalt, cfg.IPHashPreviousSalt, cfg.IPVoteLimit24h)
+	challengeSvc := service.NewChallengeService(userRepo, ipHashSvc, trustSvc, cacheSvc, cfg.ChallengeSecret,
+		model.ChallengePolicy{
+			Enabled:        cfg.ChallengeEnabled,
+			TrustThreshold: cfg.ChallengeTrustThreshold,
+			BaseDifficulty: cfg.ChallengeBaseDifficulty,
+			MaxDifficulty:  cfg.ChallengeMaxDifficulty,
+			VelocityStep:   cfg.ChallengeVelocityStep,
+			TTL:            time.Duration(cfg.ChallengeTTLSeconds) * time.Second,
+		})
+	voteSvc := service.NewVoteService(voteRepo, ipHashSvc, challengeSvc, trustSvc, cacheSvc)
 	channelSvc := service.NewChannelService(channelRepo, cacheSvc)
-	userSvc := service.NewUserService(userRepo)
+	userSvc := service.NewUserService(userRepo, trustSvc)
 	syncSvc := service.NewSyncService(pool, videoSvc, channelSvc)
+	moderationSvc := service.NewModerationService(moderationRepo, userRepo, scoreSvc, trustSvc, cacheSvc)
+	appealSvc := service.NewAppealService(appealRepo, moderationSvc, cacheSvc)
+	moderationLogSvc := service.NewModerationLogService(moderationRepo, cfg.ModeratorIDSalt, cfg.ModerationLogRedacted)
+	sybilSvc := service.NewSybilService(sybilRepo, trustSvc, model.SybilDetection{
+		Window:          time.Duration(cfg.SybilWindowDays * 24 * float64(time.Hour)),
+		MinUsers:        cfg.SybilMinUsers,
+		MinSharedVideos: cfg.SybilMinSharedVideos,
+	}, cfg.SybilAutoDampen)
+	brigadeSvc := service.NewBrigadeService(brigadeRepo, scoreSvc, cacheSvc, model.BrigadeDetection{
+		Enabled:         cfg.BrigadeEnabled,
+		Window:          time.Duration(cfg.BrigadeWindowMinutes * float64(time.Minute)),
+		Baseline:        time.Duration(cfg.BrigadeBaselineHours * float64(time.Hour)),
+		MinVideoVotes:   cfg.BrigadeMinVideoVotes,
+		MinChannelVotes: cfg.BrigadeMinChannelVotes,
+		VelocityFactor:  cfg.BrigadeVelocityFactor,
+		NewAccountAge:   time.Duration(cfg.BrigadeNewAccountDays * 24 * float64(time.Hour)),
+		NewAccountShare: cfg.BrigadeNewAccountShare,
+		FreezeFor:       time.Duration(cfg.BrigadeFreezeHours * float64(time.Hour)),
+	})
+	authSvc := service.NewAuthService(adminKeyRepo, userRepo)
+	opsSvc := service.NewOpsService(pool)
 
 	// Initialize Prometheus metrics
 	handler.InitMetrics(pool)
@@ -61,6 +108,15 @@ func main() {
 		Sync:    handler.NewSyncHandler(syncSvc),
 		Health:  handler.NewHealthHandler(pool, cacheSvc.Client()),
 		Export:  handler.NewExportHandler(cfg.ExportDir),
+
+		Moderation:    handler.NewModerationHandler(moderationSvc),
+		ModerationLog: handler.NewModerationLogHandler(moderationLogSvc),
+		Appeal:        handler.NewAppealHandler(appealSvc),
+		Sybil:         handler.NewSybilHandler(sybilSvc),
+		Challenge:     handler.NewChallengeHandler(challengeSvc),
+		Brigade:       handler.NewBrigadeHandler(brigadeSvc),
+		Admin:         handler.NewAdminHandler(authSvc, opsSvc),
+		Auth:          middleware.NewAuth(authSvc.Authenticate),
 	}
 
 	app := fiber.New(fiber.Config{
@@ -78,22 +134,54 @@ func main() {
 		},
 	})
 
-	router.Setup(app, handlers, cfg.CORSOrigins)
+	// Rate limits are shared by every replica through Redis when it is available
+	if cacheSvc.Client() == nil {
+		log.Warn().Msg("Redis unavailable — rate limits are kept per process and reset on restart")
+	}
+	router.Setup(app, handlers, cfg.CORSOrigins, middleware.NewRateLimitStore(cacheSvc.Client()))
 
 	// Warn if wildcard CORS is used in production
 	if cfg.Environment == "production" && cfg.CORSOrigins == "*" {
 		log.Warn().Msg("CORS_ORIGINS is set to '*' in production — this allows any website to make API requests")
 	}
 
+	// Warn if moderator pseudonyms can be linked back to VIP user IDs
+	if cfg.Environment == "production" && cfg.ModeratorIDSalt == "" {
+		log.Warn().Msg("MODERATOR_ID_SALT is not set in production — moderation log pseudonyms can be linked to VIP user IDs")
+	}
+
+	// Warn if stored IP hashes can be reversed by hashing the IPv4 space
+	if cfg.Environment == "production" && cfg.IPHashSalt == "" {
+		log.Warn().Msg("IP_HASH_SALT is not set in production — stored IP hashes can be reversed by enumerating IPs")
+	}
+
+	// Warn if challenges issued by one replica fail on the others
+	if cfg.ChallengeEnabled && cfg.ChallengeSecret == "" {
+		log.Warn().Msg("CHALLENGE_SECRET is not set — vote challenges only verify on the replica that issued them")
+	}
+
 	// Graceful shutdown: listen for SIGTERM/SIGINT
 	shutdownCtx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
 	defer stop()
 
-	// Start background workers
-	channelWorker := service.NewChannelWorker(pool, 15*time.Minute)
-	go channelWorker.Start(shutdownCtx)
-
-	scoreWorker := service.NewScoreWorker(pool, scoreSvc, cacheSvc)
+	// Start background workers.
+	// The periodic sweeps run on the elected leader replica only. The score
+	// worker runs on every replica: they split the durable queue with
+	// FOR UPDATE SKIP LOCKED.
+	channelWorker := service.NewChannelWorker(pool, cacheSvc, 10*time.Second, 6*time.Hour)
+	accuracyWorker := service.NewAccuracyWorker(pool, time.Hour)
+	trustWorker := service.NewTrustWorker(pool, trustSvc, time.Hour, cfg.TrustReweightVotes)
+	sybilWorker := service.NewSybilWorker(sybilSvc, time.Hour)
+	brigadeWorker := service.NewBrigadeWorker(brigadeSvc, 5*time.Minute)
+
+	leader := service.NewLeaderElector(pool, "periodic-workers", 15*time.Second)
+	go leader.Run(shutdownCtx, channelWorker.Start, accuracyWorker.Start, trustWorker.Start, sybilWorker.Start,
+		brigadeWorker.Start)
+
+	scoreWorker := service.NewScoreWorker(pool, scoreSvc, brigadeSvc, cacheSvc, service.ScoreWorkerMetrics{
+		Duration:  handler.Metrics.ScoreRecalcDuration,
+		BatchSize: handler.Metrics.ScoreRecalcBatchSize,
+	})
 	go scoreWorker.Start(shutdownCtx)
 
 	// Start server in a goroutine
@@ -3,6 +3,7 @@ package config
 import (
 	"fmt"
 	"os"
+	"strconv"
 	"strings"
 )
 
@@ -14,6 +15,69 @@ type Config struct {
 	Environment string
 	CORSOrigins string
 	ExportDir   string
+
+	// TrustReweightVotes re-applies recomputed trust to a user's existing
+	// votes so video scores follow trust changes.
+	TrustReweightVotes bool
+
+	// Inactivity decay of vote weight (trust-system-design.md §9).
+	// A half-life of 0 disables decay.
+	TrustDecayGraceDays    float64
+	TrustDecayHalfLifeDays float64
+	TrustDecayRecoveryDays float64
+
+	// Video scoring strategy ("ratio" or "wilson") and the z-score used by
+	// confidence-aware strategies.
+	ScoringStrategy    string
+	ScoringConfidenceZ float64
+
+	// Public moderation log: the salt of the pseudonymous moderator IDs and
+	// the action types left out of it.
+	ModeratorIDSalt       string
+	ModerationLogRedacted []string
+
+	// Salt of the stored client IP hashes, the salt it replaced while a
+	// rotation is under way, and the votes an IP hash may cast per 24 hours
+	// (0 for no limit).
+	IPHashSalt         string
+	IPHashPreviousSalt string
+	IPVoteLimit24h     int
+
+	// Sybil cluster detection: the vote window looked at, the user IDs and
+	// shared videos that make a cluster, and whether clusters are dampened
+	// as soon as they are found or left pending for a moderator.
+	SybilWindowDays      float64
+	SybilMinUsers        int
+	SybilMinSharedVideos int
+	SybilAutoDampen      bool
+
+	// Proof-of-work vote challenges: whether they are required, the secret
+	// that signs them (shared by all replicas), the trust score below which
+	// users must solve one, the difficulty range in leading zero bits, the
+	// votes per hour from an IP hash that add a bit, and the time allowed
+	// to solve one.
+	ChallengeEnabled        bool
+	ChallengeSecret         string
+	ChallengeTrustThreshold float64
+	ChallengeBaseDifficulty int
+	ChallengeMaxDifficulty  int
+	ChallengeVelocityStep   float64
+	ChallengeTTLSeconds     int
+
+	// Brigade detection: whether vote spikes freeze scores, the spike window
+	// and the baseline period before it, the votes in the window that make
+	// a video or channel eligible, the rate over the baseline rate and the
+	// share of accounts younger than BrigadeNewAccountDays that make a
+	// spike, and how long a freeze lasts unless a moderator holds it.
+	BrigadeEnabled         bool
+	BrigadeWindowMinutes   float64
+	BrigadeBaselineHours   float64
+	BrigadeMinVideoVotes   int
+	BrigadeMinChannelVotes int
+	BrigadeVelocityFactor  float64
+	BrigadeNewAccountDays  float64
+	BrigadeNewAccountShare float64
+	BrigadeFreezeHours     float64
 }
 
 func Load() *Config {
@@ -25,6 +89,45 @@ func Load() *Config {
 		Environment: getEnv("ENVIRONMENT", "development"),
 		CORSOrigins: getEnv("CORS_ORIGINS", "*"),
 		ExportDir:   getEnv("EXPORT_DIR", "/exports"),
+
+		TrustReweightVotes: getBoolEnv("TRUST_REWEIGHT_VOTES", true),
+
+		TrustDecayGraceDays:    getFloatEnv("TRUST_DECAY_GRACE_DAYS", 90),
+		TrustDecayHalfLifeDays: getFloatEnv("TRUST_DECAY_HALF_LIFE_DAYS", 90),
+		TrustDecayRecoveryDays: getFloatEnv("TRUST_DECAY_RECOVERY_DAYS", 30),
+
+		ScoringStrategy:    getEnv("SCORING_STRATEGY", "ratio"),
+		ScoringConfidenceZ: getFloatEnv("SCORING_CONFIDENCE_Z", 1.96),
+
+		ModeratorIDSalt:       readSecret("moderator_id_salt", "MODERATOR_ID_SALT", ""),
+		ModerationLogRedacted: getListEnv("MODERATION_LOG_REDACT", []string{"hide", "unhide", "shadowban", "unshadowban", "sybil_dampen", "sybil_dismiss"}),
+
+		IPHashSalt:         readSecret("ip_hash_salt", "IP_HASH_SALT", ""),
+		IPHashPreviousSalt: readSecret("ip_hash_previous_salt", "IP_HASH_PREVIOUS_SALT", ""),
+		IPVoteLimit24h:     getIntEnv("IP_VOTE_LIMIT_24H", 1000),
+
+		SybilWindowDays:      getFloatEnv("SYBIL_WINDOW_DAYS", 30),
+		SybilMinUsers:        getIntEnv("SYBIL_MIN_USERS", 3),
+		SybilMinSharedVideos: getIntEnv("SYBIL_MIN_SHARED_VIDEOS", 3),
+		SybilAutoDampen:      getBoolEnv("SYBIL_AUTO_DAMPEN", false),
+
+		ChallengeEnabled:        getBoolEnv("CHALLENGE_ENABLED", false),
+		ChallengeSecret:         readSecret("challenge_secret", "CHALLENGE_SECRET", ""),
+		ChallengeTrustThreshold: getFloatEnv("CHALLENGE_TRUST_THRESHOLD", 0.4),
+		ChallengeBaseDifficulty: getIntEnv("CHALLENGE_BASE_DIFFICULTY", 16),
+		ChallengeMaxDifficulty:  getIntEnv("CHALLENGE_MAX_DIFFICULTY", 24),
+		ChallengeVelocityStep:   getFloatEnv("CHALLENGE_VELOCITY_STEP", 10),
+		ChallengeTTLSeconds:     getIntEnv("CHALLENGE_TTL_SECONDS", 300),
+
+		BrigadeEnabled:         getBoolEnv("BRIGADE_ENABLED", true),
+		BrigadeWindowMinutes:   getFloatEnv("BRIGADE_WINDOW_MINUTES", 10),
+		BrigadeBaselineHours:   getFloatEnv("BRIGADE_BASELINE_HOURS", 24),
+		BrigadeMinVideoVotes:   getIntEnv("BRIGADE_MIN_VIDEO_VOTES", 20),
+		BrigadeMinChannelVotes: getIntEnv("BRIGADE_MIN_CHANNEL_VOTES", 50),
+		BrigadeVelocityFactor:  getFloatEnv("BRIGADE_VELOCITY_FACTOR", 10),
+		BrigadeNewAccountDays:  getFloatEnv("BRIGADE_NEW_ACCOUNT_DAYS", 7),
+		BrigadeNewAccountShare: getFloatEnv("BRIGADE_NEW_ACCOUNT_SHARE", 0.6),
+		BrigadeFreezeHours:     getFloatEnv("BRIGADE_FREEZE_HOURS", 24),
 	}
 }
 
@@ -35,6 +138,46 @@ func getEnv(key, fallback string) string {
 	return fallback
 }
 
+func getBoolEnv(key string, fallback bool) bool {
+	v, err := strconv.ParseBool(os.Getenv(key))
+	if err != nil {
+		return fallback
+	}
+	return v
+}
+
+func getIntEnv(key string, fallback int) int {
+	v, err := strconv.Atoi(os.Getenv(key))
+	if err != nil {
+		return fallback
+	}
+	return v
+}
+
+func getFloatEnv(key string, fallback float64) float64 {
+	v, err := strconv.ParseFloat(os.Getenv(key), 64)
+	if err != nil {
+		return fallback
+	}
+	return v
+}
+
+// getListEnv reads a comma-separated list. An unset variable gives the
+// fallback; "none" gives an empty list.
+func getListEnv(key string, fallback []string) []string {
+	v := os.Getenv(key)
+	if v == "" {
+		return fallback
+	}
+	var list []string
+	for _, item := range strings.Split(v, ",") {
+		if item = strings.TrimSpace(item); item != "" && item != "none" {
+			list = append(list, item)
+		}
+	}
+	return list
+}
+
 // readSecret reads a Docker secret from /run/secrets/<name>.
 // Falls back to the given env var, then to the fallback value.
 func readSecret(secretName, envVar, fallback string) string {
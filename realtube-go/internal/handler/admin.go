@@ -0,0 +1,82 @@
+package handler
+
+import (
+	"errors"
+	"strconv"
+
+	"github.com/gofiber/fiber/v3"
+	"github.com/jackc/pgx/v5"
+
+	"github.com/mathieu-neron/RealTube/realtube-go/internal/middleware"
+	"github.com/mathieu-neron/RealTube/realtube-go/internal/model"
+	"github.com/mathieu-neron/RealTube/realtube-go/internal/service"
+)
+
+type AdminHandler struct {
+	auth *service.AuthService
+	ops  *service.OpsService
+}
+
+func NewAdminHandler(auth *service.AuthService, ops *service.OpsService) *AdminHandler {
+	return &AdminHandler{auth: auth, ops: ops}
+}
+
+// ListKeys handles GET /api/admin/keys
+func (h *AdminHandler) ListKeys(c fiber.Ctx) error {
+	keys, err := h.auth.ListKeys(c.Context())
+	if err != nil {
+		return middleware.ErrorResponse(c, fiber.StatusInternalServerError, "INTERNAL_ERROR", "Failed to list keys")
+	}
+	if keys == nil {
+		keys = []model.AdminKey{}
+	}
+	return c.JSON(keys)
+}
+
+// CreateKey handles POST /api/admin/keys
+func (h *AdminHandler) CreateKey(c fiber.Ctx) error {
+	var req model.CreateAdminKeyRequest
+	if err := c.Bind().JSON(&req); err != nil {
+		return middleware.ErrorResponse(c, fiber.StatusBadRequest, "INVALID_BODY", "Invalid request body")
+	}
+
+	name, errMsg := middleware.ValidateKeyName(req.Name)
+	if errMsg != "" {
+		return middleware.ErrorResponse(c, fiber.StatusBadRequest, "INVALID_FIELD", errMsg)
+	}
+	if !model.ValidRoles[req.Role] {
+		return middleware.ErrorResponse(c, fiber.StatusBadRequest, "INVALID_ROLE",
+			"Invalid role. Must be one of: moderator, admin, read-only-ops")
+	}
+
+	key, k, err := h.auth.CreateKey(c.Context(), name, req.Role)
+	if err != nil {
+		return middleware.ErrorResponse(c, fiber.StatusInternalServerError, "INTERNAL_ERROR", "Failed to create key")
+	}
+	return c.Status(fiber.StatusCreated).JSON(model.CreateAdminKeyResponse{Key: key, AdminKey: *k})
+}
+
+// RevokeKey handles DELETE /api/admin/keys/:id
+func (h *AdminHandler) RevokeKey(c fiber.Ctx) error {
+	id, err := strconv.ParseInt(c.Params("id"), 10, 64)
+	if err != nil || id <= 0 {
+		return middleware.ErrorResponse(c, fiber.StatusBadRequest, "INVALID_FIELD", "id must be a positive integer")
+	}
+
+	if err := h.auth.RevokeKey(c.Context(), id); err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return middleware.ErrorResponse(c, fiber.StatusNotFound, "NOT_FOUND", "Active key not found")
+		}
+		return middleware.ErrorResponse(c, fiber.StatusInternalServerError, "INTERNAL_ERROR", "Failed to revoke key")
+	}
+	return c.JSON(fiber.Map{"success": true})
+}
+
+// QueueStats handles GET /api/ops/queues
+func (h *AdminHandler) QueueStats(c fiber.Ctx) error {
+	stats, err := h.ops.QueueStats(c.Context())
+	if err != nil {
+		return middleware.ErrorResponse(c, fiber.StatusInternalServerError, "INTERNAL_ERROR", "Failed to read queue stats")
+	}
+	return c.JSON(stats)
+}
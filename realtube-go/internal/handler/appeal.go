@@ -0,0 +1,201 @@
+package handler
+
+import (
+	"errors"
+	"strconv"
+
+	"github.com/gofiber/fiber/v3"
+	"github.com/jackc/pgx/v5"
+
+	"github.com/mathieu-neron/RealTube/realtube-go/internal/middleware"
+	"github.com/mathieu-neron/RealTube/realtube-go/internal/model"
+	"github.com/mathieu-neron/RealTube/realtube-go/internal/repository"
+	"github.com/mathieu-neron/RealTube/realtube-go/internal/service"
+)
+
+type AppealHandler struct {
+	svc *service.AppealService
+}
+
+func NewAppealHandler(svc *service.AppealService) *AppealHandler {
+	return &AppealHandler{svc: svc}
+}
+
+// Submit handles POST /api/appeals
+func (h *AppealHandler) Submit(c fiber.Ctx) error {
+	var req model.AppealRequest
+	if err := c.Bind().JSON(&req); err != nil {
+		return middleware.ErrorResponse(c, fiber.StatusBadRequest, "INVALID_BODY", "Invalid request body")
+	}
+
+	if req.TargetType == "" || req.TargetID == "" || req.UserID == "" || req.Reason == "" {
+		return middleware.ErrorResponse(c, fiber.StatusBadRequest, "MISSING_FIELDS",
+			"targetType, targetId, userId and reason are required")
+	}
+
+	var errMsg string
+	switch req.TargetType {
+	case model.TargetVideo:
+		req.TargetID, errMsg = middleware.ValidateVideoID(req.TargetID)
+	case model.TargetChannel:
+		req.TargetID, errMsg = middleware.ValidateChannelID(req.TargetID)
+	default:
+		errMsg = "targetType must be video or channel"
+	}
+	if errMsg != "" {
+		return middleware.ErrorResponse(c, fiber.StatusBadRequest, "INVALID_FIELD", errMsg)
+	}
+	if req.UserID, errMsg = middleware.ValidateUserID(req.UserID); errMsg != "" {
+		return middleware.ErrorResponse(c, fiber.StatusBadRequest, "INVALID_FIELD", errMsg)
+	}
+	if req.Reason, errMsg = middleware.ValidateReason(req.Reason); errMsg != "" {
+		return middleware.ErrorResponse(c, fiber.StatusBadRequest, "INVALID_FIELD", errMsg)
+	}
+
+	appeal, err := h.svc.Submit(c.Context(), req)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return middleware.ErrorResponse(c, fiber.StatusNotFound, "NOT_FOUND", "Unknown "+req.TargetType)
+		}
+		if errors.Is(err, repository.ErrAppealPending) {
+			return middleware.ErrorResponse(c, fiber.StatusConflict, "APPEAL_PENDING",
+				"This "+req.TargetType+" already has a pending appeal")
+		}
+		return middleware.ErrorResponse(c, fiber.StatusInternalServerError, "INTERNAL_ERROR", "Failed to submit appeal")
+	}
+
+	return c.Status(fiber.StatusCreated).JSON(appeal.Public())
+}
+
+// Get handles GET /api/appeals/:id
+func (h *AppealHandler) Get(c fiber.Ctx) error {
+	id, ok := idParam(c)
+	if !ok {
+		return middleware.ErrorResponse(c, fiber.StatusBadRequest, "INVALID_FIELD", "id must be a positive integer")
+	}
+
+	appeal, err := h.svc.Get(c.Context(), id)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return middleware.ErrorResponse(c, fiber.StatusNotFound, "NOT_FOUND", "Appeal not found")
+		}
+		return middleware.ErrorResponse(c, fiber.StatusInternalServerError, "INTERNAL_ERROR", "Failed to lookup appeal")
+	}
+
+	return c.JSON(appeal.Public())
+}
+
+// Queue handles GET /api/vip/appeals?status=&targetType=&after=&limit=
+func (h *AppealHandler) Queue(c fiber.Ctx) error {
+	f := model.AppealQueueFilter{
+		Statuses:   []string{model.AppealOpen, model.AppealUnderReview},
+		TargetType: fiber.Query[string](c, "targetType"),
+		After:      fiber.Query[int64](c, "after"),
+		Limit:      fiber.Query[int](c, "limit", 50),
+	}
+	if status := fiber.Query[string](c, "status"); status != "" {
+		if !model.AppealStatuses[status] {
+			return middleware.ErrorResponse(c, fiber.StatusBadRequest, "INVALID_PARAM",
+				"status must be one of: open, under_review, upheld, rejected")
+		}
+		f.Statuses = []string{status}
+	}
+	if f.TargetType != "" && f.TargetType != model.TargetVideo && f.TargetType != model.TargetChannel {
+		return middleware.ErrorResponse(c, fiber.StatusBadRequest, "INVALID_PARAM", "targetType must be video or channel")
+	}
+	if f.Limit < 1 || f.Limit > 100 {
+		return middleware.ErrorResponse(c, fiber.StatusBadRequest, "INVALID_PARAM", "limit must be between 1 and 100")
+	}
+	if f.After < 0 {
+		return middleware.ErrorResponse(c, fiber.StatusBadRequest, "INVALID_PARAM", "after must be a positive appeal ID")
+	}
+
+	resp, err := h.svc.Queue(c.Context(), f)
+	if err != nil {
+		return middleware.ErrorResponse(c, fiber.StatusInternalServerError, "INTERNAL_ERROR", "Failed to fetch appeals")
+	}
+	return c.JSON(resp)
+}
+
+// Review handles POST /api/vip/appeals/:id/review
+func (h *AppealHandler) Review(c fiber.Ctx) error {
+	id, ok := idParam(c)
+	if !ok {
+		return middleware.ErrorResponse(c, fiber.StatusBadRequest, "INVALID_FIELD", "id must be a positive integer")
+	}
+
+	appeal, err := h.svc.Review(c.Context(), middleware.CurrentPrincipal(c), id)
+	if err != nil {
+		return appealError(c, err)
+	}
+	return c.JSON(appeal)
+}
+
+// Resolve handles POST /api/vip/appeals/:id/resolve
+func (h *AppealHandler) Resolve(c fiber.Ctx) error {
+	id, ok := idParam(c)
+	if !ok {
+		return middleware.ErrorResponse(c, fiber.StatusBadRequest, "INVALID_FIELD", "id must be a positive integer")
+	}
+
+	var req model.AppealResolveRequest
+	if err := c.Bind().JSON(&req); err != nil {
+		return middleware.ErrorResponse(c, fiber.StatusBadRequest, "INVALID_BODY", "Invalid request body")
+	}
+
+	if req.Status != model.AppealUpheld && req.Status != model.AppealRejected {
+		return middleware.ErrorResponse(c, fiber.StatusBadRequest, "INVALID_FIELD", "status must be upheld or rejected")
+	}
+	resolution, errMsg := middleware.ValidateReason(req.Resolution)
+	if errMsg != "" {
+		return middleware.ErrorResponse(c, fiber.StatusBadRequest, "INVALID_FIELD", "resolution: "+errMsg)
+	}
+	req.Resolution = resolution
+
+	if req.Score != nil {
+		if errMsg := middleware.ValidateScore(*req.Score); errMsg != "" {
+			return middleware.ErrorResponse(c, fiber.StatusBadRequest, "INVALID_FIELD", errMsg)
+		}
+	}
+	var check moderationCheck
+	switch req.Action {
+	case "", model.ActionLock, model.ActionUnlock:
+	case model.ActionOverrideScore:
+		check = checkScoreOverride
+	case model.ActionOverrideCategory:
+		check = checkCategoryOverride
+	default:
+		return middleware.ErrorResponse(c, fiber.StatusBadRequest, "INVALID_FIELD",
+			"action must be one of: lock, unlock, override_score, override_category")
+	}
+	if check != nil {
+		if code, msg := check(model.ModerationRequest{Reason: req.Resolution, Score: req.Score, Category: req.Category}); code != "" {
+			return middleware.ErrorResponse(c, fiber.StatusBadRequest, code, msg)
+		}
+	}
+
+	appeal, err := h.svc.Resolve(c.Context(), middleware.CurrentPrincipal(c), id, req)
+	if err != nil {
+		return appealError(c, err)
+	}
+	return c.JSON(appeal)
+}
+
+// idParam parses a positive :id route parameter.
+func idParam(c fiber.Ctx) (int64, bool) {
+	id, err := strconv.ParseInt(c.Params("id"), 10, 64)
+	return id, err == nil && id > 0
+}
+
+// appealError maps the errors of the moderator appeal endpoints.
+func appealError(c fiber.Ctx, err error) error {
+	switch {
+	case errors.Is(err, pgx.ErrNoRows):
+		return middleware.ErrorResponse(c, fiber.StatusNotFound, "NOT_FOUND", "Appeal not found")
+	case errors.Is(err, service.ErrAppealNotPending):
+		return middleware.ErrorResponse(c, fiber.StatusConflict, "APPEAL_NOT_PENDING", "Appeal is already resolved or under review")
+	case errors.Is(err, service.ErrInvalidAppealAction), errors.Is(err, service.ErrInvalidOverride):
+		return middleware.ErrorResponse(c, fiber.StatusBadRequest, "INVALID_ACTION", err.Error())
+	}
+	return middleware.ErrorResponse(c, fiber.StatusInternalServerError, "INTERNAL_ERROR", "Failed to process appeal")
+}
@@ -0,0 +1,98 @@
+package handler
+
+import (
+	"errors"
+
+	"github.com/gofiber/fiber/v3"
+	"github.com/jackc/pgx/v5"
+
+	"github.com/mathieu-neron/RealTube/realtube-go/internal/middleware"
+	"github.com/mathieu-neron/RealTube/realtube-go/internal/model"
+	"github.com/mathieu-neron/RealTube/realtube-go/internal/service"
+)
+
+type BrigadeHandler struct {
+	svc *service.BrigadeService
+}
+
+func NewBrigadeHandler(svc *service.BrigadeService) *BrigadeHandler {
+	return &BrigadeHandler{svc: svc}
+}
+
+// List handles GET /api/vip/brigades?status=&after=&limit=
+func (h *BrigadeHandler) List(c fiber.Ctx) error {
+	f := model.BrigadeFilter{
+		Status: fiber.Query[string](c, "status"),
+		After:  fiber.Query[int64](c, "after"),
+		Limit:  fiber.Query[int](c, "limit", 50),
+	}
+	if f.Status != "" && !model.BrigadeStatuses[f.Status] {
+		return middleware.ErrorResponse(c, fiber.StatusBadRequest, "INVALID_PARAM",
+			"status must be one of: frozen, released, expired")
+	}
+	if f.Limit < 1 || f.Limit > 100 {
+		return middleware.ErrorResponse(c, fiber.StatusBadRequest, "INVALID_PARAM", "limit must be between 1 and 100")
+	}
+	if f.After < 0 {
+		return middleware.ErrorResponse(c, fiber.StatusBadRequest, "INVALID_PARAM", "after must be a positive brigade ID")
+	}
+
+	resp, err := h.svc.List(c.Context(), f)
+	if err != nil {
+		return middleware.ErrorResponse(c, fiber.StatusInternalServerError, "INTERNAL_ERROR", "Failed to fetch brigades")
+	}
+	return c.JSON(resp)
+}
+
+// Get handles GET /api/vip/brigades/:id
+func (h *BrigadeHandler) Get(c fiber.Ctx) error {
+	id, ok := idParam(c)
+	if !ok {
+		return middleware.ErrorResponse(c, fiber.StatusBadRequest, "INVALID_FIELD", "id must be a positive integer")
+	}
+
+	brigade, err := h.svc.Get(c.Context(), id)
+	if err != nil {
+		return brigadeError(c, err)
+	}
+	return c.JSON(brigade)
+}
+
+// Hold handles POST /api/vip/brigades/:id/hold
+func (h *BrigadeHandler) Hold(c fiber.Ctx) error {
+	id, ok := idParam(c)
+	if !ok {
+		return middleware.ErrorResponse(c, fiber.StatusBadRequest, "INVALID_FIELD", "id must be a positive integer")
+	}
+
+	resp, err := h.svc.Hold(c.Context(), middleware.CurrentPrincipal(c), id)
+	if err != nil {
+		return brigadeError(c, err)
+	}
+	return c.JSON(resp)
+}
+
+// Release handles POST /api/vip/brigades/:id/release
+func (h *BrigadeHandler) Release(c fiber.Ctx) error {
+	id, ok := idParam(c)
+	if !ok {
+		return middleware.ErrorResponse(c, fiber.StatusBadRequest, "INVALID_FIELD", "id must be a positive integer")
+	}
+
+	resp, err := h.svc.Release(c.Context(), middleware.CurrentPrincipal(c), id)
+	if err != nil {
+		return brigadeError(c, err)
+	}
+	return c.JSON(resp)
+}
+
+// brigadeError maps the errors of the brigade endpoints.
+func brigadeError(c fiber.Ctx, err error) error {
+	switch {
+	case errors.Is(err, pgx.ErrNoRows):
+		return middleware.ErrorResponse(c, fiber.StatusNotFound, "NOT_FOUND", "Brigade not found")
+	case errors.Is(err, service.ErrBrigadeStatus):
+		return middleware.ErrorResponse(c, fiber.StatusConflict, "INVALID_STATUS", "Brigade is already released or expired")
+	}
+	return middleware.ErrorResponse(c, fiber.StatusInternalServerError, "INTERNAL_ERROR", "Failed to process brigade")
+}
@@ -0,0 +1,35 @@
+package handler
+
+import (
+	"github.com/gofiber/fiber/v3"
+
+	"github.com/mathieu-neron/RealTube/realtube-go/internal/middleware"
+	"github.com/mathieu-neron/RealTube/realtube-go/internal/service"
+)
+
+type ChallengeHandler struct {
+	svc *service.ChallengeService
+}
+
+func NewChallengeHandler(svc *service.ChallengeService) *ChallengeHandler {
+	return &ChallengeHandler{svc: svc}
+}
+
+// Issue handles GET /api/challenge?userId=X&videoId=Y
+func (h *ChallengeHandler) Issue(c fiber.Ctx) error {
+	userID, errMsg := middleware.ValidateUserID(fiber.Query[string](c, "userId"))
+	if errMsg != "" {
+		return middleware.ErrorResponse(c, fiber.StatusBadRequest, "INVALID_FIELD", errMsg)
+	}
+	videoID, errMsg := middleware.ValidateVideoID(fiber.Query[string](c, "videoId"))
+	if errMsg != "" {
+		return middleware.ErrorResponse(c, fiber.StatusBadRequest, "INVALID_FIELD", errMsg)
+	}
+
+	// The challenge is bound to the salted hash of the IP
+	resp, err := h.svc.Issue(c.Context(), userID, videoID, c.IP())
+	if err != nil {
+		return middleware.ErrorResponse(c, fiber.StatusInternalServerError, "INTERNAL_ERROR", "Failed to issue challenge")
+	}
+	return c.JSON(resp)
+}
@@ -2,6 +2,7 @@ package handler
 
 import (
 	"strconv"
+	"strings"
 	"time"
 
 	"github.com/gofiber/fiber/v3"
@@ -21,6 +22,7 @@ var Metrics = struct {
 	CacheHits            prometheus.Counter
 	CacheMisses          prometheus.Counter
 	ScoreRecalcDuration  prometheus.Histogram
+	ScoreRecalcBatchSize prometheus.Histogram
 }{}
 
 // InitMetrics registers all Prometheus metrics. Call once at startup.
@@ -71,6 +73,14 @@ func InitMetrics(pool *pgxpool.Pool) {
 		},
 	)
 
+	Metrics.ScoreRecalcBatchSize = prometheus.NewHistogram(
+		prometheus.HistogramOpts{
+			Name:    "realtube_score_recalculation_batch_size",
+			Help:    "Number of videos recalculated per score worker batch.",
+			Buckets: prometheus.ExponentialBuckets(1, 4, 8),
+		},
+	)
+
 	// DB pool gauges — read live stats from pgxpool
 	if pool != nil {
 		Metrics.DBPoolActive = prometheus.NewGaugeFunc(
@@ -104,6 +114,7 @@ func InitMetrics(pool *pgxpool.Pool) {
 		Metrics.CacheHits,
 		Metrics.CacheMisses,
 		Metrics.ScoreRecalcDuration,
+		Metrics.ScoreRecalcBatchSize,
 	)
 }
 
@@ -145,6 +156,12 @@ func sanitizeEndpoint(path string) string {
 	case len(path) > 14 && path[:14] == "/api/channels/":
 		return "/api/channels/:channelId"
 	case len(path) > 11 && path[:11] == "/api/users/":
+		if strings.HasSuffix(path, "/trust/history") {
+			return "/api/users/:userId/trust/history"
+		}
+		if strings.HasSuffix(path, "/trust") {
+			return "/api/users/:userId/trust"
+		}
 		return "/api/users/:userId"
 	default:
 		return path
@@ -0,0 +1,210 @@
+package handler
+
+import (
+	"context"
+	"errors"
+
+	"github.com/gofiber/fiber/v3"
+	"github.com/jackc/pgx/v5"
+
+	"github.com/mathieu-neron/RealTube/realtube-go/internal/middleware"
+	"github.com/mathieu-neron/RealTube/realtube-go/internal/model"
+	"github.com/mathieu-neron/RealTube/realtube-go/internal/repository"
+	"github.com/mathieu-neron/RealTube/realtube-go/internal/service"
+)
+
+// moderationFunc applies a moderation action to a target on behalf of actor.
+type moderationFunc func(ctx context.Context, actor *model.Principal, targetID string, req model.ModerationRequest) (*model.VIPAction, error)
+
+// moderationCheck validates the action-specific fields of a request and
+// returns an error code and message, or "" if the request is valid.
+type moderationCheck func(req model.ModerationRequest) (code, message string)
+
+type ModerationHandler struct {
+	svc *service.ModerationService
+}
+
+func NewModerationHandler(svc *service.ModerationService) *ModerationHandler {
+	return &ModerationHandler{svc: svc}
+}
+
+// LockVideo handles POST /api/vip/videos/:videoId/lock
+func (h *ModerationHandler) LockVideo(c fiber.Ctx) error {
+	return h.moderateVideo(c, nil, func(ctx context.Context, actor *model.Principal, id string, req model.ModerationRequest) (*model.VIPAction, error) {
+		return h.svc.SetVideoLocked(ctx, actor, id, req.Reason, true)
+	})
+}
+
+// UnlockVideo handles DELETE /api/vip/videos/:videoId/lock
+func (h *ModerationHandler) UnlockVideo(c fiber.Ctx) error {
+	return h.moderateVideo(c, nil, func(ctx context.Context, actor *model.Principal, id string, req model.ModerationRequest) (*model.VIPAction, error) {
+		return h.svc.SetVideoLocked(ctx, actor, id, req.Reason, false)
+	})
+}
+
+// HideVideo handles POST /api/vip/videos/:videoId/hide
+func (h *ModerationHandler) HideVideo(c fiber.Ctx) error {
+	return h.moderateVideo(c, nil, func(ctx context.Context, actor *model.Principal, id string, req model.ModerationRequest) (*model.VIPAction, error) {
+		return h.svc.SetVideoHidden(ctx, actor, id, req.Reason, true)
+	})
+}
+
+// UnhideVideo handles DELETE /api/vip/videos/:videoId/hide
+func (h *ModerationHandler) UnhideVideo(c fiber.Ctx) error {
+	return h.moderateVideo(c, nil, func(ctx context.Context, actor *model.Principal, id string, req model.ModerationRequest) (*model.VIPAction, error) {
+		return h.svc.SetVideoHidden(ctx, actor, id, req.Reason, false)
+	})
+}
+
+// OverrideScore handles PUT /api/vip/videos/:videoId/score
+func (h *ModerationHandler) OverrideScore(c fiber.Ctx) error {
+	return h.moderateVideo(c, checkScoreOverride, func(ctx context.Context, actor *model.Principal, id string, req model.ModerationRequest) (*model.VIPAction, error) {
+		return h.svc.OverrideScore(ctx, actor, id, req.Reason, *req.Score)
+	})
+}
+
+// OverrideCategory handles PUT /api/vip/videos/:videoId/category
+func (h *ModerationHandler) OverrideCategory(c fiber.Ctx) error {
+	return h.moderateVideo(c, checkCategoryOverride, func(ctx context.Context, actor *model.Principal, id string, req model.ModerationRequest) (*model.VIPAction, error) {
+		return h.svc.OverrideCategory(ctx, actor, id, req.Reason, req.Category, req.Score)
+	})
+}
+
+// LockChannel handles POST /api/vip/channels/:channelId/lock
+func (h *ModerationHandler) LockChannel(c fiber.Ctx) error {
+	return h.moderateChannel(c, nil, func(ctx context.Context, actor *model.Principal, id string, req model.ModerationRequest) (*model.VIPAction, error) {
+		return h.svc.SetChannelLocked(ctx, actor, id, req.Reason, true)
+	})
+}
+
+// UnlockChannel handles DELETE /api/vip/channels/:channelId/lock
+func (h *ModerationHandler) UnlockChannel(c fiber.Ctx) error {
+	return h.moderateChannel(c, nil, func(ctx context.Context, actor *model.Principal, id string, req model.ModerationRequest) (*model.VIPAction, error) {
+		return h.svc.SetChannelLocked(ctx, actor, id, req.Reason, false)
+	})
+}
+
+// HideChannel handles POST /api/vip/channels/:channelId/hide
+func (h *ModerationHandler) HideChannel(c fiber.Ctx) error {
+	return h.moderateChannel(c, nil, func(ctx context.Context, actor *model.Principal, id string, req model.ModerationRequest) (*model.VIPAction, error) {
+		return h.svc.SetChannelHidden(ctx, actor, id, req.Reason, true)
+	})
+}
+
+// UnhideChannel handles DELETE /api/vip/channels/:channelId/hide
+func (h *ModerationHandler) UnhideChannel(c fiber.Ctx) error {
+	return h.moderateChannel(c, nil, func(ctx context.Context, actor *model.Principal, id string, req model.ModerationRequest) (*model.VIPAction, error) {
+		return h.svc.SetChannelHidden(ctx, actor, id, req.Reason, false)
+	})
+}
+
+// GrantVIP handles POST /api/admin/users/:userId/vip
+func (h *ModerationHandler) GrantVIP(c fiber.Ctx) error {
+	return h.moderateUser(c, func(ctx context.Context, actor *model.Principal, id string, req model.ModerationRequest) (*model.VIPAction, error) {
+		return h.svc.SetUserVIP(ctx, actor, id, req.Reason, true)
+	})
+}
+
+// RevokeVIP handles DELETE /api/admin/users/:userId/vip
+func (h *ModerationHandler) RevokeVIP(c fiber.Ctx) error {
+	return h.moderateUser(c, func(ctx context.Context, actor *model.Principal, id string, req model.ModerationRequest) (*model.VIPAction, error) {
+		return h.svc.SetUserVIP(ctx, actor, id, req.Reason, false)
+	})
+}
+
+// ShadowbanUser handles POST /api/admin/users/:userId/shadowban
+func (h *ModerationHandler) ShadowbanUser(c fiber.Ctx) error {
+	return h.moderateUser(c, func(ctx context.Context, actor *model.Principal, id string, req model.ModerationRequest) (*model.VIPAction, error) {
+		return h.svc.SetUserShadowbanned(ctx, actor, id, req.Reason, true)
+	})
+}
+
+// UnshadowbanUser handles DELETE /api/admin/users/:userId/shadowban
+func (h *ModerationHandler) UnshadowbanUser(c fiber.Ctx) error {
+	return h.moderateUser(c, func(ctx context.Context, actor *model.Principal, id string, req model.ModerationRequest) (*model.VIPAction, error) {
+		return h.svc.SetUserShadowbanned(ctx, actor, id, req.Reason, false)
+	})
+}
+
+func (h *ModerationHandler) moderateVideo(c fiber.Ctx, check moderationCheck, apply moderationFunc) error {
+	videoID, errMsg := middleware.ValidateVideoID(c.Params("videoId"))
+	if errMsg != "" {
+		return middleware.ErrorResponse(c, fiber.StatusBadRequest, "INVALID_FIELD", errMsg)
+	}
+	return h.moderate(c, videoID, "Video not found", check, apply)
+}
+
+func (h *ModerationHandler) moderateChannel(c fiber.Ctx, check moderationCheck, apply moderationFunc) error {
+	channelID, errMsg := middleware.ValidateChannelID(c.Params("channelId"))
+	if errMsg != "" {
+		return middleware.ErrorResponse(c, fiber.StatusBadRequest, "INVALID_FIELD", errMsg)
+	}
+	return h.moderate(c, channelID, "Channel not found", check, apply)
+}
+
+func (h *ModerationHandler) moderateUser(c fiber.Ctx, apply moderationFunc) error {
+	userID, errMsg := middleware.ValidateUserID(c.Params("userId"))
+	if errMsg != "" {
+		return middleware.ErrorResponse(c, fiber.StatusBadRequest, "INVALID_FIELD", errMsg)
+	}
+	return h.moderate(c, userID, "User not found", nil, apply)
+}
+
+// moderate validates the request body and applies the action to targetID.
+func (h *ModerationHandler) moderate(c fiber.Ctx, targetID, notFound string, check moderationCheck, apply moderationFunc) error {
+	var req model.ModerationRequest
+	if err := c.Bind().JSON(&req); err != nil {
+		return middleware.ErrorResponse(c, fiber.StatusBadRequest, "INVALID_BODY", "Invalid request body")
+	}
+
+	reason, errMsg := middleware.ValidateReason(req.Reason)
+	if errMsg != "" {
+		return middleware.ErrorResponse(c, fiber.StatusBadRequest, "INVALID_FIELD", errMsg)
+	}
+	req.Reason = reason
+
+	if req.Score != nil {
+		if errMsg := middleware.ValidateScore(*req.Score); errMsg != "" {
+			return middleware.ErrorResponse(c, fiber.StatusBadRequest, "INVALID_FIELD", errMsg)
+		}
+	}
+	if check != nil {
+		if code, msg := check(req); code != "" {
+			return middleware.ErrorResponse(c, fiber.StatusBadRequest, code, msg)
+		}
+	}
+
+	action, err := apply(c.Context(), middleware.CurrentPrincipal(c), targetID, req)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return middleware.ErrorResponse(c, fiber.StatusNotFound, "NOT_FOUND", notFound)
+		}
+		if errors.Is(err, service.ErrInvalidOverride) {
+			return middleware.ErrorResponse(c, fiber.StatusBadRequest, "INVALID_OVERRIDE", err.Error())
+		}
+		return middleware.ErrorResponse(c, fiber.StatusInternalServerError, "INTERNAL_ERROR", "Failed to apply moderation action")
+	}
+
+	return c.JSON(model.ModerationResponse{Success: true, Action: *action})
+}
+
+// checkScoreOverride requires the score of a score override.
+func checkScoreOverride(req model.ModerationRequest) (string, string) {
+	if req.Score == nil {
+		return "MISSING_FIELDS", "score and reason are required"
+	}
+	return "", ""
+}
+
+// checkCategoryOverride requires a valid category for a category override;
+// the score is optional.
+func checkCategoryOverride(req model.ModerationRequest) (string, string) {
+	if req.Category == "" {
+		return "MISSING_FIELDS", "category and reason are required"
+	}
+	if !repository.ValidCategories[req.Category] {
+		return "INVALID_CATEGORY",
+			"Invalid category. Must be one of: fully_ai, ai_voiceover, ai_visuals, ai_thumbnails, ai_assisted, not_ai"
+	}
+	return "", ""
+}
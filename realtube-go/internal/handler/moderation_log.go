@@ -0,0 +1,104 @@
+package handler
+
+import (
+	"time"
+
+	"github.com/gofiber/fiber/v3"
+
+	"github.com/mathieu-neron/RealTube/realtube-go/internal/middleware"
+	"github.com/mathieu-neron/RealTube/realtube-go/internal/model"
+	"github.com/mathieu-neron/RealTube/realtube-go/internal/service"
+)
+
+type ModerationLogHandler struct {
+	svc *service.ModerationLogService
+}
+
+func NewModerationLogHandler(svc *service.ModerationLogService) *ModerationLogHandler {
+	return &ModerationLogHandler{svc: svc}
+}
+
+// Log handles GET /api/moderation/log?targetType=&actionType=&since=&until=&before=&limit=
+func (h *ModerationLogHandler) Log(c fiber.Ctx) error {
+	return h.filteredLog(c, true)
+}
+
+// FullLog handles GET /api/vip/log, the log without redactions, with the
+// same query parameters as Log.
+func (h *ModerationLogHandler) FullLog(c fiber.Ctx) error {
+	return h.filteredLog(c, false)
+}
+
+// VideoLog handles GET /api/moderation/videos/:videoId
+func (h *ModerationLogHandler) VideoLog(c fiber.Ctx) error {
+	videoID, errMsg := middleware.ValidateVideoID(c.Params("videoId"))
+	if errMsg != "" {
+		return middleware.ErrorResponse(c, fiber.StatusBadRequest, "INVALID_FIELD", errMsg)
+	}
+	return h.log(c, model.TargetVideo, videoID, true)
+}
+
+// ChannelLog handles GET /api/moderation/channels/:channelId
+func (h *ModerationLogHandler) ChannelLog(c fiber.Ctx) error {
+	channelID, errMsg := middleware.ValidateChannelID(c.Params("channelId"))
+	if errMsg != "" {
+		return middleware.ErrorResponse(c, fiber.StatusBadRequest, "INVALID_FIELD", errMsg)
+	}
+	return h.log(c, model.TargetChannel, channelID, true)
+}
+
+func (h *ModerationLogHandler) filteredLog(c fiber.Ctx, public bool) error {
+	targetType := fiber.Query[string](c, "targetType")
+	if targetType != "" && !model.TargetTypes[targetType] {
+		return middleware.ErrorResponse(c, fiber.StatusBadRequest, "INVALID_PARAM", "targetType must be one of: video, channel, user, sybil_cluster")
+	}
+	return h.log(c, targetType, "", public)
+}
+
+// log parses the shared query parameters and returns a page of the log.
+func (h *ModerationLogHandler) log(c fiber.Ctx, targetType, targetID string, public bool) error {
+	f := model.ModerationLogFilter{
+		TargetType: targetType,
+		TargetID:   targetID,
+		ActionType: fiber.Query[string](c, "actionType"),
+		Before:     fiber.Query[int64](c, "before"),
+		Limit:      fiber.Query[int](c, "limit", 50),
+	}
+	if f.ActionType != "" && !model.ActionTypes[f.ActionType] {
+		return middleware.ErrorResponse(c, fiber.StatusBadRequest, "INVALID_PARAM", "unknown actionType")
+	}
+	if f.Limit < 1 || f.Limit > 100 {
+		return middleware.ErrorResponse(c, fiber.StatusBadRequest, "INVALID_PARAM", "limit must be between 1 and 100")
+	}
+	if f.Before < 0 {
+		return middleware.ErrorResponse(c, fiber.StatusBadRequest, "INVALID_PARAM", "before must be a positive entry ID")
+	}
+
+	var ok bool
+	if f.Since, ok = queryTime(c, "since"); !ok {
+		return middleware.ErrorResponse(c, fiber.StatusBadRequest, "INVALID_PARAM", "since must be a valid RFC3339 timestamp")
+	}
+	if f.Until, ok = queryTime(c, "until"); !ok {
+		return middleware.ErrorResponse(c, fiber.StatusBadRequest, "INVALID_PARAM", "until must be a valid RFC3339 timestamp")
+	}
+
+	resp, err := h.svc.Log(c.Context(), f, public)
+	if err != nil {
+		return middleware.ErrorResponse(c, fiber.StatusInternalServerError, "INTERNAL_ERROR", "Failed to fetch moderation log")
+	}
+	return c.JSON(resp)
+}
+
+// queryTime parses an optional RFC3339 query parameter. It returns nil if
+// the parameter is absent and false if it is invalid.
+func queryTime(c fiber.Ctx, key string) (*time.Time, bool) {
+	v := fiber.Query[string](c, key)
+	if v == "" {
+		return nil, true
+	}
+	t, err := time.Parse(time.RFC3339, v)
+	if err != nil {
+		return nil, false
+	}
+	return &t, true
+}
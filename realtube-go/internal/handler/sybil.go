@@ -0,0 +1,98 @@
+package handler
+
+import (
+	"errors"
+
+	"github.com/gofiber/fiber/v3"
+	"github.com/jackc/pgx/v5"
+
+	"github.com/mathieu-neron/RealTube/realtube-go/internal/middleware"
+	"github.com/mathieu-neron/RealTube/realtube-go/internal/model"
+	"github.com/mathieu-neron/RealTube/realtube-go/internal/service"
+)
+
+type SybilHandler struct {
+	svc *service.SybilService
+}
+
+func NewSybilHandler(svc *service.SybilService) *SybilHandler {
+	return &SybilHandler{svc: svc}
+}
+
+// List handles GET /api/vip/sybil/clusters?status=&after=&limit=
+func (h *SybilHandler) List(c fiber.Ctx) error {
+	f := model.SybilClusterFilter{
+		Status: fiber.Query[string](c, "status"),
+		After:  fiber.Query[int64](c, "after"),
+		Limit:  fiber.Query[int](c, "limit", 50),
+	}
+	if f.Status != "" && !model.SybilStatuses[f.Status] {
+		return middleware.ErrorResponse(c, fiber.StatusBadRequest, "INVALID_PARAM",
+			"status must be one of: pending, dampened, dismissed")
+	}
+	if f.Limit < 1 || f.Limit > 100 {
+		return middleware.ErrorResponse(c, fiber.StatusBadRequest, "INVALID_PARAM", "limit must be between 1 and 100")
+	}
+	if f.After < 0 {
+		return middleware.ErrorResponse(c, fiber.StatusBadRequest, "INVALID_PARAM", "after must be a positive cluster ID")
+	}
+
+	resp, err := h.svc.List(c.Context(), f)
+	if err != nil {
+		return middleware.ErrorResponse(c, fiber.StatusInternalServerError, "INTERNAL_ERROR", "Failed to fetch sybil clusters")
+	}
+	return c.JSON(resp)
+}
+
+// Get handles GET /api/vip/sybil/clusters/:id
+func (h *SybilHandler) Get(c fiber.Ctx) error {
+	id, ok := idParam(c)
+	if !ok {
+		return middleware.ErrorResponse(c, fiber.StatusBadRequest, "INVALID_FIELD", "id must be a positive integer")
+	}
+
+	cluster, err := h.svc.Get(c.Context(), id)
+	if err != nil {
+		return sybilError(c, err)
+	}
+	return c.JSON(cluster)
+}
+
+// Dampen handles POST /api/vip/sybil/clusters/:id/dampen
+func (h *SybilHandler) Dampen(c fiber.Ctx) error {
+	id, ok := idParam(c)
+	if !ok {
+		return middleware.ErrorResponse(c, fiber.StatusBadRequest, "INVALID_FIELD", "id must be a positive integer")
+	}
+
+	resp, err := h.svc.Dampen(c.Context(), middleware.CurrentPrincipal(c), id)
+	if err != nil {
+		return sybilError(c, err)
+	}
+	return c.JSON(resp)
+}
+
+// Dismiss handles POST /api/vip/sybil/clusters/:id/dismiss
+func (h *SybilHandler) Dismiss(c fiber.Ctx) error {
+	id, ok := idParam(c)
+	if !ok {
+		return middleware.ErrorResponse(c, fiber.StatusBadRequest, "INVALID_FIELD", "id must be a positive integer")
+	}
+
+	resp, err := h.svc.Dismiss(c.Context(), middleware.CurrentPrincipal(c), id)
+	if err != nil {
+		return sybilError(c, err)
+	}
+	return c.JSON(resp)
+}
+
+// sybilError maps the errors of the sybil cluster endpoints.
+func sybilError(c fiber.Ctx, err error) error {
+	switch {
+	case errors.Is(err, pgx.ErrNoRows):
+		return middleware.ErrorResponse(c, fiber.StatusNotFound, "NOT_FOUND", "Sybil cluster not found")
+	case errors.Is(err, service.ErrSybilStatus):
+		return middleware.ErrorResponse(c, fiber.StatusConflict, "INVALID_STATUS", "Cluster is already dampened or dismissed")
+	}
+	return middleware.ErrorResponse(c, fiber.StatusInternalServerError, "INTERNAL_ERROR", "Failed to process sybil cluster")
+}
@@ -29,3 +29,38 @@ func (h *UserHandler) GetByUserID(c fiber.Ctx) error {
 
 	return c.JSON(resp)
 }
+
+// GetTrust handles GET /api/users/:userId/trust
+func (h *UserHandler) GetTrust(c fiber.Ctx) error {
+	userID, errMsg := middleware.ValidateUserID(c.Params("userId"))
+	if errMsg != "" {
+		return middleware.ErrorResponse(c, fiber.StatusBadRequest, "INVALID_FIELD", errMsg)
+	}
+
+	resp, err := h.svc.ExplainTrust(c.Context(), userID)
+	if err != nil {
+		return middleware.ErrorResponse(c, fiber.StatusInternalServerError, "INTERNAL_ERROR", "Failed to explain user trust")
+	}
+
+	return c.JSON(resp)
+}
+
+// GetTrustHistory handles GET /api/users/:userId/trust/history?limit=N
+func (h *UserHandler) GetTrustHistory(c fiber.Ctx) error {
+	userID, errMsg := middleware.ValidateUserID(c.Params("userId"))
+	if errMsg != "" {
+		return middleware.ErrorResponse(c, fiber.StatusBadRequest, "INVALID_FIELD", errMsg)
+	}
+
+	limit := fiber.Query[int](c, "limit", 30)
+	if limit < 1 || limit > 365 {
+		return middleware.ErrorResponse(c, fiber.StatusBadRequest, "INVALID_PARAM", "limit must be between 1 and 365")
+	}
+
+	history, err := h.svc.TrustHistory(c.Context(), userID, limit)
+	if err != nil {
+		return middleware.ErrorResponse(c, fiber.StatusInternalServerError, "INTERNAL_ERROR", "Failed to fetch trust history")
+	}
+
+	return c.JSON(fiber.Map{"userId": userID, "history": history})
+}
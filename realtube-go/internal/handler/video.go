@@ -25,7 +25,12 @@ func (h *VideoHandler) GetByHashPrefix(c fiber.Ctx) error {
 		return middleware.ErrorResponse(c, fiber.StatusBadRequest, "INVALID_PREFIX", errMsg)
 	}
 
-	videos, err := h.svc.LookupByHashPrefix(c.Context(), prefix)
+	channelID, errMsg := channelHint(c)
+	if errMsg != "" {
+		return middleware.ErrorResponse(c, fiber.StatusBadRequest, "INVALID_FIELD", errMsg)
+	}
+
+	videos, err := h.svc.LookupByHashPrefix(c.Context(), prefix, channelID)
 	if err != nil {
 		return middleware.ErrorResponse(c, fiber.StatusInternalServerError, "INTERNAL_ERROR", "Failed to lookup videos")
 	}
@@ -44,7 +49,12 @@ func (h *VideoHandler) GetByVideoID(c fiber.Ctx) error {
 		return middleware.ErrorResponse(c, fiber.StatusBadRequest, "INVALID_FIELD", errMsg)
 	}
 
-	video, err := h.svc.LookupByVideoID(c.Context(), videoID)
+	channelID, errMsg := channelHint(c)
+	if errMsg != "" {
+		return middleware.ErrorResponse(c, fiber.StatusBadRequest, "INVALID_FIELD", errMsg)
+	}
+
+	video, err := h.svc.LookupByVideoID(c.Context(), videoID, channelID)
 	if err != nil {
 		if errors.Is(err, pgx.ErrNoRows) {
 			return middleware.ErrorResponse(c, fiber.StatusNotFound, "NOT_FOUND", "Video not found")
@@ -54,3 +64,14 @@ func (h *VideoHandler) GetByVideoID(c fiber.Ctx) error {
 
 	return c.JSON(video)
 }
+
+// channelHint validates the optional channelId query parameter, which lets
+// lookups return a provisional score for unknown videos of auto-flagged
+// channels.
+func channelHint(c fiber.Ctx) (string, string) {
+	channelID := fiber.Query[string](c, "channelId")
+	if channelID == "" {
+		return "", ""
+	}
+	return middleware.ValidateChannelID(channelID)
+}
@@ -48,21 +48,64 @@ func (h *VoteHandler) Submit(c fiber.Ctx) error {
 	}
 	if !repository.ValidCategories[req.Category] {
 		return middleware.ErrorResponse(c, fiber.StatusBadRequest, "INVALID_CATEGORY",
-			"Invalid category. Must be one of: fully_ai, ai_voiceover, ai_visuals, ai_thumbnails, ai_assisted")
+			"Invalid category. Must be one of: fully_ai, ai_voiceover, ai_visuals, ai_thumbnails, ai_assisted, not_ai")
 	}
 
 	// Sanitize optional userAgent
 	req.UserAgent = middleware.ValidateUserAgent(req.UserAgent)
 
-	// Extract IP for abuse tracking
-	ip := c.IP()
-	ipHash := ip
+	// Validate optional video metadata
+	if req.ChannelID != "" {
+		channelID, errMsg := middleware.ValidateChannelID(req.ChannelID)
+		if errMsg != "" {
+			return middleware.ErrorResponse(c, fiber.StatusBadRequest, "INVALID_FIELD", errMsg)
+		}
+		req.ChannelID = channelID
+	}
+	if req.Title != "" {
+		title, errMsg := middleware.ValidateTitle(req.Title)
+		if errMsg != "" {
+			return middleware.ErrorResponse(c, fiber.StatusBadRequest, "INVALID_FIELD", errMsg)
+		}
+		req.Title = title
+	}
+	if req.VideoDuration != nil {
+		if errMsg := middleware.ValidateVideoDuration(*req.VideoDuration); errMsg != "" {
+			return middleware.ErrorResponse(c, fiber.StatusBadRequest, "INVALID_FIELD", errMsg)
+		}
+	}
+	if req.IsShort != nil {
+		if errMsg := middleware.ValidateIsShort(*req.IsShort, req.VideoDuration); errMsg != "" {
+			return middleware.ErrorResponse(c, fiber.StatusBadRequest, "INVALID_FIELD", errMsg)
+		}
+	}
 
-	resp, err := h.svc.Submit(c.Context(), req, ipHash)
+	// Validate optional proof-of-work challenge
+	if errMsg := middleware.ValidateChallenge(req.Challenge, req.Solution); errMsg != "" {
+		return middleware.ErrorResponse(c, fiber.StatusBadRequest, "INVALID_FIELD", errMsg)
+	}
+
+	// The service stores only the salted hash of the IP (abuse tracking)
+	resp, err := h.svc.Submit(c.Context(), req, c.IP())
 	if err != nil {
 		if strings.Contains(err.Error(), "invalid category") {
 			return middleware.ErrorResponse(c, fiber.StatusBadRequest, "INVALID_CATEGORY", err.Error())
 		}
+		if errors.Is(err, repository.ErrVideoLocked) {
+			return middleware.ErrorResponse(c, fiber.StatusConflict, "VIDEO_LOCKED", "Video is locked by a moderator")
+		}
+		if errors.Is(err, service.ErrChallengeRequired) {
+			return middleware.ErrorResponse(c, fiber.StatusForbidden, "CHALLENGE_REQUIRED",
+				"Solve a challenge from GET /api/challenge and resubmit the vote")
+		}
+		if errors.Is(err, service.ErrChallengeInvalid) {
+			return middleware.ErrorResponse(c, fiber.StatusForbidden, "INVALID_CHALLENGE",
+				"Challenge is invalid, expired or already used")
+		}
+		if errors.Is(err, service.ErrIPRateLimited) {
+			return middleware.ErrorResponse(c, fiber.StatusTooManyRequests, "RATE_LIMITED",
+				"Too many votes from this network. Try again later.")
+		}
 		return middleware.ErrorResponse(c, fiber.StatusInternalServerError, "INTERNAL_ERROR", "Failed to submit vote")
 	}
 
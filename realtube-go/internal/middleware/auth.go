@@ -0,0 +1,75 @@
+package middleware
+
+import (
+	"context"
+	"slices"
+	"strings"
+
+	"github.com/gofiber/fiber/v3"
+
+	"github.com/mathieu-neron/RealTube/realtube-go/internal/model"
+)
+
+// MaxTokenLen bounds the bearer token accepted as a credential.
+const MaxTokenLen = 128
+
+const principalKey = "principal"
+
+// Authenticator resolves a bearer token to a principal, or returns nil if
+// the token is not a valid credential.
+type Authenticator func(ctx context.Context, token string) (*model.Principal, error)
+
+// NewAuth returns a middleware that authenticates the caller from an
+// "Authorization: Bearer <token>" header and rejects requests without valid
+// credentials. Use RequireRole after it to restrict a route group to roles.
+func NewAuth(authenticate Authenticator) fiber.Handler {
+	return func(c fiber.Ctx) error {
+		token, ok := bearerToken(c.Get(fiber.HeaderAuthorization))
+		if !ok {
+			return ErrorResponse(c, fiber.StatusUnauthorized, "UNAUTHORIZED", "Credentials required")
+		}
+
+		p, err := authenticate(c.Context(), token)
+		if err != nil {
+			return ErrorResponse(c, fiber.StatusInternalServerError, "INTERNAL_ERROR", "Failed to verify credentials")
+		}
+		if p == nil {
+			return ErrorResponse(c, fiber.StatusUnauthorized, "UNAUTHORIZED", "Invalid credentials")
+		}
+
+		c.Locals(principalKey, p)
+		return c.Next()
+	}
+}
+
+// RequireRole returns a middleware that only lets principals with one of
+// the given roles through. Admins are always let through.
+func RequireRole(roles ...string) fiber.Handler {
+	return func(c fiber.Ctx) error {
+		p := CurrentPrincipal(c)
+		if p == nil || (p.Role != model.RoleAdmin && !slices.Contains(roles, p.Role)) {
+			return ErrorResponse(c, fiber.StatusForbidden, "FORBIDDEN", "Insufficient role")
+		}
+		return c.Next()
+	}
+}
+
+// CurrentPrincipal returns the caller authenticated by NewAuth, or nil
+// outside of an authenticated route.
+func CurrentPrincipal(c fiber.Ctx) *model.Principal {
+	p, _ := c.Locals(principalKey).(*model.Principal)
+	return p
+}
+
+// bearerToken extracts the token of a "Bearer <token>" Authorization header.
+func bearerToken(header string) (string, bool) {
+	scheme, token, ok := strings.Cut(strings.TrimSpace(header), " ")
+	if !ok || !strings.EqualFold(scheme, "Bearer") {
+		return "", false
+	}
+	token = strings.TrimSpace(token)
+	if token == "" || len(token) > MaxTokenLen {
+		return "", false
+	}
+	return token, true
+}
@@ -0,0 +1,64 @@
+package middleware
+
+import (
+	"context"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gofiber/fiber/v3"
+
+	"github.com/mathieu-neron/RealTube/realtube-go/internal/model"
+)
+
+func TestAuth(t *testing.T) {
+	principals := map[string]*model.Principal{
+		"mod-token":   {Role: model.RoleModerator, UserID: "mod"},
+		"admin-token": {Role: model.RoleAdmin, KeyID: 1},
+		"ops-token":   {Role: model.RoleReadOnlyOps, KeyID: 2},
+	}
+
+	app := fiber.New()
+	app.Use(NewAuth(func(_ context.Context, token string) (*model.Principal, error) {
+		return principals[token], nil
+	}))
+	app.Get("/mod", RequireRole(model.RoleModerator), func(c fiber.Ctx) error {
+		return c.SendString(CurrentPrincipal(c).Role)
+	})
+	app.Get("/ops", RequireRole(model.RoleReadOnlyOps), func(c fiber.Ctx) error {
+		return c.SendString(CurrentPrincipal(c).Role)
+	})
+
+	tests := []struct {
+		name   string
+		path   string
+		header string
+		want   int
+	}{
+		{"moderator on moderator route", "/mod", "Bearer mod-token", fiber.StatusOK},
+		{"case-insensitive scheme", "/mod", "bearer mod-token", fiber.StatusOK},
+		{"admin on moderator route", "/mod", "Bearer admin-token", fiber.StatusOK},
+		{"admin on ops route", "/ops", "Bearer admin-token", fiber.StatusOK},
+		{"ops on ops route", "/ops", "Bearer ops-token", fiber.StatusOK},
+		{"ops on moderator route", "/mod", "Bearer ops-token", fiber.StatusForbidden},
+		{"moderator on ops route", "/ops", "Bearer mod-token", fiber.StatusForbidden},
+		{"unknown token", "/mod", "Bearer someone-else", fiber.StatusUnauthorized},
+		{"missing header", "/mod", "", fiber.StatusUnauthorized},
+		{"wrong scheme", "/mod", "Basic mod-token", fiber.StatusUnauthorized},
+		{"empty token", "/mod", "Bearer ", fiber.StatusUnauthorized},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(fiber.MethodGet, tt.path, nil)
+			if tt.header != "" {
+				req.Header.Set(fiber.HeaderAuthorization, tt.header)
+			}
+			resp, err := app.Test(req)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if resp.StatusCode != tt.want {
+				t.Errorf("status = %d, want %d", resp.StatusCode, tt.want)
+			}
+		})
+	}
+}
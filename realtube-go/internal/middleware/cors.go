@@ -71,6 +71,7 @@ func corsMethods() []string {
 	return []string{
 		fiber.MethodGet,
 		fiber.MethodPost,
+		fiber.MethodPut,
 		fiber.MethodDelete,
 		fiber.MethodOptions,
 	}
@@ -82,6 +83,7 @@ func corsHeaders() []string {
 		"Content-Type",
 		"Accept",
 		"X-User-ID",
+		"Authorization",
 	}
 }
 
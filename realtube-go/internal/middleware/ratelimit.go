@@ -1,8 +1,8 @@
 package middleware
 
 import (
+	"context"
 	"fmt"
-	"sync"
 	"time"
 
 	"github.com/gofiber/fiber/v3"
@@ -10,64 +10,38 @@ import (
 
 // RateLimitConfig defines the limit for a specific route or group.
 type RateLimitConfig struct {
-	Max    int           // Maximum requests allowed in the window
-	Window time.Duration // Time window for the limit
+	Name   string                   // Namespaces the keys of this limit in a shared store
+	Max    int                      // Maximum requests allowed in the window
+	Window time.Duration            // Time window for the limit
 	KeyFn  func(c fiber.Ctx) string // Returns the key to rate limit on (IP, userID, etc.)
+	Store  RateLimitStore           // Where the buckets are kept; in memory when nil
 }
 
-// entry tracks request count and window start for a single key.
-type entry struct {
-	count     int
-	windowEnd time.Time
-}
-
-// RateLimiter is an in-memory sliding-window rate limiter.
+// RateLimiter is a token-bucket rate limiter: each key may burst Max
+// requests, refilled at Max per Window.
 type RateLimiter struct {
-	mu      sync.Mutex
-	entries map[string]*entry
-	config  RateLimitConfig
+	config RateLimitConfig
+	store  RateLimitStore
 }
 
 // NewRateLimiter creates a rate limiter with the given config.
 func NewRateLimiter(cfg RateLimitConfig) *RateLimiter {
-	rl := &RateLimiter{
-		entries: make(map[string]*entry),
-		config:  cfg,
+	store := cfg.Store
+	if store == nil {
+		store = NewMemoryRateLimitStore()
 	}
-	// Background cleanup every 5 minutes
-	go rl.cleanup()
-	return rl
+	return &RateLimiter{config: cfg, store: store}
 }
 
 // Handler returns a Fiber middleware handler that enforces the rate limit.
 func (rl *RateLimiter) Handler() fiber.Handler {
 	return func(c fiber.Ctx) error {
-		key := rl.config.KeyFn(c)
-
-		rl.mu.Lock()
-		now := time.Now()
-		e, exists := rl.entries[key]
-		if !exists || now.After(e.windowEnd) {
-			// New window
-			rl.entries[key] = &entry{
-				count:     1,
-				windowEnd: now.Add(rl.config.Window),
-			}
-			e = rl.entries[key]
-			rl.mu.Unlock()
-
-			setRateLimitHeaders(c, rl.config.Max, rl.config.Max-1, e.windowEnd)
-			return c.Next()
-		}
-
-		e.count++
-		remaining := rl.config.Max - e.count
-		rl.mu.Unlock()
+		res := rl.take(c.Context(), rl.config.KeyFn(c))
 
-		setRateLimitHeaders(c, rl.config.Max, max(remaining, 0), e.windowEnd)
+		setRateLimitHeaders(c, rl.config.Max, res.Remaining, res.ResetAt)
 
-		if remaining < 0 {
-			retryAfter := int(time.Until(e.windowEnd).Seconds()) + 1
+		if !res.Allowed {
+			retryAfter := int(res.RetryAfter.Seconds()) + 1
 			return c.Status(fiber.StatusTooManyRequests).JSON(fiber.Map{
 				"error": fiber.Map{
 					"code":       "RATE_LIMITED",
@@ -83,21 +57,20 @@ func (rl *RateLimiter) Handler() fiber.Handler {
 
 // Allow checks if a request with the given key is allowed (for testing).
 func (rl *RateLimiter) Allow(key string) bool {
-	rl.mu.Lock()
-	defer rl.mu.Unlock()
-
-	now := time.Now()
-	e, exists := rl.entries[key]
-	if !exists || now.After(e.windowEnd) {
-		rl.entries[key] = &entry{
-			count:     1,
-			windowEnd: now.Add(rl.config.Window),
-		}
-		return true
-	}
+	return rl.take(context.Background(), key).Allowed
+}
 
-	e.count++
-	return e.count <= rl.config.Max
+// take spends a token for key. Requests are let through if the store fails.
+func (rl *RateLimiter) take(ctx context.Context, key string) RateLimitResult {
+	if rl.config.Name != "" {
+		key = rl.config.Name + ":" + key
+	}
+	res, err := rl.store.Take(ctx, key, rl.config.Max, rl.config.Window)
+	if err != nil {
+		Logger.Error().Err(err).Str("limit", rl.config.Name).Msg("rate limit store error")
+		return RateLimitResult{Allowed: true, Remaining: rl.config.Max, ResetAt: time.Now()}
+	}
+	return res
 }
 
 func setRateLimitHeaders(c fiber.Ctx, limit, remaining int, resetAt time.Time) {
@@ -106,20 +79,6 @@ func setRateLimitHeaders(c fiber.Ctx, limit, remaining int, resetAt time.Time) {
 	c.Set("X-RateLimit-Reset", fmt.Sprintf("%d", resetAt.Unix()))
 }
 
-func (rl *RateLimiter) cleanup() {
-	ticker := time.NewTicker(5 * time.Minute)
-	for range ticker.C {
-		rl.mu.Lock()
-		now := time.Now()
-		for key, e := range rl.entries {
-			if now.After(e.windowEnd) {
-				delete(rl.entries, key)
-			}
-		}
-		rl.mu.Unlock()
-	}
-}
-
 // KeyByIP returns the client IP as the rate limit key.
 func KeyByIP(c fiber.Ctx) string {
 	return "ip:" + c.IP()
@@ -135,57 +94,92 @@ func KeyByUserID(c fiber.Ctx) string {
 }
 
 // --- Pre-configured rate limiters matching the API contract ---
+// A nil store keeps the limiter's buckets in memory.
 
 // NewVideoRateLimiter: 100 req/min per IP
-func NewVideoRateLimiter() *RateLimiter {
+func NewVideoRateLimiter(store RateLimitStore) *RateLimiter {
 	return NewRateLimiter(RateLimitConfig{
+		Name:   "video",
 		Max:    100,
 		Window: time.Minute,
 		KeyFn:  KeyByIP,
+		Store:  store,
 	})
 }
 
 // NewVoteSubmitRateLimiter: 10 req/min per user
-func NewVoteSubmitRateLimiter() *RateLimiter {
+func NewVoteSubmitRateLimiter(store RateLimitStore) *RateLimiter {
 	return NewRateLimiter(RateLimitConfig{
+		Name:   "vote-submit",
 		Max:    10,
 		Window: time.Minute,
 		KeyFn:  KeyByUserID,
+		Store:  store,
 	})
 }
 
 // NewVoteDeleteRateLimiter: 5 req/min per user
-func NewVoteDeleteRateLimiter() *RateLimiter {
+func NewVoteDeleteRateLimiter(store RateLimitStore) *RateLimiter {
 	return NewRateLimiter(RateLimitConfig{
+		Name:   "vote-delete",
 		Max:    5,
 		Window: time.Minute,
 		KeyFn:  KeyByUserID,
+		Store:  store,
 	})
 }
 
 // NewSyncRateLimiter: 2 req/min per user
-func NewSyncRateLimiter() *RateLimiter {
+func NewSyncRateLimiter(store RateLimitStore) *RateLimiter {
 	return NewRateLimiter(RateLimitConfig{
+		Name:   "sync",
 		Max:    2,
 		Window: time.Minute,
 		KeyFn:  KeyByUserID,
+		Store:  store,
 	})
 }
 
 // NewStatsRateLimiter: 10 req/min per IP
-func NewStatsRateLimiter() *RateLimiter {
+func NewStatsRateLimiter(store RateLimitStore) *RateLimiter {
 	return NewRateLimiter(RateLimitConfig{
+		Name:   "stats",
 		Max:    10,
 		Window: time.Minute,
 		KeyFn:  KeyByIP,
+		Store:  store,
 	})
 }
 
 // NewExportRateLimiter: 1 req/hour per IP
-func NewExportRateLimiter() *RateLimiter {
+func NewExportRateLimiter(store RateLimitStore) *RateLimiter {
 	return NewRateLimiter(RateLimitConfig{
+		Name:   "export",
 		Max:    1,
 		Window: time.Hour,
 		KeyFn:  KeyByIP,
+		Store:  store,
+	})
+}
+
+// NewAppealRateLimiter: 5 req/hour per IP
+func NewAppealRateLimiter(store RateLimitStore) *RateLimiter {
+	return NewRateLimiter(RateLimitConfig{
+		Name:   "appeal",
+		Max:    5,
+		Window: time.Hour,
+		KeyFn:  KeyByIP,
+		Store:  store,
+	})
+}
+
+// NewChallengeRateLimiter: 30 req/min per IP
+func NewChallengeRateLimiter(store RateLimitStore) *RateLimiter {
+	return NewRateLimiter(RateLimitConfig{
+		Name:   "challenge",
+		Max:    30,
+		Window: time.Minute,
+		KeyFn:  KeyByIP,
+		Store:  store,
 	})
 }
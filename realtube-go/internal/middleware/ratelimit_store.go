@@ -0,0 +1,253 @@
+package middleware
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// RateLimitStore keeps the token buckets of the rate limiters. A bucket
+// holds up to max tokens and refills at max tokens per window, so a key can
+// burst max requests and then sustains max requests per window.
+type RateLimitStore interface {
+	// Take spends a token from the bucket of key, creating it full.
+	Take(ctx context.Context, key string, max int, window time.Duration) (RateLimitResult, error)
+}
+
+// RateLimitResult is the state of a bucket after a Take.
+type RateLimitResult struct {
+	Allowed    bool
+	Remaining  int           // Whole tokens left in the bucket
+	RetryAfter time.Duration // Until the next token, when not allowed
+	ResetAt    time.Time     // When the bucket is full again
+}
+
+// NewRateLimitStore returns a store shared by every replica through Redis,
+// or a per-process store when rdb is nil.
+func NewRateLimitStore(rdb *redis.Client) RateLimitStore {
+	if rdb == nil {
+		return NewMemoryRateLimitStore()
+	}
+	return NewRedisRateLimitStore(rdb)
+}
+
+// bucketResult computes the result of a Take that left tokens in the
+// bucket at now.
+func bucketResult(allowed bool, tokens float64, max int, window time.Duration, now time.Time) RateLimitResult {
+	perToken := window / time.Duration(max)
+	res := RateLimitResult{
+		Allowed:   allowed,
+		Remaining: int(math.Floor(tokens)),
+		ResetAt:   now.Add(time.Duration((float64(max) - tokens) * float64(perToken))),
+	}
+	if !allowed {
+		res.RetryAfter = time.Duration((1 - tokens) * float64(perToken))
+	}
+	return res
+}
+
+// --- In-memory store ---
+
+// bucket is the token count of a key at its last Take.
+type bucket struct {
+	tokens float64
+	last   time.Time
+	full   time.Time // When the bucket is full again, for cleanup
+}
+
+// MemoryRateLimitStore keeps the buckets in a per-process map. Limits are
+// per replica and reset on restart.
+type MemoryRateLimitStore struct {
+	mu      sync.Mutex
+	buckets map[string]*bucket
+}
+
+// NewMemoryRateLimitStore creates an in-memory store.
+func NewMemoryRateLimitStore() *MemoryRateLimitStore {
+	s := &MemoryRateLimitStore{buckets: make(map[string]*bucket)}
+	// Background cleanup every 5 minutes
+	go s.cleanup()
+	return s
+}
+
+// Take spends a token from the bucket of key.
+func (s *MemoryRateLimitStore) Take(_ context.Context, key string, max int, window time.Duration) (RateLimitResult, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := time.Now()
+	b, exists := s.buckets[key]
+	if !exists {
+		b = &bucket{tokens: float64(max), last: now}
+		s.buckets[key] = b
+	}
+
+	// Refill for the time since the last Take
+	rate := float64(max) / float64(window)
+	b.tokens = math.Min(float64(max), b.tokens+float64(now.Sub(b.last))*rate)
+	b.last = now
+
+	allowed := b.tokens >= 1
+	if allowed {
+		b.tokens--
+	}
+	res := bucketResult(allowed, b.tokens, max, window, now)
+	b.full = res.ResetAt
+	return res, nil
+}
+
+func (s *MemoryRateLimitStore) cleanup() {
+	ticker := time.NewTicker(5 * time.Minute)
+	for range ticker.C {
+		s.mu.Lock()
+		now := time.Now()
+		for key, b := range s.buckets {
+			if now.After(b.full) {
+				delete(s.buckets, key)
+			}
+		}
+		s.mu.Unlock()
+	}
+}
+
+// --- Redis store ---
+
+// takeScript refills and spends a token from a bucket atomically, using the
+// Redis clock so replicas with skewed clocks share one timeline. Buckets
+// expire once they would be full again. Returns whether the token was
+// spent, the tokens left and the Redis time in milliseconds.
+var takeScript = redis.NewScript(`
+local max = tonumber(ARGV[1])
+local window = tonumber(ARGV[2])
+local t = redis.call('TIME')
+local now = tonumber(t[1]) * 1000 + math.floor(tonumber(t[2]) / 1000)
+
+local state = redis.call('HMGET', KEYS[1], 'tokens', 'ts')
+local tokens = tonumber(state[1])
+local ts = tonumber(state[2])
+if tokens == nil or ts == nil then
+	tokens = max
+	ts = now
+end
+
+tokens = math.min(max, tokens + math.max(0, now - ts) * max / window)
+local allowed = 0
+if tokens >= 1 then
+	tokens = tokens - 1
+	allowed = 1
+end
+
+redis.call('HSET', KEYS[1], 'tokens', tostring(tokens), 'ts', now)
+redis.call('PEXPIRE', KEYS[1], math.ceil((max - tokens) * window / max) + 1)
+return {allowed, tostring(tokens), now}
+`)
+
+// Back-off of the Redis store after a failure: Redis is skipped for
+// redisBackoffMin, doubling on each failed retry up to redisBackoffMax.
+// Failures are logged at most once per redisWarnEvery.
+const (
+	redisBackoffMin = time.Second
+	redisBackoffMax = 30 * time.Second
+	redisWarnEvery  = time.Minute
+)
+
+// RedisRateLimitStore keeps the buckets in Redis, shared by every replica
+// and kept across restarts. When Redis fails, it falls back to a
+// per-process store so limits stay enforced per replica, and backs off:
+// requests go straight to the fallback until the next retry, so an outage
+// doesn't add a Redis timeout to every request.
+type RedisRateLimitStore struct {
+	rdb      *redis.Client
+	fallback *MemoryRateLimitStore
+
+	mu         sync.Mutex
+	backoff    time.Duration // 0 while Redis is healthy
+	retryAt    time.Time     // Redis is skipped until then
+	lastWarn   time.Time
+	suppressed int // failures not logged since lastWarn
+}
+
+// NewRedisRateLimitStore creates a store backed by rdb.
+func NewRedisRateLimitStore(rdb *redis.Client) *RedisRateLimitStore {
+	return &RedisRateLimitStore{rdb: rdb, fallback: NewMemoryRateLimitStore()}
+}
+
+// Take spends a token from the bucket of key.
+func (s *RedisRateLimitStore) Take(ctx context.Context, key string, max int, window time.Duration) (RateLimitResult, error) {
+	if !s.available(time.Now()) {
+		return s.fallback.Take(ctx, key, max, window)
+	}
+
+	res, err := s.take(ctx, key, max, window)
+	if err != nil {
+		s.fail(err, time.Now())
+		return s.fallback.Take(ctx, key, max, window)
+	}
+	s.succeed()
+	return res, nil
+}
+
+// take runs takeScript on the bucket of key.
+func (s *RedisRateLimitStore) take(ctx context.Context, key string, max int, window time.Duration) (RateLimitResult, error) {
+	vals, err := takeScript.Run(ctx, s.rdb, []string{"ratelimit:" + key}, max, window.Milliseconds()).Slice()
+	if err != nil {
+		return RateLimitResult{}, err
+	}
+	if len(vals) != 3 {
+		return RateLimitResult{}, fmt.Errorf("unexpected rate limit script result %v", vals)
+	}
+
+	allowed, _ := vals[0].(int64)
+	tokensStr, _ := vals[1].(string)
+	tokens, err := strconv.ParseFloat(tokensStr, 64)
+	if err != nil {
+		return RateLimitResult{}, fmt.Errorf("invalid rate limit bucket: %w", err)
+	}
+	nowMs, _ := vals[2].(int64)
+	return bucketResult(allowed == 1, tokens, max, window, time.UnixMilli(nowMs)), nil
+}
+
+// available reports whether Redis may be tried at now.
+func (s *RedisRateLimitStore) available(now time.Time) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return !now.Before(s.retryAt)
+}
+
+// fail backs off after a Redis failure at now, and logs it unless a
+// failure was logged in the last redisWarnEvery.
+func (s *RedisRateLimitStore) fail(err error, now time.Time) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.backoff = min(max(2*s.backoff, redisBackoffMin), redisBackoffMax)
+	s.retryAt = now.Add(s.backoff)
+
+	if now.Sub(s.lastWarn) < redisWarnEvery {
+		s.suppressed++
+		return
+	}
+	Logger.Warn().Err(err).Int("suppressed", s.suppressed).Dur("retry_in", s.backoff).
+		Msg("rate limit store unavailable, using in-memory limits")
+	s.lastWarn = now
+	s.suppressed = 0
+}
+
+// succeed clears the back-off after Redis answered.
+func (s *RedisRateLimitStore) succeed() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.backoff == 0 {
+		return
+	}
+	Logger.Info().Msg("rate limit store available again")
+	s.backoff = 0
+	s.retryAt = time.Time{}
+	s.suppressed = 0
+}
@@ -1,8 +1,12 @@
 package middleware
 
 import (
+	"context"
 	"testing"
 	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/redis/go-redis/v9"
 )
 
 func TestRateLimiter_AllowsUpToMax(t *testing.T) {
@@ -79,7 +83,7 @@ func TestRateLimiter_WindowResets(t *testing.T) {
 }
 
 func TestRateLimiter_VoteSubmitConfig(t *testing.T) {
-	rl := NewVoteSubmitRateLimiter()
+	rl := NewVoteSubmitRateLimiter(nil)
 	// Should allow up to 10
 	for i := 0; i < 10; i++ {
 		if !rl.Allow("user:abc123") {
@@ -92,7 +96,7 @@ func TestRateLimiter_VoteSubmitConfig(t *testing.T) {
 }
 
 func TestRateLimiter_VoteDeleteConfig(t *testing.T) {
-	rl := NewVoteDeleteRateLimiter()
+	rl := NewVoteDeleteRateLimiter(nil)
 	for i := 0; i < 5; i++ {
 		if !rl.Allow("user:abc123") {
 			t.Fatalf("vote delete request %d should be allowed (max 5)", i+1)
@@ -104,7 +108,7 @@ func TestRateLimiter_VoteDeleteConfig(t *testing.T) {
 }
 
 func TestRateLimiter_SyncConfig(t *testing.T) {
-	rl := NewSyncRateLimiter()
+	rl := NewSyncRateLimiter(nil)
 	for i := 0; i < 2; i++ {
 		if !rl.Allow("user:abc123") {
 			t.Fatalf("sync request %d should be allowed (max 2)", i+1)
@@ -116,7 +120,7 @@ func TestRateLimiter_SyncConfig(t *testing.T) {
 }
 
 func TestRateLimiter_StatsConfig(t *testing.T) {
-	rl := NewStatsRateLimiter()
+	rl := NewStatsRateLimiter(nil)
 	for i := 0; i < 10; i++ {
 		if !rl.Allow("ip:127.0.0.1") {
 			t.Fatalf("stats request %d should be allowed (max 10)", i+1)
@@ -128,7 +132,7 @@ func TestRateLimiter_StatsConfig(t *testing.T) {
 }
 
 func TestRateLimiter_ExportConfig(t *testing.T) {
-	rl := NewExportRateLimiter()
+	rl := NewExportRateLimiter(nil)
 	if !rl.Allow("ip:127.0.0.1") {
 		t.Fatal("1st export request should be allowed")
 	}
@@ -136,3 +140,199 @@ func TestRateLimiter_ExportConfig(t *testing.T) {
 		t.Fatal("2nd export request should be blocked (max 1/hour)")
 	}
 }
+
+func TestRateLimiter_AppealConfig(t *testing.T) {
+	rl := NewAppealRateLimiter(nil)
+	for i := 0; i < 5; i++ {
+		if !rl.Allow("ip:127.0.0.1") {
+			t.Fatalf("appeal %d should be allowed (max 5)", i+1)
+		}
+	}
+	if rl.Allow("ip:127.0.0.1") {
+		t.Fatal("6th appeal should be blocked (max 5/hour)")
+	}
+}
+
+func TestRateLimiter_RefillsOneTokenAtATime(t *testing.T) {
+	rl := NewRateLimiter(RateLimitConfig{
+		Max:    2,
+		Window: 100 * time.Millisecond,
+		KeyFn:  KeyByIP,
+	})
+
+	rl.Allow("test")
+	rl.Allow("test")
+
+	// One token refills every 50ms
+	time.Sleep(60 * time.Millisecond)
+
+	if !rl.Allow("test") {
+		t.Fatal("should be allowed after one token refilled")
+	}
+	if rl.Allow("test") {
+		t.Fatal("should be blocked until the next token refills")
+	}
+}
+
+func TestRateLimiter_SharedStoreNamespacesLimits(t *testing.T) {
+	store := NewMemoryRateLimitStore()
+	syncRL := NewSyncRateLimiter(store)
+	statsRL := NewStatsRateLimiter(store)
+
+	syncRL.Allow("ip:127.0.0.1")
+	syncRL.Allow("ip:127.0.0.1")
+	if syncRL.Allow("ip:127.0.0.1") {
+		t.Fatal("3rd sync request should be blocked")
+	}
+
+	// The same key on another limit has its own bucket
+	if !statsRL.Allow("ip:127.0.0.1") {
+		t.Fatal("stats request should be allowed (independent limit)")
+	}
+}
+
+func TestMemoryRateLimitStore_Result(t *testing.T) {
+	store := NewMemoryRateLimitStore()
+	ctx := context.Background()
+
+	res, _ := store.Take(ctx, "k", 2, time.Minute)
+	if !res.Allowed || res.Remaining != 1 {
+		t.Fatalf("1st take = %+v, want allowed with 1 remaining", res)
+	}
+	store.Take(ctx, "k", 2, time.Minute)
+
+	res, _ = store.Take(ctx, "k", 2, time.Minute)
+	if res.Allowed || res.Remaining != 0 {
+		t.Fatalf("3rd take = %+v, want blocked with 0 remaining", res)
+	}
+	// A token refills every 30s, the bucket is full in a minute
+	if res.RetryAfter <= 29*time.Second || res.RetryAfter > 30*time.Second {
+		t.Errorf("RetryAfter = %s, want ~30s", res.RetryAfter)
+	}
+	if d := time.Until(res.ResetAt); d <= 59*time.Second || d > time.Minute {
+		t.Errorf("ResetAt in %s, want ~1m", d)
+	}
+}
+
+// newTestRedisStore returns a Redis store backed by a miniredis server with
+// its clock set to now.
+func newTestRedisStore(t *testing.T, now time.Time) (*RedisRateLimitStore, *miniredis.Miniredis) {
+	t.Helper()
+	m := miniredis.RunT(t)
+	m.SetTime(now)
+	rdb := redis.NewClient(&redis.Options{Addr: m.Addr(), MaxRetries: -1})
+	t.Cleanup(func() { rdb.Close() })
+	return NewRedisRateLimitStore(rdb), m
+}
+
+func TestRedisRateLimitStore_BurstAndRefill(t *testing.T) {
+	now := time.Date(2026, 2, 6, 12, 0, 0, 0, time.UTC)
+	store, m := newTestRedisStore(t, now)
+	ctx := context.Background()
+
+	// A full bucket allows a burst of max
+	for i := 2; i >= 0; i-- {
+		res, err := store.Take(ctx, "k", 3, time.Minute)
+		if err != nil || !res.Allowed || res.Remaining != i {
+			t.Fatalf("burst take = %+v, %v, want allowed with %d remaining", res, err, i)
+		}
+	}
+	res, _ := store.Take(ctx, "k", 3, time.Minute)
+	if res.Allowed {
+		t.Fatalf("4th take = %+v, want blocked", res)
+	}
+	// A token refills every 20s, the bucket is full in a minute, on the
+	// Redis clock
+	if res.RetryAfter != 20*time.Second {
+		t.Errorf("RetryAfter = %s, want 20s", res.RetryAfter)
+	}
+	if !res.ResetAt.Equal(now.Add(time.Minute)) {
+		t.Errorf("ResetAt = %s, want %s", res.ResetAt, now.Add(time.Minute))
+	}
+
+	// One token refills at a time
+	m.SetTime(now.Add(20 * time.Second))
+	if res, _ := store.Take(ctx, "k", 3, time.Minute); !res.Allowed || res.Remaining != 0 {
+		t.Fatalf("take after 20s = %+v, want allowed with 0 remaining", res)
+	}
+	if res, _ := store.Take(ctx, "k", 3, time.Minute); res.Allowed {
+		t.Fatalf("2nd take after 20s = %+v, want blocked", res)
+	}
+
+	// The bucket never holds more than max
+	m.SetTime(now.Add(time.Hour))
+	if res, _ := store.Take(ctx, "k", 3, time.Minute); !res.Allowed || res.Remaining != 2 {
+		t.Fatalf("take after an hour = %+v, want allowed with 2 remaining", res)
+	}
+
+	// Other keys have their own bucket
+	if res, _ := store.Take(ctx, "other", 3, time.Minute); !res.Allowed || res.Remaining != 2 {
+		t.Fatalf("other key take = %+v, want allowed with 2 remaining", res)
+	}
+}
+
+func TestRedisRateLimitStore_BucketExpires(t *testing.T) {
+	store, m := newTestRedisStore(t, time.Now())
+	ctx := context.Background()
+
+	store.Take(ctx, "k", 2, time.Minute)
+	// Half empty, the bucket is full again in 30s
+	if ttl := m.TTL("ratelimit:k"); ttl <= 29*time.Second || ttl > 31*time.Second {
+		t.Errorf("TTL = %s, want ~30s", ttl)
+	}
+	m.FastForward(31 * time.Second)
+	if m.Exists("ratelimit:k") {
+		t.Error("full bucket should have expired")
+	}
+}
+
+func TestRedisRateLimitStore_BacksOffWhileRedisIsDown(t *testing.T) {
+	store, m := newTestRedisStore(t, time.Now())
+	ctx := context.Background()
+
+	m.Close()
+	// Limits are still enforced by the fallback store
+	store.Take(ctx, "k", 1, time.Minute)
+	if res, err := store.Take(ctx, "k", 1, time.Minute); err != nil || res.Allowed {
+		t.Fatalf("2nd take with Redis down = %+v, %v, want blocked", res, err)
+	}
+
+	// Redis is skipped until the back-off ends, even once it is back
+	if err := m.Restart(); err != nil {
+		t.Fatal(err)
+	}
+	calls := m.TotalConnectionCount()
+	store.Take(ctx, "other", 1, time.Minute)
+	if m.TotalConnectionCount() != calls || m.Exists("ratelimit:other") {
+		t.Fatal("Redis should be skipped during the back-off")
+	}
+
+	store.mu.Lock()
+	store.retryAt = time.Now()
+	store.mu.Unlock()
+	store.Take(ctx, "other", 1, time.Minute)
+	if !m.Exists("ratelimit:other") {
+		t.Fatal("Redis should be used again after the back-off")
+	}
+	if store.backoff != 0 {
+		t.Errorf("backoff = %s after a success, want 0", store.backoff)
+	}
+}
+
+func TestRedisRateLimitStore_BackoffDoubles(t *testing.T) {
+	store := &RedisRateLimitStore{}
+	now := time.Now()
+
+	want := []time.Duration{time.Second, 2 * time.Second, 4 * time.Second, 8 * time.Second,
+		16 * time.Second, 30 * time.Second, 30 * time.Second}
+	for i, d := range want {
+		store.fail(context.DeadlineExceeded, now)
+		if store.backoff != d || !store.retryAt.Equal(now.Add(d)) {
+			t.Fatalf("failure %d: backoff = %s, want %s", i+1, store.backoff, d)
+		}
+	}
+	// Only the first failure of the minute is logged
+	if store.suppressed != len(want)-1 {
+		t.Errorf("suppressed = %d, want %d", store.suppressed, len(want)-1)
+	}
+}
@@ -3,6 +3,8 @@ package middleware
 import (
 	"regexp"
 	"strings"
+	"unicode"
+	"unicode/utf8"
 
 	"github.com/gofiber/fiber/v3"
 )
@@ -16,6 +18,16 @@ const (
 	MaxCategoryLen  = 20  // video_categories.category VARCHAR(20)
 	MinHashPrefix   = 4
 	MaxHashPrefix   = 8
+
+	MaxTitleLen         = 100       // YouTube title limit, in characters
+	MaxVideoDurationSec = 12 * 3600 // YouTube upload limit
+	MaxShortDurationSec = 180       // Shorts are at most 3 minutes
+
+	MaxReasonLen  = 500 // moderation reason, in characters
+	MaxKeyNameLen = 64  // admin_keys.name VARCHAR(64)
+
+	MaxChallengeLen = 128 // proof-of-work challenge as issued
+	MaxSolutionLen  = 64  // proof-of-work solution
 )
 
 var (
@@ -104,3 +116,108 @@ func ValidateUserAgent(ua string) string {
 	}
 	return ua
 }
+
+// ValidateTitle trims a video title and checks that it is valid UTF-8
+// without control characters and within YouTube's length limit.
+func ValidateTitle(title string) (string, string) {
+	title = strings.TrimSpace(title)
+	if title == "" {
+		return "", "title must not be blank"
+	}
+	if !utf8.ValidString(title) {
+		return "", "title must be valid UTF-8"
+	}
+	if utf8.RuneCountInString(title) > MaxTitleLen {
+		return "", "title must be at most 100 characters"
+	}
+	if strings.IndexFunc(title, unicode.IsControl) >= 0 {
+		return "", "title contains invalid characters"
+	}
+	return title, ""
+}
+
+// ValidateVideoDuration checks that a video duration in seconds is positive
+// and within YouTube's upload limit.
+func ValidateVideoDuration(seconds float64) string {
+	if !(seconds > 0) || seconds > MaxVideoDurationSec {
+		return "videoDuration must be between 0 and 43200 seconds"
+	}
+	return ""
+}
+
+// ValidateIsShort checks that a video reported as a Short is not longer than
+// a Short can be. duration may be nil if it was not reported.
+func ValidateIsShort(isShort bool, duration *float64) string {
+	if isShort && duration != nil && *duration > MaxShortDurationSec {
+		return "isShort requires videoDuration of at most 180 seconds"
+	}
+	return ""
+}
+
+// ValidateReason trims a moderation reason and checks that it is present,
+// valid UTF-8 and within MaxReasonLen characters. Newlines are allowed.
+func ValidateReason(reason string) (string, string) {
+	reason = strings.TrimSpace(reason)
+	if reason == "" {
+		return "", "reason is required"
+	}
+	if !utf8.ValidString(reason) {
+		return "", "reason must be valid UTF-8"
+	}
+	if utf8.RuneCountInString(reason) > MaxReasonLen {
+		return "", "reason must be at most 500 characters"
+	}
+	if strings.IndexFunc(reason, func(r rune) bool { return unicode.IsControl(r) && r != '\n' }) >= 0 {
+		return "", "reason contains invalid characters"
+	}
+	return reason, ""
+}
+
+// ValidateKeyName validates and trims the name of an admin API key.
+func ValidateKeyName(name string) (string, string) {
+	name = strings.TrimSpace(name)
+	if name == "" {
+		return "", "name is required"
+	}
+	if !utf8.ValidString(name) {
+		return "", "name must be valid UTF-8"
+	}
+	if utf8.RuneCountInString(name) > MaxKeyNameLen {
+		return "", "name must be at most 64 characters"
+	}
+	if strings.IndexFunc(name, unicode.IsControl) >= 0 {
+		return "", "name contains invalid characters"
+	}
+	return name, ""
+}
+
+// ValidateChallenge checks the optional proof-of-work challenge and
+// solution of a vote: both or neither must be present, within length
+// limits and made of printable ASCII.
+func ValidateChallenge(challenge, solution string) string {
+	if challenge == "" && solution == "" {
+		return ""
+	}
+	if challenge == "" || solution == "" {
+		return "challenge and solution must be sent together"
+	}
+	if len(challenge) > MaxChallengeLen {
+		return "challenge must be at most 128 characters"
+	}
+	if len(solution) > MaxSolutionLen {
+		return "solution must be at most 64 characters"
+	}
+	unprintable := func(r rune) bool { return r < '!' || r > '~' }
+	if strings.IndexFunc(challenge, unprintable) >= 0 || strings.IndexFunc(solution, unprintable) >= 0 {
+		return "challenge and solution must be printable ASCII"
+	}
+	return ""
+}
+
+// ValidateScore checks that a score is within 0-100.
+func ValidateScore(score float64) string {
+	if !(score >= 0 && score <= 100) {
+		return "score must be between 0 and 100"
+	}
+	return ""
+}
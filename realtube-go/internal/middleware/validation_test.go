@@ -1,6 +1,10 @@
 package middleware
 
-import "testing"
+import (
+	"math"
+	"strings"
+	"testing"
+)
 
 func TestValidateVideoID(t *testing.T) {
 	tests := []struct {
@@ -137,3 +141,195 @@ func TestValidateUserAgent(t *testing.T) {
 		t.Errorf("truncation failed: got len %d, want %d", len(got), MaxUserAgentLen)
 	}
 }
+
+func TestValidateTitle(t *testing.T) {
+	tests := []struct {
+		name    string
+		input   string
+		want    string
+		wantErr bool
+	}{
+		{"valid", "Never Gonna Give You Up", "Never Gonna Give You Up", false},
+		{"trims whitespace", "  title  ", "title", false},
+		{"unicode", "Café au lait ☕", "Café au lait ☕", false},
+		{"blank", "   ", "", true},
+		{"exactly 100 chars", strings.Repeat("é", 100), strings.Repeat("é", 100), false},
+		{"too long", strings.Repeat("a", 101), "", true},
+		{"control chars", "bad\x00title", "", true},
+		{"invalid utf8", "bad\xfftitle", "", true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, errMsg := ValidateTitle(tt.input)
+			if tt.wantErr && errMsg == "" {
+				t.Errorf("expected error, got none")
+			}
+			if !tt.wantErr && errMsg != "" {
+				t.Errorf("unexpected error: %s", errMsg)
+			}
+			if got != tt.want {
+				t.Errorf("got %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestValidateVideoDuration(t *testing.T) {
+	tests := []struct {
+		name    string
+		input   float64
+		wantErr bool
+	}{
+		{"typical", 212.5, false},
+		{"upload limit", 43200, false},
+		{"zero", 0, true},
+		{"negative", -1, true},
+		{"too long", 43201, true},
+		{"NaN", math.NaN(), true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			errMsg := ValidateVideoDuration(tt.input)
+			if tt.wantErr && errMsg == "" {
+				t.Errorf("expected error, got none")
+			}
+			if !tt.wantErr && errMsg != "" {
+				t.Errorf("unexpected error: %s", errMsg)
+			}
+		})
+	}
+}
+
+func TestValidateIsShort(t *testing.T) {
+	short, long := 45.0, 600.0
+	tests := []struct {
+		name     string
+		isShort  bool
+		duration *float64
+		wantErr  bool
+	}{
+		{"short with short duration", true, &short, false},
+		{"short without duration", true, nil, false},
+		{"short with long duration", true, &long, true},
+		{"not short with long duration", false, &long, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			errMsg := ValidateIsShort(tt.isShort, tt.duration)
+			if tt.wantErr && errMsg == "" {
+				t.Errorf("expected error, got none")
+			}
+			if !tt.wantErr && errMsg != "" {
+				t.Errorf("unexpected error: %s", errMsg)
+			}
+		})
+	}
+}
+
+func TestValidateReason(t *testing.T) {
+	tests := []struct {
+		name    string
+		input   string
+		want    string
+		wantErr bool
+	}{
+		{"valid", "Confirmed human creator", "Confirmed human creator", false},
+		{"trims whitespace", "  spam  ", "spam", false},
+		{"multi-line", "line one\nline two", "line one\nline two", false},
+		{"blank", "  ", "", true},
+		{"exactly 500 chars", strings.Repeat("é", 500), strings.Repeat("é", 500), false},
+		{"too long", strings.Repeat("a", 501), "", true},
+		{"control chars", "bad\x1breason", "", true},
+		{"invalid utf8", "bad\xffreason", "", true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, errMsg := ValidateReason(tt.input)
+			if tt.wantErr && errMsg == "" {
+				t.Errorf("expected error, got none")
+			}
+			if !tt.wantErr && errMsg != "" {
+				t.Errorf("unexpected error: %s", errMsg)
+			}
+			if got != tt.want {
+				t.Errorf("got %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestValidateKeyName(t *testing.T) {
+	tests := []struct {
+		name    string
+		input   string
+		want    string
+		wantErr bool
+	}{
+		{"valid", "grafana", "grafana", false},
+		{"trims whitespace", "  ops dashboard ", "ops dashboard", false},
+		{"blank", " ", "", true},
+		{"exactly 64 chars", strings.Repeat("é", 64), strings.Repeat("é", 64), false},
+		{"too long", strings.Repeat("a", 65), "", true},
+		{"control chars", "bad\nname", "", true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, errMsg := ValidateKeyName(tt.input)
+			if tt.wantErr && errMsg == "" {
+				t.Errorf("expected error, got none")
+			}
+			if !tt.wantErr && errMsg != "" {
+				t.Errorf("unexpected error: %s", errMsg)
+			}
+			if got != tt.want {
+				t.Errorf("got %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestValidateChallenge(t *testing.T) {
+	tests := []struct {
+		name      string
+		challenge string
+		solution  string
+		wantErr   bool
+	}{
+		{"neither", "", "", false},
+		{"both", "18.1760000000.abcd.ef01", "12345", false},
+		{"challenge only", "18.1760000000.abcd.ef01", "", true},
+		{"solution only", "", "12345", true},
+		{"challenge too long", strings.Repeat("a", 129), "1", true},
+		{"solution too long", "a", strings.Repeat("1", 65), true},
+		{"space in solution", "a", "12 34", true},
+		{"non-ASCII solution", "a", "é", true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			errMsg := ValidateChallenge(tt.challenge, tt.solution)
+			if (errMsg != "") != tt.wantErr {
+				t.Errorf("error = %q, wantErr %v", errMsg, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestValidateScore(t *testing.T) {
+	tests := []struct {
+		score   float64
+		wantErr bool
+	}{
+		{0, false},
+		{60, false},
+		{100, false},
+		{-0.1, true},
+		{100.1, true},
+		{math.NaN(), true},
+	}
+	for _, tt := range tests {
+		errMsg := ValidateScore(tt.score)
+		if (errMsg != "") != tt.wantErr {
+			t.Errorf("ValidateScore(%v) error = %q, wantErr %v", tt.score, errMsg, tt.wantErr)
+		}
+	}
+}
@@ -0,0 +1,59 @@
+package model
+
+import "time"
+
+// Roles of authenticated callers of the privileged endpoints, stored in
+// admin_keys.role. VIPs authenticated with their private user ID act as
+// moderators.
+const (
+	RoleModerator   = "moderator"
+	RoleAdmin       = "admin"
+	RoleReadOnlyOps = "read-only-ops"
+)
+
+// ValidRoles is the set of roles an admin key can have.
+var ValidRoles = map[string]bool{
+	RoleModerator:   true,
+	RoleAdmin:       true,
+	RoleReadOnlyOps: true,
+}
+
+// Principal is the authenticated caller of a privileged endpoint: either a
+// VIP user (UserID set) or an admin API key (KeyID set).
+type Principal struct {
+	Role   string
+	UserID string
+	KeyID  int64
+}
+
+// AdminKey is an admin API key. The key itself is never stored; KeyPrefix is
+// kept so that operators can recognise a key in listings.
+type AdminKey struct {
+	ID         int64      `json:"id"`
+	Name       string     `json:"name"`
+	KeyPrefix  string     `json:"keyPrefix"`
+	Role       string     `json:"role"`
+	CreatedAt  time.Time  `json:"createdAt"`
+	LastUsedAt *time.Time `json:"lastUsedAt"`
+	RevokedAt  *time.Time `json:"revokedAt"`
+}
+
+// CreateAdminKeyRequest is the request body for POST /api/admin/keys.
+type CreateAdminKeyRequest struct {
+	Name string `json:"name"`
+	Role string `json:"role"`
+}
+
+// CreateAdminKeyResponse returns a new key. This is the only time the key
+// is shown.
+type CreateAdminKeyResponse struct {
+	Key      string   `json:"key"`
+	AdminKey AdminKey `json:"adminKey"`
+}
+
+// QueueStats is the backlog of a background work queue.
+type QueueStats struct {
+	Name             string  `json:"name"`
+	Depth            int     `json:"depth"`
+	OldestAgeSeconds float64 `json:"oldestAgeSeconds"`
+}
@@ -0,0 +1,83 @@
+package model
+
+import "time"
+
+// Appeal statuses. Open and under-review appeals are pending; upheld and
+// rejected are final.
+const (
+	AppealOpen        = "open"
+	AppealUnderReview = "under_review"
+	AppealUpheld      = "upheld"
+	AppealRejected    = "rejected"
+)
+
+// AppealStatuses is the set of appeal statuses.
+var AppealStatuses = map[string]bool{
+	AppealOpen:        true,
+	AppealUnderReview: true,
+	AppealUpheld:      true,
+	AppealRejected:    true,
+}
+
+// Appeal is a dispute of a video or channel flag. The submitter, reason and
+// reviewer are only shown to moderators.
+type Appeal struct {
+	ID             int64      `json:"id"`
+	TargetType     string     `json:"targetType"`
+	TargetID       string     `json:"targetId"`
+	UserID         string     `json:"userId,omitempty"`
+	Reason         string     `json:"reason,omitempty"`
+	Status         string     `json:"status"`
+	ReviewerUserID string     `json:"reviewerUserId,omitempty"`
+	ReviewerKeyID  int64      `json:"reviewerKeyId,omitempty"`
+	Resolution     *string    `json:"resolution,omitempty"`
+	ActionID       *int64     `json:"actionId,omitempty"`
+	CreatedAt      time.Time  `json:"createdAt"`
+	UpdatedAt      time.Time  `json:"updatedAt"`
+	ResolvedAt     *time.Time `json:"resolvedAt,omitempty"`
+}
+
+// Public returns the appeal without the fields only moderators see.
+func (a Appeal) Public() Appeal {
+	a.UserID = ""
+	a.Reason = ""
+	a.ReviewerUserID = ""
+	a.ReviewerKeyID = 0
+	return a
+}
+
+// AppealRequest is the API request body for submitting an appeal.
+type AppealRequest struct {
+	TargetType string `json:"targetType"`
+	TargetID   string `json:"targetId"`
+	UserID     string `json:"userId"`
+	Reason     string `json:"reason"`
+}
+
+// AppealResolveRequest is the API request body for resolving an appeal.
+// Action optionally applies a moderation action with the resolution as its
+// reason: lock or unlock, or override_score or override_category for
+// videos, with the same Score and Category fields as the moderation
+// endpoints.
+type AppealResolveRequest struct {
+	Status     string   `json:"status"`
+	Resolution string   `json:"resolution"`
+	Action     string   `json:"action,omitempty"`
+	Score      *float64 `json:"score,omitempty"`
+	Category   string   `json:"category,omitempty"`
+}
+
+// AppealQueueFilter selects appeals for the moderator queue, oldest first.
+type AppealQueueFilter struct {
+	Statuses   []string
+	TargetType string
+	After      int64 // only appeals with a higher ID
+	Limit      int
+}
+
+// AppealQueueResponse is a page of the moderator appeal queue. NextCursor is
+// passed as "after" to fetch the next page, and is omitted on the last one.
+type AppealQueueResponse struct {
+	Appeals    []Appeal `json:"appeals"`
+	NextCursor int64    `json:"nextCursor,omitempty"`
+}
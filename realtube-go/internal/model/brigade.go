@@ -0,0 +1,108 @@
+package model
+
+import "time"
+
+// Brigade statuses. Frozen brigades hold their videos' scores at the
+// pre-spike value; released brigades were thawed by a moderator and expired
+// ones by time.
+const (
+	BrigadeFrozen   = "frozen"
+	BrigadeReleased = "released"
+	BrigadeExpired  = "expired"
+)
+
+// BrigadeStatuses is the set of brigade statuses.
+var BrigadeStatuses = map[string]bool{
+	BrigadeFrozen:   true,
+	BrigadeReleased: true,
+	BrigadeExpired:  true,
+}
+
+// Brigade detection reasons: the votes in the window outpaced the baseline,
+// or came mostly from new accounts.
+const (
+	BrigadeVelocity    = "velocity"
+	BrigadeNewAccounts = "new_accounts"
+)
+
+// Brigade is a vote spike on a video, or on a channel's videos. FrozenScore
+// is a video's score counting only the votes cast before the detection
+// window, or a channel's score when the spike was detected. ExpiresAt is
+// omitted while a moderator holds the freeze.
+type Brigade struct {
+	ID              int64      `json:"id"`
+	TargetType      string     `json:"targetType"`
+	TargetID        string     `json:"targetId"`
+	Reason          string     `json:"reason"`
+	WindowVotes     int        `json:"windowVotes"`
+	BaselineVotes   int        `json:"baselineVotes"`
+	NewAccountVotes int        `json:"newAccountVotes"`
+	FrozenScore     float64    `json:"frozenScore"`
+	Status          string     `json:"status"`
+	ReviewerUserID  string     `json:"reviewerUserId,omitempty"`
+	ReviewerKeyID   int64      `json:"reviewerKeyId,omitempty"`
+	DetectedAt      time.Time  `json:"detectedAt"`
+	ExpiresAt       *time.Time `json:"expiresAt,omitempty"`
+	ReviewedAt      *time.Time `json:"reviewedAt,omitempty"`
+}
+
+// BrigadeStats counts the votes on a video or channel: WindowVotes in the
+// detection window, NewAccountVotes among them cast by new accounts, and
+// BaselineVotes in the baseline period before the window. Score is the
+// target's current score, replaced for a spiking video by its pre-spike
+// score. CountedAt is the database time of the count, the detection time of
+// a brigade found from it.
+type BrigadeStats struct {
+	TargetType      string
+	TargetID        string
+	WindowVotes     int
+	BaselineVotes   int
+	NewAccountVotes int
+	Score           float64
+	CountedAt       time.Time
+}
+
+// FrozenVideo is a video frozen by a brigade detected at DetectedAt.
+type FrozenVideo struct {
+	VideoID    string
+	DetectedAt time.Time
+}
+
+// BrigadeDetection holds the brigade thresholds. A video, or a channel's
+// videos together, is a brigade target when it got at least MinVideoVotes
+// (MinChannelVotes) votes in the last Window and either VelocityFactor
+// times its average rate over the Baseline before the window, or a share of
+// at least NewAccountShare from accounts first seen within NewAccountAge.
+// Detection only runs if Enabled; freezes last FreezeFor.
+type BrigadeDetection struct {
+	Enabled         bool
+	Window          time.Duration
+	Baseline        time.Duration
+	MinVideoVotes   int
+	MinChannelVotes int
+	VelocityFactor  float64
+	NewAccountAge   time.Duration
+	NewAccountShare float64
+	FreezeFor       time.Duration
+}
+
+// BrigadeFilter selects brigades for the moderator queue, oldest first.
+type BrigadeFilter struct {
+	Status string
+	After  int64 // only brigades with a higher ID
+	Limit  int
+}
+
+// BrigadeResponse is a page of brigades. NextCursor is passed as "after" to
+// fetch the next page, and is omitted on the last one.
+type BrigadeResponse struct {
+	Brigades   []Brigade `json:"brigades"`
+	NextCursor int64     `json:"nextCursor,omitempty"`
+}
+
+// BrigadeReviewResponse is the API response for holding or releasing a
+// brigade. VideosRequeued counts the thawed videos queued for rescoring.
+type BrigadeReviewResponse struct {
+	Brigade        Brigade `json:"brigade"`
+	VideosRequeued int     `json:"videosRequeued"`
+}
@@ -0,0 +1,30 @@
+package model
+
+import "time"
+
+// ChallengeResponse is the API response for a proof-of-work challenge. When
+// Required is false the user may vote without one and the other fields are
+// omitted. Otherwise the client must find a solution such that
+// SHA256(challenge + ":" + solution) starts with Difficulty zero bits, and
+// send both with its vote before ExpiresAt.
+type ChallengeResponse struct {
+	Required   bool       `json:"required"`
+	Challenge  string     `json:"challenge,omitempty"`
+	Difficulty int        `json:"difficulty,omitempty"`
+	ExpiresAt  *time.Time `json:"expiresAt,omitempty"`
+}
+
+// ChallengePolicy decides who must solve a challenge and how hard it is.
+// Challenges are only required if Enabled. Users that don't exist yet or
+// whose trust score is below TrustThreshold must solve one. Difficulty
+// starts at BaseDifficulty bits and gains a bit each time the vote rate of
+// the client's IP hash doubles past VelocityStep votes per hour, up to
+// MaxDifficulty. A challenge must be solved within TTL.
+type ChallengePolicy struct {
+	Enabled        bool
+	TrustThreshold float64
+	BaseDifficulty int
+	MaxDifficulty  int
+	VelocityStep   float64
+	TTL            time.Duration
+}
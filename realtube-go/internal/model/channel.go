@@ -12,16 +12,35 @@ type Channel struct {
 	TopCategory  *string   `json:"topCategory,omitempty"`
 	Locked       bool      `json:"locked"`
 	AutoFlagNew  bool      `json:"autoFlagNew"`
+	Disputed     bool      `json:"disputed"`
 	LastUpdated  time.Time `json:"lastUpdated"`
 }
 
-// ChannelResponse is the API response for channel lookups.
+// ChannelCategory represents per-category aggregates over a channel's videos.
+type ChannelCategory struct {
+	ChannelID     string  `json:"channelId"`
+	Category      string  `json:"category"`
+	FlaggedVideos int     `json:"flaggedVideos"`
+	WeightedScore float64 `json:"weightedScore"`
+}
+
+// ChannelResponse is the API response for channel lookups. Disputed marks a
+// channel with a pending appeal.
 type ChannelResponse struct {
-	ChannelID     string   `json:"channelId"`
-	Score         float64  `json:"score"`
-	TotalVideos   int      `json:"totalVideos"`
-	FlaggedVideos int      `json:"flaggedVideos"`
-	TopCategories []string `json:"topCategories"`
-	Locked        bool     `json:"locked"`
-	LastUpdated   string   `json:"lastUpdated"`
+	ChannelID     string                            `json:"channelId"`
+	Score         float64                           `json:"score"`
+	TotalVideos   int                               `json:"totalVideos"`
+	FlaggedVideos int                               `json:"flaggedVideos"`
+	TopCategories []string                          `json:"topCategories"`
+	Categories    map[string]*ChannelCategoryDetail `json:"categories"`
+	Locked        bool                              `json:"locked"`
+	Disputed      bool                              `json:"disputed,omitempty"`
+	LastUpdated   string                            `json:"lastUpdated"`
+}
+
+// ChannelCategoryDetail holds the flagged video count and average weighted
+// score of a single category across a channel's videos.
+type ChannelCategoryDetail struct {
+	FlaggedVideos int     `json:"flaggedVideos"`
+	WeightedScore float64 `json:"weightedScore"`
 }
@@ -0,0 +1,146 @@
+package model
+
+import (
+	"encoding/json"
+	"time"
+)
+
+// VIP action types recorded in vip_actions.action_type.
+const (
+	ActionLock             = "lock"
+	ActionUnlock           = "unlock"
+	ActionHide             = "hide"
+	ActionUnhide           = "unhide"
+	ActionOverrideScore    = "override_score"
+	ActionOverrideCategory = "override_category"
+	ActionShadowban        = "shadowban"
+	ActionUnshadowban      = "unshadowban"
+	ActionGrantVIP         = "vip_grant"
+	ActionRevokeVIP        = "vip_revoke"
+	ActionSybilDampen      = "sybil_dampen"
+	ActionSybilDismiss     = "sybil_dismiss"
+	ActionBrigadeHold      = "brigade_hold"
+	ActionBrigadeRelease   = "brigade_release"
+)
+
+// ActionTypes is the set of VIP action types.
+var ActionTypes = map[string]bool{
+	ActionLock:             true,
+	ActionUnlock:           true,
+	ActionHide:             true,
+	ActionUnhide:           true,
+	ActionOverrideScore:    true,
+	ActionOverrideCategory: true,
+	ActionShadowban:        true,
+	ActionUnshadowban:      true,
+	ActionGrantVIP:         true,
+	ActionRevokeVIP:        true,
+	ActionSybilDampen:      true,
+	ActionSybilDismiss:     true,
+	ActionBrigadeHold:      true,
+	ActionBrigadeRelease:   true,
+}
+
+// Moderation target types recorded in vip_actions.target_type.
+const (
+	TargetVideo        = "video"
+	TargetChannel      = "channel"
+	TargetUser         = "user"
+	TargetSybilCluster = "sybil_cluster"
+)
+
+// TargetTypes is the set of moderation target types.
+var TargetTypes = map[string]bool{
+	TargetVideo:        true,
+	TargetChannel:      true,
+	TargetUser:         true,
+	TargetSybilCluster: true,
+}
+
+// VIPAction is an audit log entry for a moderator action, taken either by a
+// VIP user or with an admin API key.
+type VIPAction struct {
+	ID         int64           `json:"id"`
+	VIPUserID  string          `json:"vipUserId,omitempty"`
+	AdminKeyID int64           `json:"adminKeyId,omitempty"`
+	ActionType string          `json:"actionType"`
+	TargetType string          `json:"targetType"`
+	TargetID   string          `json:"targetId"`
+	Reason     string          `json:"reason"`
+	Details    json.RawMessage `json:"details,omitempty"`
+	CreatedAt  time.Time       `json:"createdAt"`
+}
+
+// ScoreOverride is the details of an override_score or override_category
+// action.
+type ScoreOverride struct {
+	Category      string  `json:"category,omitempty"`
+	Score         float64 `json:"score"`
+	PreviousScore float64 `json:"previousScore"`
+}
+
+// UserStatusDetails is the details of a shadowban, unshadowban, vip_grant
+// or vip_revoke action.
+type UserStatusDetails struct {
+	VideosRequeued int `json:"videosRequeued"`
+}
+
+// SybilReviewDetails is the details of a sybil_dampen or sybil_dismiss
+// action.
+type SybilReviewDetails struct {
+	UserCount      int `json:"userCount"`
+	VideosRequeued int `json:"videosRequeued"`
+}
+
+// BrigadeReviewDetails is the details of a brigade_hold or brigade_release
+// action.
+type BrigadeReviewDetails struct {
+	BrigadeID      int64 `json:"brigadeId"`
+	VideosRequeued int   `json:"videosRequeued"`
+}
+
+// ModerationRequest is the request body shared by the moderation endpoints.
+// Score and Category are only read by the override endpoints.
+type ModerationRequest struct {
+	Reason   string   `json:"reason"`
+	Score    *float64 `json:"score,omitempty"`
+	Category string   `json:"category,omitempty"`
+}
+
+// ModerationResponse is the API response for a moderation action.
+type ModerationResponse struct {
+	Success bool      `json:"success"`
+	Action  VIPAction `json:"action"`
+}
+
+// ModerationLogFilter selects moderation log entries, newest first.
+type ModerationLogFilter struct {
+	TargetType string
+	TargetID   string
+	ActionType string
+	Exclude    []string // action types to leave out
+	Since      *time.Time
+	Until      *time.Time
+	Before     int64 // only entries with a lower ID; 0 starts from the newest
+	Limit      int
+}
+
+// ModerationLogEntry is an entry of the moderation log. Moderator is a
+// pseudonym that is stable per VIP or admin key but does not reveal either.
+type ModerationLogEntry struct {
+	ID         int64           `json:"id"`
+	Moderator  string          `json:"moderator"`
+	ActionType string          `json:"actionType"`
+	TargetType string          `json:"targetType"`
+	TargetID   string          `json:"targetId"`
+	Reason     string          `json:"reason"`
+	Details    json.RawMessage `json:"details,omitempty"`
+	CreatedAt  time.Time       `json:"createdAt"`
+}
+
+// ModerationLogResponse is a page of the moderation log. NextCursor is
+// passed as "before" to fetch the next page, and is omitted on the last one.
+type ModerationLogResponse struct {
+	Entries    []ModerationLogEntry `json:"entries"`
+	NextCursor int64                `json:"nextCursor,omitempty"`
+}
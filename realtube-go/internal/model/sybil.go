@@ -0,0 +1,76 @@
+package model
+
+import "time"
+
+// Sybil cluster statuses. Pending clusters await a moderator; dampened
+// clusters split one voter's weight between their members; dismissed
+// clusters are false positives and are not detected again.
+const (
+	SybilPending   = "pending"
+	SybilDampened  = "dampened"
+	SybilDismissed = "dismissed"
+)
+
+// SybilStatuses is the set of sybil cluster statuses.
+var SybilStatuses = map[string]bool{
+	SybilPending:   true,
+	SybilDampened:  true,
+	SybilDismissed: true,
+}
+
+// SybilCluster is a group of user IDs that voted from the same IP hash on
+// the same videos. Members is only filled in for a single cluster.
+type SybilCluster struct {
+	ID             int64      `json:"id"`
+	IPHash         string     `json:"ipHash"`
+	UserCount      int        `json:"userCount"`
+	SharedVideos   int        `json:"sharedVideos"`
+	Status         string     `json:"status"`
+	Members        []string   `json:"members,omitempty"`
+	ReviewerUserID string     `json:"reviewerUserId,omitempty"`
+	ReviewerKeyID  int64      `json:"reviewerKeyId,omitempty"`
+	DetectedAt     time.Time  `json:"detectedAt"`
+	UpdatedAt      time.Time  `json:"updatedAt"`
+	ReviewedAt     *time.Time `json:"reviewedAt,omitempty"`
+}
+
+// SybilCandidate is a cluster found by a detection run, before it is
+// recorded. SharedVideos counts the videos voted on by at least two of the
+// users from the IP hash.
+type SybilCandidate struct {
+	IPHash       string
+	UserIDs      []string
+	SharedVideos int
+}
+
+// SybilDetection holds the parameters of a detection run: user IDs are
+// clustered when at least MinUsers of them voted from one IP hash in the
+// last Window, each sharing at least MinSharedVideos videos with another.
+type SybilDetection struct {
+	Window          time.Duration
+	MinUsers        int
+	MinSharedVideos int
+}
+
+// SybilClusterFilter selects sybil clusters for the moderator queue, oldest
+// first.
+type SybilClusterFilter struct {
+	Status string
+	After  int64 // only clusters with a higher ID
+	Limit  int
+}
+
+// SybilClusterResponse is a page of sybil clusters. NextCursor is passed as
+// "after" to fetch the next page, and is omitted on the last one.
+type SybilClusterResponse struct {
+	Clusters   []SybilCluster `json:"clusters"`
+	NextCursor int64          `json:"nextCursor,omitempty"`
+}
+
+// SybilReviewResponse is the API response for dampening or dismissing a
+// cluster. VideosRequeued counts the videos queued for rescoring because
+// their votes were re-weighted.
+type SybilReviewResponse struct {
+	Cluster        SybilCluster `json:"cluster"`
+	VideosRequeued int          `json:"videosRequeued"`
+}
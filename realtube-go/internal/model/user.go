@@ -15,6 +15,13 @@ type User struct {
 	IsShadowbanned bool    `json:"-"`
 	BanReason     *string   `json:"-"`
 	Username      *string   `json:"username,omitempty"`
+
+	// Inactivity decay state (see service.TrustService.ActivityFactor)
+	DormancyFactor float64    `json:"-"`
+	ReactivatedAt  *time.Time `json:"-"`
+
+	// Vote weight divisor while in a dampened sybil cluster; 0 or 1 for none
+	SybilSplit int `json:"-"`
 }
 
 // UserResponse is the API response for user info.
@@ -27,6 +34,56 @@ type UserResponse struct {
 	IsVIP        bool    `json:"isVip"`
 }
 
+// TrustBreakdownResponse is the API response explaining how a user's trust
+// score and vote weight are computed (trust-system-design.md §9).
+type TrustBreakdownResponse struct {
+	UserID          string              `json:"userId"`
+	TrustScore      float64             `json:"trustScore"`
+	AgeFactor       TrustAgeFactor      `json:"ageFactor"`
+	AccuracyFactor  TrustAccuracyFactor `json:"accuracyFactor"`
+	VolumeFactor    TrustVolumeFactor   `json:"volumeFactor"`
+	BaseWeight      float64             `json:"baseWeight"`
+	ActivityFactor  float64             `json:"activityFactor"`
+	EffectiveWeight float64             `json:"effectiveWeight"`
+}
+
+// TrustAgeFactor explains the account-age component of the trust score.
+type TrustAgeFactor struct {
+	Value      float64 `json:"value"`
+	Weight     float64 `json:"weight"`
+	AccountAge int     `json:"accountAge"`
+	DaysToMax  int     `json:"daysToMax"`
+}
+
+// TrustAccuracyFactor explains the accuracy component of the trust score.
+// DefaultApplied is true while the user is below the minimum vote count and
+// the default accuracy is used instead of their measured rate.
+type TrustAccuracyFactor struct {
+	Value          float64 `json:"value"`
+	Weight         float64 `json:"weight"`
+	AccuracyRate   float64 `json:"accuracyRate"`
+	DefaultApplied bool    `json:"defaultApplied"`
+	MinVotes       int     `json:"minVotes"`
+	VotesToMinimum int     `json:"votesToMinimum"`
+}
+
+// TrustVolumeFactor explains the vote-volume component of the trust score.
+type TrustVolumeFactor struct {
+	Value      float64 `json:"value"`
+	Weight     float64 `json:"weight"`
+	TotalVotes int     `json:"totalVotes"`
+	VotesToMax int     `json:"votesToMax"`
+}
+
+// TrustHistoryEntry is a single point of a user's trust score time series.
+type TrustHistoryEntry struct {
+	TrustScore     float64   `json:"trustScore"`
+	AccuracyRate   float64   `json:"accuracyRate"`
+	TotalVotes     int       `json:"totalVotes"`
+	ActivityFactor float64   `json:"activityFactor"`
+	RecordedAt     time.Time `json:"recordedAt"`
+}
+
 // StatsResponse is the API response for global statistics.
 type StatsResponse struct {
 	TotalVideos    int            `json:"totalVideos"`
@@ -49,6 +106,7 @@ type SyncVideoEntry struct {
 	VideoID    string                     `json:"videoId"`
 	Score      float64                    `json:"score,omitempty"`
 	Categories map[string]*CategoryDetail `json:"categories,omitempty"`
+	Disputed   bool                       `json:"disputed,omitempty"`
 	Action     string                     `json:"action"`
 }
 
@@ -56,6 +114,7 @@ type SyncVideoEntry struct {
 type SyncChannelEntry struct {
 	ChannelID string  `json:"channelId"`
 	Score     float64 `json:"score,omitempty"`
+	Disputed  bool    `json:"disputed,omitempty"`
 	Action    string  `json:"action"`
 }
 
@@ -14,6 +14,9 @@ type Video struct {
 	ShadowHidden  bool      `json:"-"`
 	VideoDuration *float64  `json:"videoDuration,omitempty"`
 	IsShort       bool      `json:"isShort,omitempty"`
+	Provisional   bool      `json:"provisional,omitempty"`
+	Disputed      bool      `json:"disputed,omitempty"`
+	Frozen        bool      `json:"frozen,omitempty"`
 	FirstReported time.Time `json:"firstReported"`
 	LastUpdated   time.Time `json:"lastUpdated"`
 	Service       string    `json:"service,omitempty"`
@@ -28,6 +31,14 @@ type VideoCategory struct {
 }
 
 // VideoResponse is the API response for video lookups.
+//
+// Provisional marks a score inherited from an auto-flagged channel rather
+// than computed from votes. The provisional entry of a hash-prefix lookup
+// has an empty VideoID: it is keyed by ChannelID and applies to every video
+// of the channel that is not otherwise in the response. Disputed marks a
+// video with a pending appeal.
+// Frozen marks a video whose score is held at its value before a suspected
+// brigade until a moderator reviews it or the freeze expires.
 type VideoResponse struct {
 	VideoID      string                       `json:"videoId"`
 	Score        float64                      `json:"score"`
@@ -36,6 +47,9 @@ type VideoResponse struct {
 	Locked       bool                         `json:"locked"`
 	ChannelID    *string                      `json:"channelId,omitempty"`
 	ChannelScore float64                      `json:"channelScore,omitempty"`
+	Provisional  bool                         `json:"provisional,omitempty"`
+	Disputed     bool                         `json:"disputed,omitempty"`
+	Frozen       bool                         `json:"frozen,omitempty"`
 	LastUpdated  time.Time                    `json:"lastUpdated"`
 }
 
@@ -15,11 +15,35 @@ type Vote struct {
 }
 
 // VoteRequest is the API request body for submitting a vote.
+// ChannelID, Title, VideoDuration and IsShort are optional video metadata
+// read by the extension from the watch page. Challenge and Solution are the
+// proof-of-work challenge required of new and low-trust users, and its
+// solution.
 type VoteRequest struct {
-	VideoID   string `json:"videoId"`
-	Category  string `json:"category"`
-	UserID    string `json:"userId"`
-	UserAgent string `json:"userAgent,omitempty"`
+	VideoID       string   `json:"videoId"`
+	Category      string   `json:"category"`
+	UserID        string   `json:"userId"`
+	UserAgent     string   `json:"userAgent,omitempty"`
+	ChannelID     string   `json:"channelId,omitempty"`
+	Title         string   `json:"title,omitempty"`
+	VideoDuration *float64 `json:"videoDuration,omitempty"`
+	IsShort       *bool    `json:"isShort,omitempty"`
+	Challenge     string   `json:"challenge,omitempty"`
+	Solution      string   `json:"solution,omitempty"`
+}
+
+// VideoMetadata is the video metadata reported with a vote.
+// Nil fields were not reported.
+type VideoMetadata struct {
+	ChannelID     *string
+	Title         *string
+	VideoDuration *float64
+	IsShort       *bool
+}
+
+// Empty reports whether no metadata field was reported.
+func (m VideoMetadata) Empty() bool {
+	return m.ChannelID == nil && m.Title == nil && m.VideoDuration == nil && m.IsShort == nil
 }
 
 // VoteDeleteRequest is the API request body for removing a vote.
@@ -0,0 +1,99 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+
+	"github.com/mathieu-neron/RealTube/realtube-go/internal/model"
+)
+
+type AdminKeyRepo struct {
+	pool *pgxpool.Pool
+}
+
+func NewAdminKeyRepo(pool *pgxpool.Pool) *AdminKeyRepo {
+	return &AdminKeyRepo{pool: pool}
+}
+
+// Create inserts a new key and returns it.
+func (r *AdminKeyRepo) Create(ctx context.Context, name, keyPrefix, keyHash, role string) (*model.AdminKey, error) {
+	k := model.AdminKey{Name: name, KeyPrefix: keyPrefix, Role: role}
+	err := r.pool.QueryRow(ctx, `
+		INSERT INTO admin_keys (name, key_prefix, key_hash, role)
+		VALUES ($1, $2, $3, $4)
+		RETURNING id, created_at`,
+		name, keyPrefix, keyHash, role,
+	).Scan(&k.ID, &k.CreatedAt)
+	if err != nil {
+		return nil, err
+	}
+	return &k, nil
+}
+
+// List returns all keys, revoked ones included, oldest first.
+func (r *AdminKeyRepo) List(ctx context.Context) ([]model.AdminKey, error) {
+	rows, err := r.pool.Query(ctx, `
+		SELECT id, name, key_prefix, role, created_at, last_used_at, revoked_at
+		FROM admin_keys
+		ORDER BY id`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var keys []model.AdminKey
+	for rows.Next() {
+		var k model.AdminKey
+		if err := rows.Scan(&k.ID, &k.Name, &k.KeyPrefix, &k.Role, &k.CreatedAt, &k.LastUsedAt, &k.RevokedAt); err != nil {
+			return nil, err
+		}
+		keys = append(keys, k)
+	}
+	return keys, rows.Err()
+}
+
+// Use looks up an active key by hash and records that it was used. To spare
+// a write on every privileged request, last_used_at is only refreshed once it
+// is a minute old. Returns pgx.ErrNoRows if no active key has that hash.
+func (r *AdminKeyRepo) Use(ctx context.Context, keyHash string) (*model.AdminKey, error) {
+	var k model.AdminKey
+	err := r.pool.QueryRow(ctx, `
+		WITH k AS (
+			SELECT id, name, key_prefix, role, created_at, last_used_at, revoked_at
+			FROM admin_keys
+			WHERE key_hash = $1 AND revoked_at IS NULL
+		), touched AS (
+			UPDATE admin_keys SET last_used_at = NOW()
+			FROM k
+			WHERE admin_keys.id = k.id
+			  AND (admin_keys.last_used_at IS NULL OR admin_keys.last_used_at < NOW() - interval '1 minute')
+			RETURNING admin_keys.last_used_at
+		)
+		SELECT k.id, k.name, k.key_prefix, k.role, k.created_at,
+		       COALESCE((SELECT last_used_at FROM touched), k.last_used_at), k.revoked_at
+		FROM k`,
+		keyHash,
+	).Scan(&k.ID, &k.Name, &k.KeyPrefix, &k.Role, &k.CreatedAt, &k.LastUsedAt, &k.RevokedAt)
+	if err != nil {
+		return nil, err
+	}
+	return &k, nil
+}
+
+// Revoke revokes an active key. Returns pgx.ErrNoRows if there is no active
+// key with that ID.
+func (r *AdminKeyRepo) Revoke(ctx context.Context, id int64) error {
+	tag, err := r.pool.Exec(ctx, `
+		UPDATE admin_keys SET revoked_at = NOW()
+		WHERE id = $1 AND revoked_at IS NULL`,
+		id)
+	if err != nil {
+		return err
+	}
+	if tag.RowsAffected() == 0 {
+		return pgx.ErrNoRows
+	}
+	return nil
+}
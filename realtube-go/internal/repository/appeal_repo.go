@@ -0,0 +1,136 @@
+package repository
+
+import (
+	"context"
+	"errors"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+
+	"github.com/mathieu-neron/RealTube/realtube-go/internal/model"
+)
+
+// ErrAppealPending is returned when submitting an appeal for a target that
+// already has a pending one.
+var ErrAppealPending = errors.New("target already has a pending appeal")
+
+const appealColumns = `id, target_type, target_id, user_id, reason, status,
+		       COALESCE(reviewer_user_id, ''), COALESCE(reviewer_key_id, 0), resolution, action_id,
+		       created_at, updated_at, resolved_at`
+
+type AppealRepo struct {
+	pool *pgxpool.Pool
+}
+
+func NewAppealRepo(pool *pgxpool.Pool) *AppealRepo {
+	return &AppealRepo{pool: pool}
+}
+
+// Begin starts an appeal transaction.
+func (r *AppealRepo) Begin(ctx context.Context) (pgx.Tx, error) {
+	return r.pool.Begin(ctx)
+}
+
+// Create inserts an open appeal. Returns ErrAppealPending if the target
+// already has a pending appeal.
+func (r *AppealRepo) Create(ctx context.Context, tx pgx.Tx, req model.AppealRequest) (*model.Appeal, error) {
+	a, err := scanAppeal(tx.QueryRow(ctx, `
+		INSERT INTO appeals (target_type, target_id, user_id, reason)
+		VALUES ($1, $2, $3, $4)
+		ON CONFLICT (target_type, target_id) WHERE status IN ('open', 'under_review') DO NOTHING
+		RETURNING `+appealColumns,
+		req.TargetType, req.TargetID, req.UserID, req.Reason))
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, ErrAppealPending
+	}
+	return a, err
+}
+
+// SetDisputed sets the disputed marker of the appeal's target. A video's
+// new state is appended to sync_cache; a channel reaches delta syncs through
+// last_updated. Returns pgx.ErrNoRows if the target doesn't exist.
+func (r *AppealRepo) SetDisputed(ctx context.Context, tx pgx.Tx, targetType, targetID string, disputed bool) error {
+	query := `UPDATE channels SET disputed = $2, last_updated = NOW() WHERE channel_id = $1`
+	if targetType == model.TargetVideo {
+		query = `UPDATE videos SET disputed = $2 WHERE video_id = $1`
+	}
+	tag, err := tx.Exec(ctx, query, targetID, disputed)
+	if err != nil {
+		return err
+	}
+	if tag.RowsAffected() == 0 {
+		return pgx.ErrNoRows
+	}
+	if targetType == model.TargetVideo {
+		return recordSyncChange(ctx, tx, targetID)
+	}
+	return nil
+}
+
+// FindByID returns an appeal. Returns pgx.ErrNoRows if it doesn't exist.
+func (r *AppealRepo) FindByID(ctx context.Context, id int64) (*model.Appeal, error) {
+	return scanAppeal(r.pool.QueryRow(ctx, `SELECT `+appealColumns+` FROM appeals WHERE id = $1`, id))
+}
+
+// Review moves an open appeal under review by actor. Returns pgx.ErrNoRows
+// if there is no open appeal with that ID.
+func (r *AppealRepo) Review(ctx context.Context, id int64, actor *model.Principal) (*model.Appeal, error) {
+	return scanAppeal(r.pool.QueryRow(ctx, `
+		UPDATE appeals
+		SET status = 'under_review', reviewer_user_id = NULLIF($2, ''), reviewer_key_id = NULLIF($3::bigint, 0),
+		    updated_at = NOW()
+		WHERE id = $1 AND status = 'open'
+		RETURNING `+appealColumns,
+		id, actor.UserID, actor.KeyID))
+}
+
+// Resolve closes a pending appeal with a final status. actionID is the
+// moderation action taken on resolution, if any. Returns pgx.ErrNoRows if
+// there is no pending appeal with that ID.
+func (r *AppealRepo) Resolve(ctx context.Context, tx pgx.Tx, id int64, status, resolution string,
+	actor *model.Principal, actionID *int64) (*model.Appeal, error) {
+	return scanAppeal(tx.QueryRow(ctx, `
+		UPDATE appeals
+		SET status = $2, resolution = $3, action_id = $4,
+		    reviewer_user_id = NULLIF($5, ''), reviewer_key_id = NULLIF($6::bigint, 0),
+		    updated_at = NOW(), resolved_at = NOW()
+		WHERE id = $1 AND status IN ('open', 'under_review')
+		RETURNING `+appealColumns,
+		id, status, resolution, actionID, actor.UserID, actor.KeyID))
+}
+
+// Queue returns the appeals matching the filter, oldest first.
+func (r *AppealRepo) Queue(ctx context.Context, f model.AppealQueueFilter) ([]model.Appeal, error) {
+	rows, err := r.pool.Query(ctx, `
+		SELECT `+appealColumns+`
+		FROM appeals
+		WHERE status = ANY($1) AND ($2 = '' OR target_type = $2) AND id > $3
+		ORDER BY id
+		LIMIT $4`,
+		f.Statuses, f.TargetType, f.After, f.Limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var appeals []model.Appeal
+	for rows.Next() {
+		a, err := scanAppeal(rows)
+		if err != nil {
+			return nil, err
+		}
+		appeals = append(appeals, *a)
+	}
+	return appeals, rows.Err()
+}
+
+func scanAppeal(row pgx.Row) (*model.Appeal, error) {
+	var a model.Appeal
+	err := row.Scan(&a.ID, &a.TargetType, &a.TargetID, &a.UserID, &a.Reason, &a.Status,
+		&a.ReviewerUserID, &a.ReviewerKeyID, &a.Resolution, &a.ActionID,
+		&a.CreatedAt, &a.UpdatedAt, &a.ResolvedAt)
+	if err != nil {
+		return nil, err
+	}
+	return &a, nil
+}
@@ -0,0 +1,282 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+
+	"github.com/mathieu-neron/RealTube/realtube-go/internal/model"
+)
+
+const brigadeColumns = `id, target_type, target_id, reason, window_votes, baseline_votes, new_account_votes,
+		       frozen_score, status, COALESCE(reviewer_user_id, ''), COALESCE(reviewer_key_id, 0),
+		       detected_at, expires_at, reviewed_at`
+
+// BrigadeRepo stores the vote spikes found by the score worker and freezes
+// the scores of their videos.
+type BrigadeRepo struct {
+	pool *pgxpool.Pool
+}
+
+func NewBrigadeRepo(pool *pgxpool.Pool) *BrigadeRepo {
+	return &BrigadeRepo{pool: pool}
+}
+
+// Begin starts a brigade transaction.
+func (r *BrigadeRepo) Begin(ctx context.Context) (pgx.Tx, error) {
+	return r.pool.Begin(ctx)
+}
+
+// RecordAction inserts the vip_actions audit row of a moderator's decision
+// on a brigade.
+func (r *BrigadeRepo) RecordAction(ctx context.Context, tx pgx.Tx, a *model.VIPAction) error {
+	return recordAction(ctx, tx, a)
+}
+
+// VideoStats returns the vote counts of the unlocked, unfrozen videos of
+// videoIDs that got votes in the detection window.
+func (r *BrigadeRepo) VideoStats(ctx context.Context, tx pgx.Tx, videoIDs []string,
+	d model.BrigadeDetection) ([]model.BrigadeStats, error) {
+	return collectBrigadeStats(tx.Query(ctx, `
+		SELECT 'video', v.video_id,
+		       COUNT(*) FILTER (WHERE vo.created_at > NOW() - $2::interval),
+		       COUNT(*) FILTER (WHERE vo.created_at <= NOW() - $2::interval),
+		       COUNT(*) FILTER (WHERE vo.created_at > NOW() - $2::interval AND u.first_seen > NOW() - $4::interval),
+		       v.score, NOW()
+		FROM videos v
+		JOIN votes vo ON vo.video_id = v.video_id
+		JOIN users u ON u.user_id = vo.user_id
+		WHERE v.video_id = ANY($1) AND NOT v.locked AND NOT v.frozen
+		  AND vo.created_at > NOW() - $2::interval - $3::interval
+		GROUP BY v.video_id
+		HAVING COUNT(*) FILTER (WHERE vo.created_at > NOW() - $2::interval) > 0
+		ORDER BY v.video_id`,
+		videoIDs, d.Window, d.Baseline, d.NewAccountAge))
+}
+
+// ChannelStats returns the vote counts of the videos of each channel of
+// videoIDs, together, for the channels without a frozen brigade that got
+// votes in the detection window.
+func (r *BrigadeRepo) ChannelStats(ctx context.Context, tx pgx.Tx, videoIDs []string,
+	d model.BrigadeDetection) ([]model.BrigadeStats, error) {
+	return collectBrigadeStats(tx.Query(ctx, `
+		SELECT 'channel', v.channel_id,
+		       COUNT(*) FILTER (WHERE vo.created_at > NOW() - $2::interval),
+		       COUNT(*) FILTER (WHERE vo.created_at <= NOW() - $2::interval),
+		       COUNT(*) FILTER (WHERE vo.created_at > NOW() - $2::interval AND u.first_seen > NOW() - $4::interval),
+		       COALESCE((SELECT score FROM channels WHERE channel_id = v.channel_id), 0), NOW()
+		FROM videos v
+		JOIN votes vo ON vo.video_id = v.video_id
+		JOIN users u ON u.user_id = vo.user_id
+		WHERE v.channel_id IN (SELECT channel_id FROM videos WHERE video_id = ANY($1))
+		  AND NOT EXISTS (
+		      SELECT 1 FROM brigades b
+		      WHERE b.target_type = 'channel' AND b.target_id = v.channel_id AND b.status = 'frozen'
+		  )
+		  AND vo.created_at > NOW() - $2::interval - $3::interval
+		GROUP BY v.channel_id
+		HAVING COUNT(*) FILTER (WHERE vo.created_at > NOW() - $2::interval) > 0
+		ORDER BY v.channel_id`,
+		videoIDs, d.Window, d.Baseline, d.NewAccountAge))
+}
+
+func collectBrigadeStats(rows pgx.Rows, err error) ([]model.BrigadeStats, error) {
+	if err != nil {
+		return nil, err
+	}
+	return pgx.CollectRows(rows, func(row pgx.CollectableRow) (model.BrigadeStats, error) {
+		var s model.BrigadeStats
+		err := row.Scan(&s.TargetType, &s.TargetID, &s.WindowVotes, &s.BaselineVotes, &s.NewAccountVotes, &s.Score, &s.CountedAt)
+		return s, err
+	})
+}
+
+// Freeze records a brigade on the target of stats, expiring at expiresAt,
+// and freezes its videos: the video itself, or the unlocked videos of the
+// channel voted on in the detection window. Returns the brigade ID, or 0 if
+// the target already has a frozen brigade, and the videos frozen.
+func (r *BrigadeRepo) Freeze(ctx context.Context, tx pgx.Tx, s model.BrigadeStats, reason string, window time.Duration,
+	expiresAt time.Time) (int64, []model.FrozenVideo, error) {
+	var id int64
+	var detectedAt time.Time
+	err := tx.QueryRow(ctx, `
+		INSERT INTO brigades (target_type, target_id, reason, window_votes, baseline_votes, new_account_votes,
+		                      frozen_score, expires_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+		ON CONFLICT (target_type, target_id) WHERE status = 'frozen' DO NOTHING
+		RETURNING id, detected_at`,
+		s.TargetType, s.TargetID, reason, s.WindowVotes, s.BaselineVotes, s.NewAccountVotes, s.Score,
+		expiresAt).Scan(&id, &detectedAt)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return 0, nil, nil
+	}
+	if err != nil {
+		return 0, nil, err
+	}
+
+	var rows pgx.Rows
+	if s.TargetType == model.TargetVideo {
+		rows, err = tx.Query(ctx, `UPDATE videos SET frozen = TRUE WHERE video_id = $1 RETURNING video_id`, s.TargetID)
+	} else {
+		rows, err = tx.Query(ctx, `
+			UPDATE videos SET frozen = TRUE
+			WHERE channel_id = $1 AND NOT locked AND NOT frozen
+			  AND EXISTS (
+			      SELECT 1 FROM votes
+			      WHERE votes.video_id = videos.video_id AND votes.created_at > NOW() - $2::interval
+			  )
+			RETURNING video_id`,
+			s.TargetID, window)
+	}
+	if err != nil {
+		return 0, nil, err
+	}
+	frozen, err := pgx.CollectRows(rows, func(row pgx.CollectableRow) (model.FrozenVideo, error) {
+		f := model.FrozenVideo{DetectedAt: detectedAt}
+		return f, row.Scan(&f.VideoID)
+	})
+	if err != nil {
+		return 0, nil, err
+	}
+	return id, frozen, nil
+}
+
+// FreezeChannelVideos freezes the unlocked videos of videoIDs whose channel
+// has a frozen brigade, so videos voted on after a channel brigade was
+// detected are held too. Returns the videos frozen, with the detection time
+// of their channel's brigade.
+func (r *BrigadeRepo) FreezeChannelVideos(ctx context.Context, tx pgx.Tx, videoIDs []string) ([]model.FrozenVideo, error) {
+	rows, err := tx.Query(ctx, `
+		UPDATE videos v SET frozen = TRUE
+		FROM brigades b
+		WHERE v.video_id = ANY($1) AND NOT v.locked AND NOT v.frozen
+		  AND b.target_type = 'channel' AND b.target_id = v.channel_id AND b.status = 'frozen'
+		RETURNING v.video_id, b.detected_at`,
+		videoIDs)
+	if err != nil {
+		return nil, err
+	}
+	return pgx.CollectRows(rows, pgx.RowToStructByPos[model.FrozenVideo])
+}
+
+// SetStatus moves a frozen brigade to status on behalf of actor, nil for
+// the expiry job. Returns pgx.ErrNoRows if there is no such frozen brigade.
+func (r *BrigadeRepo) SetStatus(ctx context.Context, tx pgx.Tx, id int64, status string,
+	actor *model.Principal) (*model.Brigade, error) {
+	var userID string
+	var keyID int64
+	if actor != nil {
+		userID, keyID = actor.UserID, actor.KeyID
+	}
+	return scanBrigade(tx.QueryRow(ctx, `
+		UPDATE brigades
+		SET status = $2, reviewer_user_id = NULLIF($3, ''), reviewer_key_id = NULLIF($4::bigint, 0),
+		    reviewed_at = NOW()
+		WHERE id = $1 AND status = 'frozen'
+		RETURNING `+brigadeColumns,
+		id, status, userID, keyID))
+}
+
+// Hold removes the expiry of a frozen brigade on behalf of actor, so it
+// stays frozen until released. Returns pgx.ErrNoRows if there is no such
+// frozen brigade.
+func (r *BrigadeRepo) Hold(ctx context.Context, tx pgx.Tx, id int64, actor *model.Principal) (*model.Brigade, error) {
+	return scanBrigade(tx.QueryRow(ctx, `
+		UPDATE brigades
+		SET expires_at = NULL, reviewer_user_id = NULLIF($2, ''), reviewer_key_id = NULLIF($3::bigint, 0),
+		    reviewed_at = NOW()
+		WHERE id = $1 AND status = 'frozen'
+		RETURNING `+brigadeColumns,
+		id, actor.UserID, actor.KeyID))
+}
+
+// Thaw unfreezes the videos of a brigade that are not covered by another
+// frozen brigade and queues them for rescoring. Returns their IDs.
+func (r *BrigadeRepo) Thaw(ctx context.Context, tx pgx.Tx, b *model.Brigade) ([]string, error) {
+	rows, err := tx.Query(ctx, `
+		UPDATE videos v SET frozen = FALSE
+		WHERE v.frozen
+		  AND (($1 = 'video' AND v.video_id = $2) OR ($1 = 'channel' AND v.channel_id = $2))
+		  AND NOT EXISTS (
+		      SELECT 1 FROM brigades b
+		      WHERE b.status = 'frozen'
+		        AND ((b.target_type = 'video' AND b.target_id = v.video_id)
+		          OR (b.target_type = 'channel' AND b.target_id = v.channel_id))
+		  )
+		RETURNING v.video_id`,
+		b.TargetType, b.TargetID)
+	if err != nil {
+		return nil, err
+	}
+	videoIDs, err := pgx.CollectRows(rows, pgx.RowTo[string])
+	if err != nil || len(videoIDs) == 0 {
+		return videoIDs, err
+	}
+
+	_, err = tx.Exec(ctx, `
+		INSERT INTO score_recalc_queue (video_id)
+		SELECT unnest($1::text[])
+		ON CONFLICT (video_id) DO NOTHING`,
+		videoIDs)
+	if err != nil {
+		return nil, err
+	}
+	return videoIDs, nil
+}
+
+// Expired returns the IDs of the frozen brigades past their expiry.
+func (r *BrigadeRepo) Expired(ctx context.Context) ([]int64, error) {
+	rows, err := r.pool.Query(ctx, `
+		SELECT id FROM brigades
+		WHERE status = 'frozen' AND expires_at <= NOW()
+		ORDER BY id`)
+	if err != nil {
+		return nil, err
+	}
+	return pgx.CollectRows(rows, pgx.RowTo[int64])
+}
+
+// FindByID returns a brigade. Returns pgx.ErrNoRows if it doesn't exist.
+func (r *BrigadeRepo) FindByID(ctx context.Context, id int64) (*model.Brigade, error) {
+	return scanBrigade(r.pool.QueryRow(ctx, `SELECT `+brigadeColumns+` FROM brigades WHERE id = $1`, id))
+}
+
+// List returns the brigades matching the filter, oldest first. An empty
+// status matches every status.
+func (r *BrigadeRepo) List(ctx context.Context, f model.BrigadeFilter) ([]model.Brigade, error) {
+	rows, err := r.pool.Query(ctx, `
+		SELECT `+brigadeColumns+`
+		FROM brigades
+		WHERE ($1 = '' OR status = $1) AND id > $2
+		ORDER BY id
+		LIMIT $3`,
+		f.Status, f.After, f.Limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var brigades []model.Brigade
+	for rows.Next() {
+		b, err := scanBrigade(rows)
+		if err != nil {
+			return nil, err
+		}
+		brigades = append(brigades, *b)
+	}
+	return brigades, rows.Err()
+}
+
+func scanBrigade(row pgx.Row) (*model.Brigade, error) {
+	var b model.Brigade
+	err := row.Scan(&b.ID, &b.TargetType, &b.TargetID, &b.Reason, &b.WindowVotes, &b.BaselineVotes,
+		&b.NewAccountVotes, &b.FrozenScore, &b.Status, &b.ReviewerUserID, &b.ReviewerKeyID,
+		&b.DetectedAt, &b.ExpiresAt, &b.ReviewedAt)
+	if err != nil {
+		return nil, err
+	}
+	return &b, nil
+}
@@ -17,18 +17,18 @@ func NewChannelRepo(pool *pgxpool.Pool) *ChannelRepo {
 	return &ChannelRepo{pool: pool}
 }
 
-// FindByChannelID returns a single channel by its ID.
+// FindByChannelID returns a single channel by its ID, excluding hidden ones.
 func (r *ChannelRepo) FindByChannelID(ctx context.Context, channelID string) (*model.Channel, error) {
 	query := `
 		SELECT channel_id, channel_name, score, total_videos, flagged_videos,
-		       top_category, locked, auto_flag_new, last_updated
+		       top_category, locked, auto_flag_new, disputed, last_updated
 		FROM channels
-		WHERE channel_id = $1`
+		WHERE channel_id = $1 AND NOT hidden`
 
 	var ch model.Channel
 	err := r.pool.QueryRow(ctx, query, channelID).Scan(
 		&ch.ChannelID, &ch.ChannelName, &ch.Score, &ch.TotalVideos, &ch.FlaggedVideos,
-		&ch.TopCategory, &ch.Locked, &ch.AutoFlagNew, &ch.LastUpdated,
+		&ch.TopCategory, &ch.Locked, &ch.AutoFlagNew, &ch.Disputed, &ch.LastUpdated,
 	)
 	if err != nil {
 		return nil, err
@@ -36,16 +36,31 @@ func (r *ChannelRepo) FindByChannelID(ctx context.Context, channelID string) (*m
 	return &ch, nil
 }
 
-// GetTopCategories returns the top category names for a channel's videos,
-// ordered by total weighted_score descending.
-func (r *ChannelRepo) GetTopCategories(ctx context.Context, channelID string) ([]string, error) {
+// FindAutoFlagged returns a channel by its ID if it has auto_flag_new set
+// and is not hidden, or pgx.ErrNoRows otherwise.
+func (r *ChannelRepo) FindAutoFlagged(ctx context.Context, channelID string) (*model.Channel, error) {
+	query := `
+		SELECT channel_id, score, last_updated
+		FROM channels
+		WHERE channel_id = $1 AND auto_flag_new AND NOT hidden`
+
+	var ch model.Channel
+	err := r.pool.QueryRow(ctx, query, channelID).Scan(&ch.ChannelID, &ch.Score, &ch.LastUpdated)
+	if err != nil {
+		return nil, err
+	}
+	ch.AutoFlagNew = true
+	return &ch, nil
+}
+
+// GetCategories returns a channel's per-category aggregates, ordered by
+// weighted_score descending.
+func (r *ChannelRepo) GetCategories(ctx context.Context, channelID string) ([]model.ChannelCategory, error) {
 	query := `
-		SELECT vc.category
-		FROM video_categories vc
-		JOIN videos v ON v.video_id = vc.video_id
-		WHERE v.channel_id = $1
-		GROUP BY vc.category
-		ORDER BY SUM(vc.weighted_score) DESC`
+		SELECT channel_id, category, flagged_videos, weighted_score
+		FROM channel_categories
+		WHERE channel_id = $1
+		ORDER BY weighted_score DESC, category`
 
 	rows, err := r.pool.Query(ctx, query, channelID)
 	if err != nil {
@@ -53,13 +68,13 @@ func (r *ChannelRepo) GetTopCategories(ctx context.Context, channelID string) ([
 	}
 	defer rows.Close()
 
-	var categories []string
+	var categories []model.ChannelCategory
 	for rows.Next() {
-		var cat string
-		if err := rows.Scan(&cat); err != nil {
+		var c model.ChannelCategory
+		if err := rows.Scan(&c.ChannelID, &c.Category, &c.FlaggedVideos, &c.WeightedScore); err != nil {
 			return nil, err
 		}
-		categories = append(categories, cat)
+		categories = append(categories, c)
 	}
 	return categories, rows.Err()
 }
@@ -99,6 +114,22 @@ func (r *ChannelRepo) ComputeChannelScore(ctx context.Context, channelID string)
 	return err
 }
 
+// AutoFlagThresholds are the channel score and flagged video count at which
+// new videos of a channel are auto-flagged (trust-system-design.md §10).
+type AutoFlagThresholds struct {
+	MinScore   float64
+	MinFlagged int
+}
+
+// DefaultAutoFlagThresholds: channel_score >= 80 AND flagged_videos >= 20.
+var DefaultAutoFlagThresholds = AutoFlagThresholds{MinScore: 80, MinFlagged: 20}
+
+// ShouldAutoFlag reports whether an unlocked channel with the given score
+// and flagged video count meets the thresholds.
+func (t AutoFlagThresholds) ShouldAutoFlag(channelScore float64, flagged int) bool {
+	return channelScore >= t.MinScore && flagged >= t.MinFlagged
+}
+
 // ComputeChannelScorePure is a pure-logic helper for unit testing.
 func ComputeChannelScorePure(flagged, tracked int, avgFlaggedScore float64) float64 {
 	if tracked < 3 {
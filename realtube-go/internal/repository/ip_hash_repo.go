@@ -0,0 +1,119 @@
+package repository
+
+import (
+	"context"
+	"errors"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// rawIPPattern matches votes.ip_hash values that are not a HashIP digest,
+// i.e. raw client IPs stored by earlier versions of the vote handler.
+const rawIPPattern = `ip_hash !~ '^[0-9a-f]{64}$'`
+
+// IPHashRepo maintains the ip_hashes table: the vote volume of each salted
+// IP hash over a 24-hour window, and whether it is rate limited.
+type IPHashRepo struct {
+	pool *pgxpool.Pool
+}
+
+func NewIPHashRepo(pool *pgxpool.Pool) *IPHashRepo {
+	return &IPHashRepo{pool: pool}
+}
+
+// RecordVote counts a vote attempt by userID from ipHash and returns whether
+// the IP hash is rate limited. The count restarts, and the flag clears, once
+// the 24-hour window that started with the first vote has passed. An IP hash
+// becomes rate limited when its count goes over limit; a limit of 0 never
+// limits.
+func (r *IPHashRepo) RecordVote(ctx context.Context, ipHash, userID string, limit int) (limited bool, err error) {
+	err = r.pool.QueryRow(ctx, `
+		INSERT INTO ip_hashes AS h (ip_hash, user_id, last_seen, vote_count_24h, window_start, rate_limited)
+		VALUES ($1, $2, NOW(), 1, NOW(), FALSE)
+		ON CONFLICT (ip_hash) DO UPDATE
+		SET user_id = EXCLUDED.user_id,
+		    last_seen = NOW(),
+		    window_start = CASE WHEN h.window_start > NOW() - INTERVAL '24 hours'
+		                        THEN h.window_start ELSE NOW() END,
+		    vote_count_24h = CASE WHEN h.window_start > NOW() - INTERVAL '24 hours'
+		                          THEN h.vote_count_24h + 1 ELSE 1 END,
+		    rate_limited = CASE WHEN h.window_start > NOW() - INTERVAL '24 hours'
+		                        THEN h.rate_limited OR ($3 > 0 AND h.vote_count_24h + 1 > $3)
+		                        ELSE FALSE END
+		RETURNING rate_limited`,
+		ipHash, userID, limit).Scan(&limited)
+	return limited, err
+}
+
+// IsRateLimited reports whether ipHash is rate limited in its current
+// window. Unknown IP hashes are not.
+func (r *IPHashRepo) IsRateLimited(ctx context.Context, ipHash string) (bool, error) {
+	var limited bool
+	err := r.pool.QueryRow(ctx, `
+		SELECT rate_limited FROM ip_hashes
+		WHERE ip_hash = $1 AND window_start > NOW() - INTERVAL '24 hours'`,
+		ipHash).Scan(&limited)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return false, nil
+	}
+	return limited, err
+}
+
+// VoteRate returns the votes per hour counted for ipHash in its current
+// window, averaged over at least an hour. Unknown IP hashes have a rate of 0.
+func (r *IPHashRepo) VoteRate(ctx context.Context, ipHash string) (float64, error) {
+	var rate float64
+	err := r.pool.QueryRow(ctx, `
+		SELECT vote_count_24h / GREATEST(EXTRACT(EPOCH FROM NOW() - window_start)::float8 / 3600, 1)
+		FROM ip_hashes
+		WHERE ip_hash = $1 AND window_start > NOW() - INTERVAL '24 hours'`,
+		ipHash).Scan(&rate)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return 0, nil
+	}
+	return rate, err
+}
+
+// RawVoteIPs returns up to limit distinct raw IPs stored in votes.ip_hash.
+func (r *IPHashRepo) RawVoteIPs(ctx context.Context, limit int) ([]string, error) {
+	rows, err := r.pool.Query(ctx, `
+		SELECT DISTINCT ip_hash FROM votes
+		WHERE `+rawIPPattern+`
+		LIMIT $1`,
+		limit)
+	if err != nil {
+		return nil, err
+	}
+	return pgx.CollectRows(rows, pgx.RowTo[string])
+}
+
+// CountRawVoteIPs returns the number of votes that store a raw IP.
+func (r *IPHashRepo) CountRawVoteIPs(ctx context.Context) (int64, error) {
+	var n int64
+	err := r.pool.QueryRow(ctx, `SELECT COUNT(*) FROM votes WHERE `+rawIPPattern).Scan(&n)
+	return n, err
+}
+
+// ReplaceVoteIPs replaces each raw IP in votes.ip_hash with its hash.
+// hashes maps raw IPs to their hashes. The rewrite doesn't touch the voted
+// category or weight, so no video is queued for rescoring. Returns the
+// number of votes rewritten.
+func (r *IPHashRepo) ReplaceVoteIPs(ctx context.Context, hashes map[string]string) (int64, error) {
+	ips := make([]string, 0, len(hashes))
+	digests := make([]string, 0, len(hashes))
+	for ip, digest := range hashes {
+		ips = append(ips, ip)
+		digests = append(digests, digest)
+	}
+
+	tag, err := r.pool.Exec(ctx, `
+		UPDATE votes v SET ip_hash = m.digest
+		FROM unnest($1::text[], $2::text[]) AS m(ip, digest)
+		WHERE v.ip_hash = m.ip`,
+		ips, digests)
+	if err != nil {
+		return 0, err
+	}
+	return tag.RowsAffected(), nil
+}
@@ -0,0 +1,273 @@
+package repository
+
+import (
+	"context"
+	"strconv"
+	"strings"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+
+	"github.com/mathieu-neron/RealTube/realtube-go/internal/model"
+)
+
+// ModerationRepo applies VIP moderation actions. Its methods run inside a
+// transaction owned by the caller, so that the change, its vip_actions audit
+// row and its sync_cache entry are committed together.
+type ModerationRepo struct {
+	pool *pgxpool.Pool
+}
+
+func NewModerationRepo(pool *pgxpool.Pool) *ModerationRepo {
+	return &ModerationRepo{pool: pool}
+}
+
+// Begin starts a moderation transaction.
+func (r *ModerationRepo) Begin(ctx context.Context) (pgx.Tx, error) {
+	return r.pool.Begin(ctx)
+}
+
+// SetVideoLocked locks or unlocks a video. Returns pgx.ErrNoRows if the
+// video doesn't exist.
+func (r *ModerationRepo) SetVideoLocked(ctx context.Context, tx pgx.Tx, videoID string, locked bool) error {
+	tag, err := tx.Exec(ctx, `
+		UPDATE videos SET locked = $2, last_updated = NOW()
+		WHERE video_id = $1`,
+		videoID, locked)
+	if err != nil {
+		return err
+	}
+	if tag.RowsAffected() == 0 {
+		return pgx.ErrNoRows
+	}
+	return nil
+}
+
+// SetVideoHidden hides or unhides a video. Returns pgx.ErrNoRows if the
+// video doesn't exist.
+func (r *ModerationRepo) SetVideoHidden(ctx context.Context, tx pgx.Tx, videoID string, hidden bool) error {
+	tag, err := tx.Exec(ctx, `
+		UPDATE videos SET hidden = $2, last_updated = NOW()
+		WHERE video_id = $1`,
+		videoID, hidden)
+	if err != nil {
+		return err
+	}
+	if tag.RowsAffected() == 0 {
+		return pgx.ErrNoRows
+	}
+	return nil
+}
+
+// OverrideVideo sets a video's score, creating the video if nobody has voted
+// on it yet, and locks it so that votes no longer change it. If categories
+// is non-nil it replaces the weighted scores of the video's categories:
+// categories not in the map are set to 0. The video's channel is queued for
+// recalculation. Returns the score before the override.
+func (r *ModerationRepo) OverrideVideo(ctx context.Context, tx pgx.Tx, videoID string, score float64,
+	categories map[string]float64) (previous float64, err error) {
+	_, err = tx.Exec(ctx, `
+		INSERT INTO videos (video_id) VALUES ($1)
+		ON CONFLICT (video_id) DO NOTHING`,
+		videoID)
+	if err != nil {
+		return 0, err
+	}
+
+	// Videos first, then categories: the same lock order as SubmitVote.
+	var channelID *string
+	err = tx.QueryRow(ctx, `
+		WITH old AS (
+			SELECT video_id, score FROM videos WHERE video_id = $1 FOR UPDATE
+		)
+		UPDATE videos v
+		SET score = $2, locked = TRUE, provisional = FALSE, last_updated = NOW(),
+		    score_changed_at = CASE WHEN old.score IS DISTINCT FROM $2 THEN NOW() ELSE v.score_changed_at END
+		FROM old
+		WHERE v.video_id = old.video_id
+		RETURNING old.score, v.channel_id`,
+		videoID, score).Scan(&previous, &channelID)
+	if err != nil {
+		return 0, err
+	}
+
+	if categories != nil {
+		names := make([]string, 0, len(categories))
+		scores := make([]float64, 0, len(categories))
+		for name, s := range categories {
+			names = append(names, name)
+			scores = append(scores, s)
+		}
+		_, err = tx.Exec(ctx, `
+			UPDATE video_categories SET weighted_score = 0
+			WHERE video_id = $1 AND NOT (category = ANY($2))`,
+			videoID, names)
+		if err != nil {
+			return 0, err
+		}
+		_, err = tx.Exec(ctx, `
+			INSERT INTO video_categories (video_id, category, weighted_score)
+			SELECT $1, c.category, c.score
+			FROM unnest($2::text[], $3::float8[]) AS c(category, score)
+			ORDER BY c.category
+			ON CONFLICT (video_id, category) DO UPDATE SET weighted_score = EXCLUDED.weighted_score`,
+			videoID, names, scores)
+		if err != nil {
+			return 0, err
+		}
+	}
+
+	if channelID != nil {
+		_, err = tx.Exec(ctx, `
+			INSERT INTO channel_recalc_queue (channel_id) VALUES ($1)
+			ON CONFLICT (channel_id) DO NOTHING`,
+			*channelID)
+		if err != nil {
+			return 0, err
+		}
+	}
+	return previous, nil
+}
+
+// SetChannelLocked locks or unlocks a channel, creating it if needed. A
+// locked channel is never auto-flagged: auto_flag_new is cleared right away
+// and the channel is queued so the channel worker withdraws the provisional
+// scores of its videos.
+func (r *ModerationRepo) SetChannelLocked(ctx context.Context, tx pgx.Tx, channelID string, locked bool) error {
+	_, err := tx.Exec(ctx, `
+		INSERT INTO channels (channel_id, locked) VALUES ($1, $2)
+		ON CONFLICT (channel_id) DO UPDATE
+		SET locked = EXCLUDED.locked,
+		    auto_flag_new = channels.auto_flag_new AND NOT EXCLUDED.locked,
+		    last_updated = NOW()`,
+		channelID, locked)
+	if err != nil {
+		return err
+	}
+
+	_, err = tx.Exec(ctx, `
+		INSERT INTO channel_recalc_queue (channel_id) VALUES ($1)
+		ON CONFLICT (channel_id) DO NOTHING`,
+		channelID)
+	return err
+}
+
+// SetChannelHidden hides or unhides a channel. Returns pgx.ErrNoRows if the
+// channel doesn't exist.
+func (r *ModerationRepo) SetChannelHidden(ctx context.Context, tx pgx.Tx, channelID string, hidden bool) error {
+	tag, err := tx.Exec(ctx, `
+		UPDATE channels SET hidden = $2, last_updated = NOW()
+		WHERE channel_id = $1`,
+		channelID, hidden)
+	if err != nil {
+		return err
+	}
+	if tag.RowsAffected() == 0 {
+		return pgx.ErrNoRows
+	}
+	return nil
+}
+
+// RecordAction inserts a vip_actions audit row and fills in its ID and
+// creation time.
+func (r *ModerationRepo) RecordAction(ctx context.Context, tx pgx.Tx, a *model.VIPAction) error {
+	return recordAction(ctx, tx, a)
+}
+
+func recordAction(ctx context.Context, tx pgx.Tx, a *model.VIPAction) error {
+	var details []byte
+	if len(a.Details) > 0 {
+		details = a.Details
+	}
+	return tx.QueryRow(ctx, `
+		INSERT INTO vip_actions (vip_user_id, admin_key_id, action_type, target_type, target_id, reason, details)
+		VALUES (NULLIF($1, ''), NULLIF($2::bigint, 0), $3, $4, $5, $6, $7)
+		RETURNING id, created_at`,
+		a.VIPUserID, a.AdminKeyID, a.ActionType, a.TargetType, a.TargetID, a.Reason, details,
+	).Scan(&a.ID, &a.CreatedAt)
+}
+
+// RecordSyncChange appends the video's current state to sync_cache, as a
+// "remove" if it is hidden and an "update" otherwise, so that delta syncs
+// pick up the moderation change.
+func (r *ModerationRepo) RecordSyncChange(ctx context.Context, tx pgx.Tx, videoID string) error {
+	return recordSyncChange(ctx, tx, videoID)
+}
+
+func recordSyncChange(ctx context.Context, tx pgx.Tx, videoID string) error {
+	_, err := tx.Exec(ctx, `
+		INSERT INTO sync_cache (video_id, score, categories, channel_id, disputed, action)
+		SELECT v.video_id, v.score,
+		       COALESCE((
+		           SELECT jsonb_object_agg(vc.category, jsonb_build_object(
+		               'votes', vc.vote_count, 'weightedScore', vc.weighted_score))
+		           FROM video_categories vc
+		           WHERE vc.video_id = v.video_id
+		       ), '{}'::jsonb),
+		       v.channel_id, v.disputed,
+		       CASE WHEN v.hidden OR v.shadow_hidden THEN 'remove' ELSE 'update' END
+		FROM videos v
+		WHERE v.video_id = $1`,
+		videoID)
+	return err
+}
+
+// ListActions returns the vip_actions rows matching the filter, newest
+// first.
+func (r *ModerationRepo) ListActions(ctx context.Context, f model.ModerationLogFilter) ([]model.VIPAction, error) {
+	var where []string
+	var args []any
+	arg := func(v any) string {
+		args = append(args, v)
+		return "$" + strconv.Itoa(len(args))
+	}
+
+	if f.TargetType != "" {
+		where = append(where, "target_type = "+arg(f.TargetType))
+	}
+	if f.TargetID != "" {
+		where = append(where, "target_id = "+arg(f.TargetID))
+	}
+	if f.ActionType != "" {
+		where = append(where, "action_type = "+arg(f.ActionType))
+	}
+	if len(f.Exclude) > 0 {
+		where = append(where, "NOT (action_type = ANY("+arg(f.Exclude)+"))")
+	}
+	if f.Since != nil {
+		where = append(where, "created_at >= "+arg(*f.Since))
+	}
+	if f.Until != nil {
+		where = append(where, "created_at < "+arg(*f.Until))
+	}
+	if f.Before > 0 {
+		where = append(where, "id < "+arg(f.Before))
+	}
+
+	query := `
+		SELECT id, COALESCE(vip_user_id, ''), COALESCE(admin_key_id, 0), action_type, target_type, target_id,
+		       COALESCE(reason, ''), details, created_at
+		FROM vip_actions`
+	if len(where) > 0 {
+		query += "\n\t\tWHERE " + strings.Join(where, " AND ")
+	}
+	query += "\n\t\tORDER BY id DESC\n\t\tLIMIT " + arg(f.Limit)
+
+	rows, err := r.pool.Query(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var actions []model.VIPAction
+	for rows.Next() {
+		var a model.VIPAction
+		err := rows.Scan(&a.ID, &a.VIPUserID, &a.AdminKeyID, &a.ActionType, &a.TargetType, &a.TargetID,
+			&a.Reason, &a.Details, &a.CreatedAt)
+		if err != nil {
+			return nil, err
+		}
+		actions = append(actions, a)
+	}
+	return actions, rows.Err()
+}
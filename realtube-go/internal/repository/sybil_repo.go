@@ -0,0 +1,278 @@
+package repository
+
+import (
+	"context"
+	"errors"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+
+	"github.com/mathieu-neron/RealTube/realtube-go/internal/model"
+)
+
+const sybilColumns = `id, ip_hash, user_count, shared_videos, status,
+		       COALESCE(reviewer_user_id, ''), COALESCE(reviewer_key_id, 0), detected_at, updated_at, reviewed_at`
+
+// SybilRepo stores the sybil clusters found among votes.ip_hash and applies
+// their weight split to the members' votes.
+type SybilRepo struct {
+	pool *pgxpool.Pool
+}
+
+func NewSybilRepo(pool *pgxpool.Pool) *SybilRepo {
+	return &SybilRepo{pool: pool}
+}
+
+// Begin starts a sybil cluster transaction.
+func (r *SybilRepo) Begin(ctx context.Context) (pgx.Tx, error) {
+	return r.pool.Begin(ctx)
+}
+
+// RecordAction inserts the vip_actions audit row of a moderator's decision
+// on a cluster.
+func (r *SybilRepo) RecordAction(ctx context.Context, tx pgx.Tx, a *model.VIPAction) error {
+	return recordAction(ctx, tx, a)
+}
+
+// Detect returns the user ID clusters among the votes cast in the detection
+// window: for each IP hash, the users who share at least MinSharedVideos
+// voted videos with another user from that IP hash, if there are at least
+// MinUsers of them.
+func (r *SybilRepo) Detect(ctx context.Context, d model.SybilDetection) ([]model.SybilCandidate, error) {
+	rows, err := r.pool.Query(ctx, `
+		WITH recent AS (
+			SELECT ip_hash, user_id, video_id
+			FROM votes
+			WHERE ip_hash IS NOT NULL AND created_at > NOW() - $1::interval
+		),
+		shared AS (
+			SELECT a.ip_hash, a.user_id AS a, b.user_id AS b, a.video_id
+			FROM recent a
+			JOIN recent b ON b.ip_hash = a.ip_hash AND b.video_id = a.video_id AND b.user_id > a.user_id
+		),
+		pairs AS (
+			SELECT ip_hash, a, b
+			FROM shared
+			GROUP BY ip_hash, a, b
+			HAVING COUNT(*) >= $3
+		),
+		members AS (
+			SELECT ip_hash, a AS user_id FROM pairs
+			UNION
+			SELECT ip_hash, b FROM pairs
+		)
+		SELECT m.ip_hash, array_agg(m.user_id ORDER BY m.user_id),
+		       (SELECT COUNT(DISTINCT s.video_id)
+		        FROM shared s JOIN pairs p USING (ip_hash, a, b)
+		        WHERE s.ip_hash = m.ip_hash)
+		FROM members m
+		GROUP BY m.ip_hash
+		HAVING COUNT(*) >= $2
+		ORDER BY m.ip_hash`,
+		d.Window, d.MinUsers, d.MinSharedVideos)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var candidates []model.SybilCandidate
+	for rows.Next() {
+		var c model.SybilCandidate
+		if err := rows.Scan(&c.IPHash, &c.UserIDs, &c.SharedVideos); err != nil {
+			return nil, err
+		}
+		candidates = append(candidates, c)
+	}
+	return candidates, rows.Err()
+}
+
+// Record stores a detected cluster. If the IP hash already has a pending or
+// dampened cluster, the new members are added to it; otherwise a cluster is
+// created with the given status, unless every member already belongs to a
+// dismissed cluster of the IP hash. Returns the cluster ID, 0 if nothing was
+// recorded, and whether the cluster is new or gained members.
+func (r *SybilRepo) Record(ctx context.Context, tx pgx.Tx, c model.SybilCandidate, status string) (int64, bool, error) {
+	var id int64
+	err := tx.QueryRow(ctx, `
+		SELECT id FROM sybil_clusters
+		WHERE ip_hash = $1 AND status IN ('pending', 'dampened')
+		FOR UPDATE`,
+		c.IPHash).Scan(&id)
+	if err != nil && !errors.Is(err, pgx.ErrNoRows) {
+		return 0, false, err
+	}
+
+	if id == 0 {
+		var dismissed bool
+		err = tx.QueryRow(ctx, `
+			SELECT NOT EXISTS (
+				SELECT unnest($2::text[])
+				EXCEPT
+				SELECT m.user_id
+				FROM sybil_cluster_members m
+				JOIN sybil_clusters sc ON sc.id = m.cluster_id
+				WHERE sc.ip_hash = $1 AND sc.status = 'dismissed'
+			)`,
+			c.IPHash, c.UserIDs).Scan(&dismissed)
+		if err != nil || dismissed {
+			return 0, false, err
+		}
+
+		err = tx.QueryRow(ctx, `
+			INSERT INTO sybil_clusters (ip_hash, user_count, shared_videos, status)
+			VALUES ($1, 0, $2, $3)
+			RETURNING id`,
+			c.IPHash, c.SharedVideos, status).Scan(&id)
+		if err != nil {
+			return 0, false, err
+		}
+	}
+
+	tag, err := tx.Exec(ctx, `
+		INSERT INTO sybil_cluster_members (cluster_id, user_id)
+		SELECT $1, unnest($2::text[])
+		ON CONFLICT DO NOTHING`,
+		id, c.UserIDs)
+	if err != nil {
+		return 0, false, err
+	}
+	if tag.RowsAffected() == 0 {
+		return id, false, nil
+	}
+
+	_, err = tx.Exec(ctx, `
+		UPDATE sybil_clusters
+		SET user_count = (SELECT COUNT(*) FROM sybil_cluster_members WHERE cluster_id = $1),
+		    shared_videos = GREATEST(shared_videos, $2),
+		    updated_at = NOW()
+		WHERE id = $1`,
+		id, c.SharedVideos)
+	if err != nil {
+		return 0, false, err
+	}
+	return id, true, nil
+}
+
+// SetStatus moves a cluster from one of the given statuses to status, on
+// behalf of actor. Returns pgx.ErrNoRows if there is no such cluster in one
+// of those statuses.
+func (r *SybilRepo) SetStatus(ctx context.Context, tx pgx.Tx, id int64, status string, actor *model.Principal,
+	from ...string) (*model.SybilCluster, error) {
+	return scanSybilCluster(tx.QueryRow(ctx, `
+		UPDATE sybil_clusters
+		SET status = $2, reviewer_user_id = NULLIF($3, ''), reviewer_key_id = NULLIF($4::bigint, 0),
+		    updated_at = NOW(), reviewed_at = NOW()
+		WHERE id = $1 AND status = ANY($5)
+		RETURNING `+sybilColumns,
+		id, status, actor.UserID, actor.KeyID, from))
+}
+
+// ApplySplits sets the sybil_split of each member of the cluster to the
+// size of the largest dampened cluster they belong to, 1 if none, and
+// re-weights the votes of the members whose split changed. The vote_changes
+// trigger queues every touched video for rescoring. Returns the IDs of the
+// re-weighted videos.
+func (r *SybilRepo) ApplySplits(ctx context.Context, tx pgx.Tx, clusterID int64, weigher VoteWeigher) ([]string, error) {
+	rows, err := tx.Query(ctx, `
+		UPDATE users u
+		SET sybil_split = d.split
+		FROM (
+			SELECT m.user_id, COALESCE(MAX(sc.user_count), 1) AS split
+			FROM sybil_cluster_members m
+			LEFT JOIN sybil_cluster_members other ON other.user_id = m.user_id
+			LEFT JOIN sybil_clusters sc ON sc.id = other.cluster_id AND sc.status = 'dampened'
+			WHERE m.cluster_id = $1
+			GROUP BY m.user_id
+		) d
+		WHERE u.user_id = d.user_id AND u.sybil_split <> d.split
+		RETURNING u.user_id, u.accuracy_rate, u.total_votes, u.first_seen, u.last_active, u.is_vip,
+		          u.is_shadowbanned, u.dormancy_factor, u.reactivated_at, u.sybil_split`,
+		clusterID)
+	if err != nil {
+		return nil, err
+	}
+	users, err := pgx.CollectRows(rows, func(row pgx.CollectableRow) (model.User, error) {
+		var u model.User
+		err := row.Scan(&u.UserID, &u.AccuracyRate, &u.TotalVotes, &u.FirstSeen, &u.LastActive, &u.IsVIP,
+			&u.IsShadowbanned, &u.DormancyFactor, &u.ReactivatedAt, &u.SybilSplit)
+		return u, err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	seen := make(map[string]bool)
+	var videoIDs []string
+	for i := range users {
+		ids, err := reweightVotes(ctx, tx, &users[i], weigher)
+		if err != nil {
+			return nil, err
+		}
+		for _, id := range ids {
+			if !seen[id] {
+				seen[id] = true
+				videoIDs = append(videoIDs, id)
+			}
+		}
+	}
+	return videoIDs, nil
+}
+
+// FindByID returns a cluster with its members. Returns pgx.ErrNoRows if it
+// doesn't exist.
+func (r *SybilRepo) FindByID(ctx context.Context, id int64) (*model.SybilCluster, error) {
+	c, err := scanSybilCluster(r.pool.QueryRow(ctx, `SELECT `+sybilColumns+` FROM sybil_clusters WHERE id = $1`, id))
+	if err != nil {
+		return nil, err
+	}
+
+	rows, err := r.pool.Query(ctx, `
+		SELECT user_id FROM sybil_cluster_members
+		WHERE cluster_id = $1
+		ORDER BY user_id`,
+		id)
+	if err != nil {
+		return nil, err
+	}
+	c.Members, err = pgx.CollectRows(rows, pgx.RowTo[string])
+	if err != nil {
+		return nil, err
+	}
+	return c, nil
+}
+
+// List returns the clusters matching the filter, oldest first. An empty
+// status matches every status.
+func (r *SybilRepo) List(ctx context.Context, f model.SybilClusterFilter) ([]model.SybilCluster, error) {
+	rows, err := r.pool.Query(ctx, `
+		SELECT `+sybilColumns+`
+		FROM sybil_clusters
+		WHERE ($1 = '' OR status = $1) AND id > $2
+		ORDER BY id
+		LIMIT $3`,
+		f.Status, f.After, f.Limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var clusters []model.SybilCluster
+	for rows.Next() {
+		c, err := scanSybilCluster(rows)
+		if err != nil {
+			return nil, err
+		}
+		clusters = append(clusters, *c)
+	}
+	return clusters, rows.Err()
+}
+
+func scanSybilCluster(row pgx.Row) (*model.SybilCluster, error) {
+	var c model.SybilCluster
+	err := row.Scan(&c.ID, &c.IPHash, &c.UserCount, &c.SharedVideos, &c.Status,
+		&c.ReviewerUserID, &c.ReviewerKeyID, &c.DetectedAt, &c.UpdatedAt, &c.ReviewedAt)
+	if err != nil {
+		return nil, err
+	}
+	return &c, nil
+}
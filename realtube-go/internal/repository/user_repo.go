@@ -3,6 +3,7 @@ package repository
 import (
 	"context"
 
+	"github.com/jackc/pgx/v5"
 	"github.com/jackc/pgx/v5/pgxpool"
 
 	"github.com/mathieu-neron/RealTube/realtube-go/internal/model"
@@ -20,7 +21,8 @@ func NewUserRepo(pool *pgxpool.Pool) *UserRepo {
 func (r *UserRepo) FindByUserID(ctx context.Context, userID string) (*model.User, error) {
 	query := `
 		SELECT user_id, trust_score, accuracy_rate, total_votes, accurate_votes,
-		       first_seen, last_active, is_vip, is_shadowbanned, ban_reason, username
+		       first_seen, last_active, is_vip, is_shadowbanned, ban_reason, username,
+		       dormancy_factor, reactivated_at, sybil_split
 		FROM users
 		WHERE user_id = $1`
 
@@ -28,6 +30,7 @@ func (r *UserRepo) FindByUserID(ctx context.Context, userID string) (*model.User
 	err := r.pool.QueryRow(ctx, query, userID).Scan(
 		&u.UserID, &u.TrustScore, &u.AccuracyRate, &u.TotalVotes, &u.AccurateVotes,
 		&u.FirstSeen, &u.LastActive, &u.IsVIP, &u.IsShadowbanned, &u.BanReason, &u.Username,
+		&u.DormancyFactor, &u.ReactivatedAt, &u.SybilSplit,
 	)
 	if err != nil {
 		return nil, err
@@ -35,6 +38,17 @@ func (r *UserRepo) FindByUserID(ctx context.Context, userID string) (*model.User
 	return &u, nil
 }
 
+// IsVIP reports whether userID belongs to a VIP who is not shadowbanned.
+func (r *UserRepo) IsVIP(ctx context.Context, userID string) (bool, error) {
+	var vip bool
+	err := r.pool.QueryRow(ctx, `
+		SELECT EXISTS (
+			SELECT 1 FROM users
+			WHERE user_id = $1 AND is_vip AND NOT is_shadowbanned
+		)`, userID).Scan(&vip)
+	return vip, err
+}
+
 // CreateIfNotExists inserts a new user with default values if one doesn't already exist.
 func (r *UserRepo) CreateIfNotExists(ctx context.Context, userID string) error {
 	_, err := r.pool.Exec(ctx, `
@@ -43,6 +57,74 @@ func (r *UserRepo) CreateIfNotExists(ctx context.Context, userID string) error {
 	return err
 }
 
+// GetTrustHistory returns the most recent trust history entries for a user,
+// newest first.
+func (r *UserRepo) GetTrustHistory(ctx context.Context, userID string, limit int) ([]model.TrustHistoryEntry, error) {
+	query := `
+		SELECT trust_score, accuracy_rate, total_votes, activity_factor, recorded_at
+		FROM user_trust_history
+		WHERE user_id = $1
+		ORDER BY recorded_at DESC
+		LIMIT $2`
+
+	rows, err := r.pool.Query(ctx, query, userID, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var entries []model.TrustHistoryEntry
+	for rows.Next() {
+		var e model.TrustHistoryEntry
+		if err := rows.Scan(&e.TrustScore, &e.AccuracyRate, &e.TotalVotes, &e.ActivityFactor, &e.RecordedAt); err != nil {
+			return nil, err
+		}
+		entries = append(entries, e)
+	}
+	return entries, rows.Err()
+}
+
+// UpdateStatus sets a user's VIP and shadowban flags within tx and
+// re-weights all of their existing votes to the resulting effective weight.
+// A nil flag is left unchanged; banReason is only written with
+// isShadowbanned. The vote_changes trigger queues every touched video for
+// rescoring. Returns pgx.ErrNoRows if the user doesn't exist, and otherwise
+// the IDs of the videos whose votes were re-weighted.
+func (r *UserRepo) UpdateStatus(ctx context.Context, tx pgx.Tx, userID string, isVIP, isShadowbanned *bool,
+	banReason *string, weigher VoteWeigher) ([]string, error) {
+	var u model.User
+	err := tx.QueryRow(ctx, `
+		UPDATE users
+		SET is_vip = COALESCE($2, is_vip), is_shadowbanned = COALESCE($3, is_shadowbanned),
+		    ban_reason = CASE WHEN $3::boolean IS NULL THEN ban_reason ELSE $4 END
+		WHERE user_id = $1
+		RETURNING user_id, accuracy_rate, total_votes, first_seen, last_active, is_vip, is_shadowbanned,
+		          dormancy_factor, reactivated_at, sybil_split`,
+		userID, isVIP, isShadowbanned, banReason).Scan(
+		&u.UserID, &u.AccuracyRate, &u.TotalVotes, &u.FirstSeen, &u.LastActive, &u.IsVIP, &u.IsShadowbanned,
+		&u.DormancyFactor, &u.ReactivatedAt, &u.SybilSplit,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return reweightVotes(ctx, tx, &u, weigher)
+}
+
+// reweightVotes sets the weight of all of the user's votes to their current
+// effective weight. The vote_changes trigger queues every touched video for
+// rescoring. Returns the IDs of the videos whose votes were re-weighted.
+func reweightVotes(ctx context.Context, tx pgx.Tx, u *model.User, weigher VoteWeigher) ([]string, error) {
+	rows, err := tx.Query(ctx, `
+		UPDATE votes SET trust_weight = $2
+		WHERE user_id = $1 AND trust_weight <> $2
+		RETURNING video_id`,
+		u.UserID, weigher.EffectiveWeight(u))
+	if err != nil {
+		return nil, err
+	}
+	return pgx.CollectRows(rows, pgx.RowTo[string])
+}
+
 // GetStats returns aggregate statistics from all tables.
 func (r *UserRepo) GetStats(ctx context.Context) (*model.StatsResponse, error) {
 	query := `
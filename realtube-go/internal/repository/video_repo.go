@@ -20,7 +20,7 @@ func NewVideoRepo(pool *pgxpool.Pool) *VideoRepo {
 func (r *VideoRepo) FindByHashPrefix(ctx context.Context, prefix string) ([]model.Video, error) {
 	query := `
 		SELECT video_id, channel_id, title, score, total_votes, locked, hidden, shadow_hidden,
-		       video_duration, is_short, first_reported, last_updated, service
+		       video_duration, is_short, provisional, disputed, frozen, first_reported, last_updated, service
 		FROM videos
 		WHERE encode(sha256(video_id::bytea), 'hex') LIKE $1 || '%'
 		  AND hidden = false AND shadow_hidden = false
@@ -38,7 +38,7 @@ func (r *VideoRepo) FindByHashPrefix(ctx context.Context, prefix string) ([]mode
 		err := rows.Scan(
 			&v.VideoID, &v.ChannelID, &v.Title, &v.Score, &v.TotalVotes,
 			&v.Locked, &v.Hidden, &v.ShadowHidden,
-			&v.VideoDuration, &v.IsShort, &v.FirstReported, &v.LastUpdated, &v.Service,
+			&v.VideoDuration, &v.IsShort, &v.Provisional, &v.Disputed, &v.Frozen, &v.FirstReported, &v.LastUpdated, &v.Service,
 		)
 		if err != nil {
 			return nil, err
@@ -52,7 +52,7 @@ func (r *VideoRepo) FindByHashPrefix(ctx context.Context, prefix string) ([]mode
 func (r *VideoRepo) FindByVideoID(ctx context.Context, videoID string) (*model.Video, error) {
 	query := `
 		SELECT video_id, channel_id, title, score, total_votes, locked, hidden, shadow_hidden,
-		       video_duration, is_short, first_reported, last_updated, service
+		       video_duration, is_short, provisional, disputed, frozen, first_reported, last_updated, service
 		FROM videos
 		WHERE video_id = $1
 		  AND hidden = false AND shadow_hidden = false`
@@ -61,7 +61,7 @@ func (r *VideoRepo) FindByVideoID(ctx context.Context, videoID string) (*model.V
 	err := r.pool.QueryRow(ctx, query, videoID).Scan(
 		&v.VideoID, &v.ChannelID, &v.Title, &v.Score, &v.TotalVotes,
 		&v.Locked, &v.Hidden, &v.ShadowHidden,
-		&v.VideoDuration, &v.IsShort, &v.FirstReported, &v.LastUpdated, &v.Service,
+		&v.VideoDuration, &v.IsShort, &v.Provisional, &v.Disputed, &v.Frozen, &v.FirstReported, &v.LastUpdated, &v.Service,
 	)
 	if err != nil {
 		return nil, err
@@ -69,6 +69,13 @@ func (r *VideoRepo) FindByVideoID(ctx context.Context, videoID string) (*model.V
 	return &v, nil
 }
 
+// Exists reports whether a row exists for videoID, including hidden ones.
+func (r *VideoRepo) Exists(ctx context.Context, videoID string) (bool, error) {
+	var exists bool
+	err := r.pool.QueryRow(ctx, `SELECT EXISTS (SELECT 1 FROM videos WHERE video_id = $1)`, videoID).Scan(&exists)
+	return exists, err
+}
+
 // GetCategories returns all category vote aggregates for a given video.
 func (r *VideoRepo) GetCategories(ctx context.Context, videoID string) ([]model.VideoCategory, error) {
 	query := `
@@ -2,9 +2,13 @@ package repository
 
 import (
 	"context"
+	"errors"
+	"time"
 
 	"github.com/jackc/pgx/v5"
 	"github.com/jackc/pgx/v5/pgxpool"
+
+	"github.com/mathieu-neron/RealTube/realtube-go/internal/model"
 )
 
 type VoteRepo struct {
@@ -15,40 +19,94 @@ func NewVoteRepo(pool *pgxpool.Pool) *VoteRepo {
 	return &VoteRepo{pool: pool}
 }
 
-// ValidCategories are the allowed AI category values.
+// CategoryNotAI is the dissent vote: the voter asserts the video is
+// human-made. It counts against every AI category when scoring.
+const CategoryNotAI = "not_ai"
+
+// ValidCategories are the allowed vote category values: the AI categories
+// plus the not_ai dissent category.
 var ValidCategories = map[string]bool{
 	"fully_ai":      true,
 	"ai_voiceover":  true,
 	"ai_visuals":    true,
 	"ai_thumbnails": true,
 	"ai_assisted":   true,
+	CategoryNotAI:   true,
+}
+
+// ErrVideoLocked is returned by SubmitVote for videos locked by a VIP,
+// whose score votes can no longer change.
+var ErrVideoLocked = errors.New("video is locked")
+
+// VoteWeigher computes a user's trust score and the effective weight of
+// their votes (implemented by service.TrustService).
+type VoteWeigher interface {
+	ComputeTrustScore(user *model.User) float64
+	EffectiveWeight(user *model.User) float64
+	RecordActivity(user *model.User, now time.Time)
 }
 
 // SubmitVote inserts or updates a vote using atomic SQL.
-// It ensures the video and user exist, then performs the upsert.
+// It ensures the video and user exist, then performs the upsert with the
+// user's effective weight (trust score × VIP/shadowban base weight), and
+// records any reported video metadata. Votes on locked videos are rejected
+// with ErrVideoLocked.
 // Returns the user's trust score at vote time.
-func (r *VoteRepo) SubmitVote(ctx context.Context, videoID, userID, category, ipHash, userAgent string) (trustWeight float64, err error) {
+func (r *VoteRepo) SubmitVote(ctx context.Context, videoID, userID, category, ipHash, userAgent string,
+	meta model.VideoMetadata, weigher VoteWeigher) (trustScore float64, err error) {
 	tx, err := r.pool.Begin(ctx)
 	if err != nil {
 		return 0, err
 	}
 	defer tx.Rollback(ctx)
 
+	// A vote that slips past a concurrent lock is harmless: rescoring skips
+	// locked videos.
+	var locked bool
+	err = tx.QueryRow(ctx, `SELECT locked FROM videos WHERE video_id = $1`, videoID).Scan(&locked)
+	if err != nil && !errors.Is(err, pgx.ErrNoRows) {
+		return 0, err
+	}
+	if locked {
+		return 0, ErrVideoLocked
+	}
+
 	// Ensure user exists (auto-create with defaults if new)
 	_, err = tx.Exec(ctx, `
 		INSERT INTO users (user_id) VALUES ($1)
-		ON CONFLICT (user_id) DO UPDATE SET last_active = NOW()`,
+		ON CONFLICT (user_id) DO NOTHING`,
 		userID)
 	if err != nil {
 		return 0, err
 	}
 
-	// Get user's trust score
-	err = tx.QueryRow(ctx, `SELECT trust_score FROM users WHERE user_id = $1`, userID).Scan(&trustWeight)
+	// Load the trust inputs (locked, so concurrent votes see the same activity state)
+	var u model.User
+	err = tx.QueryRow(ctx, `
+		SELECT user_id, accuracy_rate, total_votes, first_seen, last_active, is_vip, is_shadowbanned,
+		       dormancy_factor, reactivated_at, sybil_split
+		FROM users WHERE user_id = $1
+		FOR UPDATE`, userID).Scan(
+		&u.UserID, &u.AccuracyRate, &u.TotalVotes, &u.FirstSeen, &u.LastActive, &u.IsVIP, &u.IsShadowbanned,
+		&u.DormancyFactor, &u.ReactivatedAt, &u.SybilSplit,
+	)
+	if err != nil {
+		return 0, err
+	}
+
+	// Mark the user active; a dormant user starts recovering from their decayed weight
+	weigher.RecordActivity(&u, time.Now())
+	_, err = tx.Exec(ctx, `
+		UPDATE users SET last_active = $2, dormancy_factor = $3, reactivated_at = $4
+		WHERE user_id = $1`,
+		userID, u.LastActive, u.DormancyFactor, u.ReactivatedAt)
 	if err != nil {
 		return 0, err
 	}
 
+	trustScore = weigher.ComputeTrustScore(&u)
+	trustWeight := weigher.EffectiveWeight(&u)
+
 	// Ensure video exists (auto-create if first report)
 	_, err = tx.Exec(ctx, `
 		INSERT INTO videos (video_id) VALUES ($1)
@@ -68,12 +126,14 @@ func (r *VoteRepo) SubmitVote(ctx context.Context, videoID, userID, category, ip
 		return 0, err
 	}
 
-	// Insert or update the vote
+	// Insert or update the vote. A changed category is judged again, so the
+	// old category's verdict doesn't stay in the user's accuracy.
 	_, err = tx.Exec(ctx, `
 		INSERT INTO votes (video_id, user_id, category, trust_weight, ip_hash, user_agent)
 		VALUES ($1, $2, $3, $4, $5, $6)
 		ON CONFLICT (video_id, user_id) DO UPDATE
-		SET category = EXCLUDED.category, trust_weight = EXCLUDED.trust_weight, created_at = NOW()`,
+		SET category = EXCLUDED.category, trust_weight = EXCLUDED.trust_weight, created_at = NOW(),
+		    judged_at = CASE WHEN votes.category <> EXCLUDED.category THEN NULL ELSE votes.judged_at END`,
 		videoID, userID, category, trustWeight, ipHash, userAgent)
 	if err != nil {
 		return 0, err
@@ -87,6 +147,13 @@ func (r *VoteRepo) SubmitVote(ctx context.Context, videoID, userID, category, ip
 		if err != nil {
 			return 0, err
 		}
+
+		// Count the vote towards the user's lifetime total (trust volume factor)
+		_, err = tx.Exec(ctx, `
+			UPDATE users SET total_votes = total_votes + 1 WHERE user_id = $1`, userID)
+		if err != nil {
+			return 0, err
+		}
 	} else if existingCategory != category {
 		// Decrement old category count if changing vote
 		_, err = tx.Exec(ctx, `
@@ -98,15 +165,24 @@ func (r *VoteRepo) SubmitVote(ctx context.Context, videoID, userID, category, ip
 		}
 	}
 
-	// Upsert the per-category counter
-	_, err = tx.Exec(ctx, `
-		INSERT INTO video_categories (video_id, category, vote_count)
-		VALUES ($1, $2, 1)
-		ON CONFLICT (video_id, category) DO UPDATE
-		SET vote_count = video_categories.vote_count + 1`,
-		videoID, category)
-	if err != nil {
-		return 0, err
+	// Upsert the per-category counter (re-submitting the same category
+	// only refreshes the vote's weight, it doesn't count twice)
+	if isNewVote || existingCategory != category {
+		_, err = tx.Exec(ctx, `
+			INSERT INTO video_categories (video_id, category, vote_count)
+			VALUES ($1, $2, 1)
+			ON CONFLICT (video_id, category) DO UPDATE
+			SET vote_count = video_categories.vote_count + 1`,
+			videoID, category)
+		if err != nil {
+			return 0, err
+		}
+	}
+
+	if !meta.Empty() {
+		if err := recordMetadata(ctx, tx, videoID, userID, meta); err != nil {
+			return 0, err
+		}
 	}
 
 	// Update last_updated on video
@@ -116,7 +192,78 @@ func (r *VoteRepo) SubmitVote(ctx context.Context, videoID, userID, category, ip
 	}
 
 	err = tx.Commit(ctx)
-	return trustWeight, err
+	return trustScore, err
+}
+
+// recordMetadata stores a voter's metadata report and reconciles the video's
+// metadata columns. For each field, the value whose reporters' current votes
+// carry the most total trust_weight wins, ties going to the most recent
+// report; reporters whose votes carry no weight (shadowbanned) are ignored.
+// If the reconciled channel changes, both channels are queued for
+// recalculation.
+func recordMetadata(ctx context.Context, tx pgx.Tx, videoID, userID string, meta model.VideoMetadata) error {
+	_, err := tx.Exec(ctx, `
+		INSERT INTO video_metadata_reports (video_id, user_id, channel_id, title, video_duration, is_short)
+		VALUES ($1, $2, $3, $4, $5, $6)
+		ON CONFLICT (video_id, user_id) DO UPDATE
+		SET channel_id     = COALESCE(EXCLUDED.channel_id, video_metadata_reports.channel_id),
+		    title          = COALESCE(EXCLUDED.title, video_metadata_reports.title),
+		    video_duration = COALESCE(EXCLUDED.video_duration, video_metadata_reports.video_duration),
+		    is_short       = COALESCE(EXCLUDED.is_short, video_metadata_reports.is_short),
+		    reported_at    = NOW()`,
+		videoID, userID, meta.ChannelID, meta.Title, meta.VideoDuration, meta.IsShort)
+	if err != nil {
+		return err
+	}
+
+	var oldChannel, newChannel *string
+	err = tx.QueryRow(ctx, `
+		WITH reports AS (
+			SELECT r.channel_id, r.title, r.video_duration, r.is_short, r.reported_at, vo.trust_weight
+			FROM video_metadata_reports r
+			JOIN votes vo ON vo.video_id = r.video_id AND vo.user_id = r.user_id
+			WHERE r.video_id = $1 AND vo.trust_weight > 0
+		), old AS (
+			SELECT channel_id FROM videos WHERE video_id = $1
+		)
+		UPDATE videos v
+		SET channel_id = COALESCE((
+		        SELECT channel_id FROM reports WHERE channel_id IS NOT NULL
+		        GROUP BY channel_id ORDER BY SUM(trust_weight) DESC, MAX(reported_at) DESC LIMIT 1
+		    ), v.channel_id),
+		    title = COALESCE((
+		        SELECT title FROM reports WHERE title IS NOT NULL
+		        GROUP BY title ORDER BY SUM(trust_weight) DESC, MAX(reported_at) DESC LIMIT 1
+		    ), v.title),
+		    video_duration = COALESCE((
+		        SELECT video_duration FROM reports WHERE video_duration IS NOT NULL
+		        GROUP BY video_duration ORDER BY SUM(trust_weight) DESC, MAX(reported_at) DESC LIMIT 1
+		    ), v.video_duration),
+		    is_short = COALESCE((
+		        SELECT is_short FROM reports WHERE is_short IS NOT NULL
+		        GROUP BY is_short ORDER BY SUM(trust_weight) DESC, MAX(reported_at) DESC LIMIT 1
+		    ), v.is_short)
+		FROM old
+		WHERE v.video_id = $1
+		RETURNING old.channel_id, v.channel_id`,
+		videoID).Scan(&oldChannel, &newChannel)
+	if err != nil {
+		return err
+	}
+
+	if newChannel != nil && (oldChannel == nil || *oldChannel != *newChannel) {
+		_, err = tx.Exec(ctx, `
+			INSERT INTO channel_recalc_queue (channel_id)
+			SELECT channel_id FROM unnest($1::text[]) AS channel_id
+			WHERE channel_id IS NOT NULL
+			ORDER BY channel_id
+			ON CONFLICT (channel_id) DO NOTHING`,
+			[]*string{oldChannel, newChannel})
+		if err != nil {
+			return err
+		}
+	}
+	return nil
 }
 
 // DeleteVote removes a user's vote on a video and adjusts counters atomically.
@@ -127,11 +274,12 @@ func (r *VoteRepo) DeleteVote(ctx context.Context, videoID, userID string) error
 	}
 	defer tx.Rollback(ctx)
 
-	// Get the vote's category before deleting
+	// Get the vote's category and accuracy verdict before deleting
 	var category string
+	var accurate *bool
 	err = tx.QueryRow(ctx, `
-		SELECT category FROM votes WHERE video_id = $1 AND user_id = $2`,
-		videoID, userID).Scan(&category)
+		SELECT category, accurate FROM votes WHERE video_id = $1 AND user_id = $2`,
+		videoID, userID).Scan(&category, &accurate)
 	if err != nil {
 		return err // returns pgx.ErrNoRows if vote doesn't exist
 	}
@@ -142,6 +290,15 @@ func (r *VoteRepo) DeleteVote(ctx context.Context, videoID, userID string) error
 		return err
 	}
 
+	// Queue the video for rescoring (the vote_inserted trigger doesn't fire
+	// on DELETE). Done before touching videos to keep the trigger's lock order.
+	_, err = tx.Exec(ctx, `
+		INSERT INTO score_recalc_queue (video_id) VALUES ($1)
+		ON CONFLICT (video_id) DO NOTHING`, videoID)
+	if err != nil {
+		return err
+	}
+
 	// Decrement counters
 	_, err = tx.Exec(ctx, `
 		UPDATE videos SET total_votes = total_votes - 1, last_updated = NOW()
@@ -158,7 +315,36 @@ func (r *VoteRepo) DeleteVote(ctx context.Context, videoID, userID string) error
 		return err
 	}
 
-	// Manually notify score worker (DELETE trigger doesn't fire vote_inserted)
+	_, err = tx.Exec(ctx, `
+		UPDATE users SET total_votes = total_votes - 1
+		WHERE user_id = $1 AND total_votes > 0`, userID)
+	if err != nil {
+		return err
+	}
+
+	// Withdraw the vote's verdict from the user's accuracy counters
+	if accurate != nil {
+		accurateDelta := 0
+		if *accurate {
+			accurateDelta = 1
+		}
+		_, err = tx.Exec(ctx, `
+			UPDATE users
+			SET accurate_votes = GREATEST(accurate_votes - $1, 0),
+			    judged_votes   = GREATEST(judged_votes - 1, 0),
+			    accuracy_rate  = CASE
+			        WHEN judged_votes - 1 > 0
+			        THEN GREATEST(accurate_votes - $1, 0)::float / (judged_votes - 1)
+			        ELSE 0.5
+			    END
+			WHERE user_id = $2`,
+			accurateDelta, userID)
+		if err != nil {
+			return err
+		}
+	}
+
+	// Wake up the score worker
 	_, err = tx.Exec(ctx, `SELECT pg_notify('vote_changes', $1)`, videoID)
 	if err != nil {
 		return err
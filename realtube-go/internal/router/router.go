@@ -6,6 +6,7 @@ import (
 
 	"github.com/mathieu-neron/RealTube/realtube-go/internal/handler"
 	"github.com/mathieu-neron/RealTube/realtube-go/internal/middleware"
+	"github.com/mathieu-neron/RealTube/realtube-go/internal/model"
 )
 
 // Handlers holds all handler instances needed by the router.
@@ -18,10 +19,20 @@ type Handlers struct {
 	Sync    *handler.SyncHandler
 	Health  *handler.HealthHandler
 	Export  *handler.ExportHandler
+
+	Moderation    *handler.ModerationHandler
+	ModerationLog *handler.ModerationLogHandler
+	Appeal        *handler.AppealHandler
+	Sybil         *handler.SybilHandler
+	Challenge     *handler.ChallengeHandler
+	Brigade       *handler.BrigadeHandler
+	Admin         *handler.AdminHandler
+	Auth          fiber.Handler // authenticates VIPs and admin keys on privileged routes
 }
 
 // Setup configures the middleware stack and all API routes on the given Fiber app.
-func Setup(app *fiber.App, h *Handlers, corsOrigins string) {
+// The rate limiters keep their buckets in limits.
+func Setup(app *fiber.App, h *Handlers, corsOrigins string, limits middleware.RateLimitStore) {
 	// Middleware stack (order matters)
 	app.Use(recoverer.New())
 	app.Use(handler.MetricsMiddleware())
@@ -29,11 +40,11 @@ func Setup(app *fiber.App, h *Handlers, corsOrigins string) {
 	app.Use(middleware.NewCORS(corsOrigins))
 
 	// Rate limiters (per-route, matching api-contract.md §5.3)
-	videoRL := middleware.NewVideoRateLimiter()
-	voteSubmitRL := middleware.NewVoteSubmitRateLimiter()
-	voteDeleteRL := middleware.NewVoteDeleteRateLimiter()
-	syncRL := middleware.NewSyncRateLimiter()
-	statsRL := middleware.NewStatsRateLimiter()
+	videoRL := middleware.NewVideoRateLimiter(limits)
+	voteSubmitRL := middleware.NewVoteSubmitRateLimiter(limits)
+	voteDeleteRL := middleware.NewVoteDeleteRateLimiter(limits)
+	syncRL := middleware.NewSyncRateLimiter(limits)
+	statsRL := middleware.NewStatsRateLimiter(limits)
 
 	// Health check endpoints (no rate limiting)
 	app.Get("/health/live", h.Health.Live)
@@ -53,11 +64,17 @@ func Setup(app *fiber.App, h *Handlers, corsOrigins string) {
 	api.Post("/votes", voteSubmitRL.Handler(), h.Vote.Submit)
 	api.Delete("/votes", voteDeleteRL.Handler(), h.Vote.Delete)
 
+	// Proof-of-work challenges for new and low-trust voters — 30 req/min per IP
+	challengeRL := middleware.NewChallengeRateLimiter(limits)
+	api.Get("/challenge", challengeRL.Handler(), h.Challenge.Issue)
+
 	// Channel routes — same limits as video
 	api.Get("/channels/:channelId", videoRL.Handler(), h.Channel.GetByChannelID)
 
 	// User routes — same limits as video
 	api.Get("/users/:userId", videoRL.Handler(), h.User.GetByUserID)
+	api.Get("/users/:userId/trust", videoRL.Handler(), h.User.GetTrust)
+	api.Get("/users/:userId/trust/history", videoRL.Handler(), h.User.GetTrustHistory)
 
 	// Stats routes — 10 req/min per IP
 	api.Get("/stats", statsRL.Handler(), h.Stats.GetStats)
@@ -66,7 +83,59 @@ func Setup(app *fiber.App, h *Handlers, corsOrigins string) {
 	api.Get("/sync/delta", syncRL.Handler(), h.Sync.DeltaSync)
 	api.Get("/sync/full", syncRL.Handler(), h.Sync.FullSync)
 
+	// Appeal routes — 5 submissions/hour per IP
+	appealRL := middleware.NewAppealRateLimiter(limits)
+	api.Post("/appeals", appealRL.Handler(), h.Appeal.Submit)
+	api.Get("/appeals/:id", videoRL.Handler(), h.Appeal.Get)
+
+	// Moderation transparency log — same limits as video
+	api.Get("/moderation/log", videoRL.Handler(), h.ModerationLog.Log)
+	api.Get("/moderation/videos/:videoId", videoRL.Handler(), h.ModerationLog.VideoLog)
+	api.Get("/moderation/channels/:channelId", videoRL.Handler(), h.ModerationLog.ChannelLog)
+
+	// Privileged routes — authenticated, role per group, same limits as video.
+	// Admins can use every group.
+
+	// VIP moderation routes — moderators (VIPs and moderator keys)
+	vip := api.Group("/vip", videoRL.Handler(), h.Auth, middleware.RequireRole(model.RoleModerator))
+	vip.Post("/videos/:videoId/lock", h.Moderation.LockVideo)
+	vip.Delete("/videos/:videoId/lock", h.Moderation.UnlockVideo)
+	vip.Post("/videos/:videoId/hide", h.Moderation.HideVideo)
+	vip.Delete("/videos/:videoId/hide", h.Moderation.UnhideVideo)
+	vip.Put("/videos/:videoId/score", h.Moderation.OverrideScore)
+	vip.Put("/videos/:videoId/category", h.Moderation.OverrideCategory)
+	vip.Post("/channels/:channelId/lock", h.Moderation.LockChannel)
+	vip.Delete("/channels/:channelId/lock", h.Moderation.UnlockChannel)
+	vip.Post("/channels/:channelId/hide", h.Moderation.HideChannel)
+	vip.Delete("/channels/:channelId/hide", h.Moderation.UnhideChannel)
+	vip.Get("/log", h.ModerationLog.FullLog)
+	vip.Get("/appeals", h.Appeal.Queue)
+	vip.Post("/appeals/:id/review", h.Appeal.Review)
+	vip.Post("/appeals/:id/resolve", h.Appeal.Resolve)
+	vip.Get("/sybil/clusters", h.Sybil.List)
+	vip.Get("/sybil/clusters/:id", h.Sybil.Get)
+	vip.Post("/sybil/clusters/:id/dampen", h.Sybil.Dampen)
+	vip.Post("/sybil/clusters/:id/dismiss", h.Sybil.Dismiss)
+	vip.Get("/brigades", h.Brigade.List)
+	vip.Get("/brigades/:id", h.Brigade.Get)
+	vip.Post("/brigades/:id/hold", h.Brigade.Hold)
+	vip.Post("/brigades/:id/release", h.Brigade.Release)
+
+	// Admin routes — key management, VIP status, shadowbans
+	admin := api.Group("/admin", videoRL.Handler(), h.Auth, middleware.RequireRole(model.RoleAdmin))
+	admin.Get("/keys", h.Admin.ListKeys)
+	admin.Post("/keys", h.Admin.CreateKey)
+	admin.Delete("/keys/:id", h.Admin.RevokeKey)
+	admin.Post("/users/:userId/shadowban", h.Moderation.ShadowbanUser)
+	admin.Delete("/users/:userId/shadowban", h.Moderation.UnshadowbanUser)
+	admin.Post("/users/:userId/vip", h.Moderation.GrantVIP)
+	admin.Delete("/users/:userId/vip", h.Moderation.RevokeVIP)
+
+	// Ops routes — read-only operational state
+	ops := api.Group("/ops", videoRL.Handler(), h.Auth, middleware.RequireRole(model.RoleReadOnlyOps))
+	ops.Get("/queues", h.Admin.QueueStats)
+
 	// Database export — 1 req/hour per IP (NGINX also rate-limits this)
-	exportRL := middleware.NewExportRateLimiter()
+	exportRL := middleware.NewExportRateLimiter(limits)
 	api.Get("/database/export", exportRL.Handler(), h.Export.Export)
 }
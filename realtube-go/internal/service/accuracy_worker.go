@@ -0,0 +1,262 @@
+package service
+
+import (
+	"context"
+	"log"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+
+	"github.com/mathieu-neron/RealTube/realtube-go/internal/repository"
+)
+
+const (
+	// FlagThreshold is the video score at which the community consensus
+	// considers a video AI-generated (trust-system-design.md §9 thresholds).
+	FlagThreshold = 50.0
+
+	// A video's consensus is settled once it is locked or its score has not
+	// changed for this long.
+	accuracySettlePeriod = 7 * 24 * time.Hour
+
+	// Maximum number of votes judged per transaction.
+	accuracyBatchSize = 1000
+)
+
+// AccuracyWorker is a periodic background job that judges votes against the
+// settled consensus of their video and maintains users.accurate_votes,
+// users.judged_votes and users.accuracy_rate (trust-system-design.md §9).
+//
+// Each vote stores its last verdict, so counters are adjusted by deltas: a
+// vote is only re-judged when its video's score changes after the previous
+// verdict (videos.score_changed_at). Only votes cast before the score
+// settled, and at least the settle period ago, are judged: a vote cast
+// later could copy the score it was shown. Such a vote loses the verdict
+// of the category it replaced.
+type AccuracyWorker struct {
+	pool     *pgxpool.Pool
+	interval time.Duration
+	stopCh   chan struct{}
+}
+
+// NewAccuracyWorker creates a worker that ticks every interval.
+func NewAccuracyWorker(pool *pgxpool.Pool, interval time.Duration) *AccuracyWorker {
+	return &AccuracyWorker{
+		pool:     pool,
+		interval: interval,
+		stopCh:   make(chan struct{}),
+	}
+}
+
+// Start begins the periodic accuracy evaluation loop.
+// It runs one tick immediately, then every interval.
+func (w *AccuracyWorker) Start(ctx context.Context) {
+	log.Printf("accuracy-worker: starting (interval=%s, settle period=%s)", w.interval, accuracySettlePeriod)
+
+	w.tick(ctx)
+
+	ticker := time.NewTicker(w.interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			w.tick(ctx)
+		case <-ctx.Done():
+			log.Println("accuracy-worker: stopping (context cancelled)")
+			return
+		case <-w.stopCh:
+			log.Println("accuracy-worker: stopping (stop signal)")
+			return
+		}
+	}
+}
+
+// Stop signals the worker to stop.
+func (w *AccuracyWorker) Stop() {
+	close(w.stopCh)
+}
+
+// tick judges all votes on settled videos that have no up-to-date verdict.
+func (w *AccuracyWorker) tick(ctx context.Context) {
+	start := time.Now()
+
+	judged, users := 0, 0
+	for {
+		n, u, err := w.judgeBatch(ctx)
+		if err != nil {
+			log.Printf("accuracy-worker: error: %v", err)
+			return
+		}
+		judged += n
+		users += u
+		if n < accuracyBatchSize || ctx.Err() != nil {
+			break
+		}
+	}
+
+	elapsed := time.Since(start)
+	log.Printf("accuracy-worker: tick complete — %d votes judged, %d users updated (%s)",
+		judged, users, elapsed.Round(time.Millisecond))
+}
+
+// userAccuracyDelta accumulates counter changes for one user within a batch.
+type userAccuracyDelta struct {
+	accurate int
+	judged   int
+}
+
+// judgeBatch judges up to accuracyBatchSize votes in a single transaction and
+// applies the resulting counter deltas to the users table.
+func (w *AccuracyWorker) judgeBatch(ctx context.Context) (judged, users int, err error) {
+	tx, err := w.pool.Begin(ctx)
+	if err != nil {
+		return 0, 0, err
+	}
+	defer tx.Rollback(ctx)
+
+	// Votes to judge, and votes cast after settlement that hold the verdict
+	// of a replaced category, to withdraw. VoteVerdict tells them apart.
+	rows, err := tx.Query(ctx, `
+		SELECT vo.id, vo.user_id, vo.category, vo.accurate, v.score, vo.created_at, v.score_changed_at, NOW()
+		FROM votes vo
+		JOIN videos v ON v.video_id = vo.video_id
+		WHERE (v.locked OR v.score_changed_at < NOW() - make_interval(secs => $1))
+		  AND (vo.judged_at IS NULL OR vo.judged_at < v.score_changed_at)
+		  AND ((vo.created_at < v.score_changed_at AND vo.created_at < NOW() - make_interval(secs => $1))
+		       OR vo.accurate IS NOT NULL)
+		LIMIT $2
+		FOR UPDATE OF vo SKIP LOCKED`,
+		accuracySettlePeriod.Seconds(), accuracyBatchSize)
+	if err != nil {
+		return 0, 0, err
+	}
+	defer rows.Close()
+
+	var voteIDs []int64
+	var verdicts []*bool
+	deltas := make(map[string]*userAccuracyDelta)
+
+	for rows.Next() {
+		var id int64
+		var userID, category string
+		var prev *bool
+		var score float64
+		var castAt, settledAt, now time.Time
+		if err := rows.Scan(&id, &userID, &category, &prev, &score, &castAt, &settledAt, &now); err != nil {
+			return 0, 0, err
+		}
+
+		verdict, accDelta, judgedDelta := VoteVerdict(prev, category, score, castAt, settledAt, now)
+		voteIDs = append(voteIDs, id)
+		verdicts = append(verdicts, verdict)
+
+		if accDelta == 0 && judgedDelta == 0 {
+			continue
+		}
+		d, ok := deltas[userID]
+		if !ok {
+			d = &userAccuracyDelta{}
+			deltas[userID] = d
+		}
+		d.accurate += accDelta
+		d.judged += judgedDelta
+	}
+	if err := rows.Err(); err != nil {
+		return 0, 0, err
+	}
+
+	if len(voteIDs) == 0 {
+		return 0, 0, nil
+	}
+
+	_, err = tx.Exec(ctx, `
+		UPDATE votes
+		SET accurate = data.accurate, judged_at = NOW()
+		FROM unnest($1::bigint[], $2::boolean[]) AS data(id, accurate)
+		WHERE votes.id = data.id`,
+		voteIDs, verdicts)
+	if err != nil {
+		return 0, 0, err
+	}
+
+	if len(deltas) > 0 {
+		userIDs := make([]string, 0, len(deltas))
+		accurateDeltas := make([]int32, 0, len(deltas))
+		judgedDeltas := make([]int32, 0, len(deltas))
+		for userID, d := range deltas {
+			userIDs = append(userIDs, userID)
+			accurateDeltas = append(accurateDeltas, int32(d.accurate))
+			judgedDeltas = append(judgedDeltas, int32(d.judged))
+		}
+
+		// SET expressions see the pre-update row, so the new rate is computed
+		// from the old counters plus the deltas.
+		_, err = tx.Exec(ctx, `
+			UPDATE users u
+			SET accurate_votes = u.accurate_votes + d.accurate,
+			    judged_votes   = u.judged_votes + d.judged,
+			    accuracy_rate  = CASE
+			        WHEN u.judged_votes + d.judged > 0
+			        THEN (u.accurate_votes + d.accurate)::float / (u.judged_votes + d.judged)
+			        ELSE u.accuracy_rate
+			    END
+			FROM unnest($1::text[], $2::int[], $3::int[]) AS d(user_id, accurate, judged)
+			WHERE u.user_id = d.user_id`,
+			userIDs, accurateDeltas, judgedDeltas)
+		if err != nil {
+			return 0, 0, err
+		}
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return 0, 0, err
+	}
+	return len(voteIDs), len(deltas), nil
+}
+
+// JudgeVote reports whether a vote agrees with its video's settled score.
+// AI category votes are accurate when the consensus flagged the video;
+// not_ai dissent votes are accurate when it did not.
+func JudgeVote(category string, videoScore float64) bool {
+	if category == repository.CategoryNotAI {
+		return videoScore < FlagThreshold
+	}
+	return videoScore >= FlagThreshold
+}
+
+// VoteVerdict judges a vote cast at castAt on a video whose score settled at
+// settledAt. Returns the vote's verdict, nil for a vote cast after
+// settlement or less than the settle period before now, which is not judged
+// and loses its previous verdict prev, and the change to its user's
+// accurate_votes and judged_votes counters.
+func VoteVerdict(prev *bool, category string, score float64, castAt, settledAt, now time.Time) (*bool, int, int) {
+	if !castAt.Before(settledAt) || now.Sub(castAt) <= accuracySettlePeriod {
+		if prev == nil {
+			return nil, 0, 0
+		}
+		if *prev {
+			return nil, -1, -1
+		}
+		return nil, 0, -1
+	}
+
+	verdict := JudgeVote(category, score)
+	accurate, judged := AccuracyDelta(prev, verdict)
+	return &verdict, accurate, judged
+}
+
+// AccuracyDelta returns the change to a user's accurate_votes and
+// judged_votes counters when a vote's verdict moves from prev (nil if the
+// vote was never judged) to verdict.
+func AccuracyDelta(prev *bool, verdict bool) (accurate, judged int) {
+	if prev == nil {
+		judged = 1
+	} else if *prev {
+		accurate--
+	}
+	if verdict {
+		accurate++
+	}
+	return accurate, judged
+}
@@ -0,0 +1,101 @@
+package service
+
+import (
+	"testing"
+	"time"
+)
+
+func TestJudgeVote(t *testing.T) {
+	tests := []struct {
+		name     string
+		category string
+		score    float64
+		want     bool
+	}{
+		{"flagged video", "fully_ai", 87.5, true},
+		{"exactly at threshold", "ai_voiceover", 50.0, true},
+		{"below threshold", "fully_ai", 49.99, false},
+		{"unflagged video", "ai_visuals", 0, false},
+		{"dissent on unflagged video", "not_ai", 12.0, true},
+		{"dissent on flagged video", "not_ai", 75.0, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := JudgeVote(tt.category, tt.score)
+			if got != tt.want {
+				t.Errorf("JudgeVote(%q, %.2f) = %v, want %v", tt.category, tt.score, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestAccuracyDelta(t *testing.T) {
+	yes, no := true, false
+
+	tests := []struct {
+		name         string
+		prev         *bool
+		verdict      bool
+		wantAccurate int
+		wantJudged   int
+	}{
+		{"first verdict accurate", nil, true, 1, 1},
+		{"first verdict inaccurate", nil, false, 0, 1},
+		{"unchanged accurate", &yes, true, 0, 0},
+		{"unchanged inaccurate", &no, false, 0, 0},
+		{"consensus flipped to agree", &no, true, 1, 0},
+		{"consensus flipped to disagree", &yes, false, -1, 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			accurate, judged := AccuracyDelta(tt.prev, tt.verdict)
+			if accurate != tt.wantAccurate || judged != tt.wantJudged {
+				t.Errorf("AccuracyDelta() = (%d, %d), want (%d, %d)",
+					accurate, judged, tt.wantAccurate, tt.wantJudged)
+			}
+		})
+	}
+}
+
+func TestVoteVerdict(t *testing.T) {
+	yes, no := true, false
+	now := time.Date(2026, 3, 1, 0, 0, 0, 0, time.UTC)
+	settled := now.Add(-accuracySettlePeriod - time.Hour)
+
+	tests := []struct {
+		name         string
+		prev         *bool
+		category     string
+		castAt       time.Time
+		settledAt    time.Time
+		wantVerdict  *bool
+		wantAccurate int
+		wantJudged   int
+	}{
+		{"cast before settlement", nil, "fully_ai", settled.Add(-time.Hour), settled, &yes, 1, 1},
+		{"cast before settlement, dissent", nil, "not_ai", settled.Add(-time.Hour), settled, &no, 0, 1},
+		// Copying the settled score doesn't earn accuracy
+		{"cast after settlement", nil, "fully_ai", settled.Add(time.Minute), settled, nil, 0, 0},
+		{"cast at settlement", nil, "fully_ai", settled, settled, nil, 0, 0},
+		// A vote changed after settlement loses the old category's verdict
+		{"changed after settlement, was accurate", &yes, "fully_ai", settled.Add(time.Minute), settled, nil, -1, -1},
+		{"changed after settlement, was inaccurate", &no, "fully_ai", settled.Add(time.Minute), settled, nil, 0, -1},
+		// On a video locked since, the vote must still be older than the
+		// settle period
+		{"recent vote on a locked video", nil, "fully_ai", now.Add(-2 * time.Hour), now.Add(-time.Hour), nil, 0, 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			verdict, accurate, judged := VoteVerdict(tt.prev, tt.category, 80, tt.castAt, tt.settledAt, now)
+			if (verdict == nil) != (tt.wantVerdict == nil) || (verdict != nil && *verdict != *tt.wantVerdict) {
+				t.Errorf("verdict = %v, want %v", verdict, tt.wantVerdict)
+			}
+			if accurate != tt.wantAccurate || judged != tt.wantJudged {
+				t.Errorf("deltas = (%d, %d), want (%d, %d)", accurate, judged, tt.wantAccurate, tt.wantJudged)
+			}
+		})
+	}
+}
@@ -0,0 +1,176 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+
+	"github.com/jackc/pgx/v5"
+
+	"github.com/mathieu-neron/RealTube/realtube-go/internal/model"
+	"github.com/mathieu-neron/RealTube/realtube-go/internal/repository"
+)
+
+var (
+	// ErrAppealNotPending is returned when reviewing or resolving an appeal
+	// that is no longer open or under review.
+	ErrAppealNotPending = errors.New("appeal is not pending")
+
+	// ErrInvalidAppealAction is returned for a resolution action that does
+	// not apply to the appeal's target.
+	ErrInvalidAppealAction = errors.New("invalid appeal action")
+)
+
+// AppealService handles creator appeals of video and channel flags. While
+// an appeal is pending its target is marked as disputed in lookups and
+// sync. Resolution actions go through the ModerationService, in the same
+// transaction as the resolution.
+type AppealService struct {
+	repo       *repository.AppealRepo
+	moderation *ModerationService
+	cache      *CacheService
+}
+
+func NewAppealService(repo *repository.AppealRepo, moderation *ModerationService, cache *CacheService) *AppealService {
+	return &AppealService{repo: repo, moderation: moderation, cache: cache}
+}
+
+// Submit opens an appeal and marks its target as disputed. Returns
+// pgx.ErrNoRows if the target is unknown and repository.ErrAppealPending if
+// it already has a pending appeal.
+func (s *AppealService) Submit(ctx context.Context, req model.AppealRequest) (*model.Appeal, error) {
+	var appeal *model.Appeal
+	err := inTx(ctx, s.repo, func(tx pgx.Tx) error {
+		if err := s.repo.SetDisputed(ctx, tx, req.TargetType, req.TargetID, true); err != nil {
+			return err
+		}
+		var err error
+		appeal, err = s.repo.Create(ctx, tx, req)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	s.invalidate(ctx, appeal)
+	return appeal, nil
+}
+
+// Get returns an appeal. Returns pgx.ErrNoRows if it doesn't exist.
+func (s *AppealService) Get(ctx context.Context, id int64) (*model.Appeal, error) {
+	return s.repo.FindByID(ctx, id)
+}
+
+// Queue returns a page of the moderator appeal queue.
+func (s *AppealService) Queue(ctx context.Context, f model.AppealQueueFilter) (*model.AppealQueueResponse, error) {
+	appeals, next, err := fetchPage(f.Limit, func(limit int) ([]model.Appeal, error) {
+		f.Limit = limit
+		return s.repo.Queue(ctx, f)
+	}, func(a *model.Appeal) int64 { return a.ID })
+	if err != nil {
+		return nil, err
+	}
+	return &model.AppealQueueResponse{Appeals: appeals, NextCursor: next}, nil
+}
+
+// Review moves an open appeal under review by actor.
+func (s *AppealService) Review(ctx context.Context, actor *model.Principal, id int64) (*model.Appeal, error) {
+	if _, err := s.repo.FindByID(ctx, id); err != nil {
+		return nil, err
+	}
+	appeal, err := s.repo.Review(ctx, id, actor)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, ErrAppealNotPending
+	}
+	return appeal, err
+}
+
+// Resolve closes a pending appeal as upheld or rejected and clears the
+// disputed marker of its target. If req.Action is set, the moderation
+// action is applied with the resolution as its reason and committed
+// together with the resolution.
+func (s *AppealService) Resolve(ctx context.Context, actor *model.Principal, id int64, req model.AppealResolveRequest) (*model.Appeal, error) {
+	appeal, err := s.repo.FindByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if appeal.Status != model.AppealOpen && appeal.Status != model.AppealUnderReview {
+		return nil, ErrAppealNotPending
+	}
+
+	var resolved *model.Appeal
+	resolve := func(ctx context.Context, tx pgx.Tx, actionID *int64) error {
+		if err := s.repo.SetDisputed(ctx, tx, appeal.TargetType, appeal.TargetID, false); err != nil {
+			return err
+		}
+		var err error
+		resolved, err = s.repo.Resolve(ctx, tx, id, req.Status, req.Resolution, actor, actionID)
+		if errors.Is(err, pgx.ErrNoRows) {
+			return ErrAppealNotPending
+		}
+		return err
+	}
+
+	if req.Action == "" {
+		err = inTx(ctx, s.repo, func(tx pgx.Tx) error {
+			return resolve(ctx, tx, nil)
+		})
+	} else {
+		moderation := s.moderation.withAfter(func(ctx context.Context, tx pgx.Tx, a *model.VIPAction) error {
+			return resolve(ctx, tx, &a.ID)
+		})
+		_, err = applyAppealAction(ctx, moderation, actor, appeal, req)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	s.invalidate(ctx, resolved)
+	return resolved, nil
+}
+
+// applyAppealAction applies the moderation action of a resolution to the
+// appeal's target.
+func applyAppealAction(ctx context.Context, m *ModerationService, actor *model.Principal, appeal *model.Appeal,
+	req model.AppealResolveRequest) (*model.VIPAction, error) {
+	reason := fmt.Sprintf("Appeal #%d: %s", appeal.ID, req.Resolution)
+
+	if appeal.TargetType == model.TargetChannel {
+		switch req.Action {
+		case model.ActionLock, model.ActionUnlock:
+			return m.SetChannelLocked(ctx, actor, appeal.TargetID, reason, req.Action == model.ActionLock)
+		}
+		return nil, fmt.Errorf("%w: %s does not apply to channels", ErrInvalidAppealAction, req.Action)
+	}
+
+	switch req.Action {
+	case model.ActionLock, model.ActionUnlock:
+		return m.SetVideoLocked(ctx, actor, appeal.TargetID, reason, req.Action == model.ActionLock)
+	case model.ActionOverrideScore:
+		if req.Score == nil {
+			return nil, fmt.Errorf("%w: override_score needs a score", ErrInvalidAppealAction)
+		}
+		return m.OverrideScore(ctx, actor, appeal.TargetID, reason, *req.Score)
+	case model.ActionOverrideCategory:
+		return m.OverrideCategory(ctx, actor, appeal.TargetID, reason, req.Category, req.Score)
+	}
+	return nil, fmt.Errorf("%w: %s", ErrInvalidAppealAction, req.Action)
+}
+
+// invalidate drops the cached lookup of the appeal's target, whose disputed
+// marker changed.
+func (s *AppealService) invalidate(ctx context.Context, appeal *model.Appeal) {
+	if s.cache == nil {
+		return
+	}
+	var err error
+	if appeal.TargetType == model.TargetVideo {
+		err = s.cache.InvalidateVideo(ctx, appeal.TargetID)
+	} else {
+		err = s.cache.InvalidateChannel(ctx, appeal.TargetID)
+	}
+	if err != nil {
+		log.Printf("cache: invalidate %s error: %v", appeal.TargetType, err)
+	}
+}
@@ -0,0 +1,38 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/mathieu-neron/RealTube/realtube-go/internal/model"
+)
+
+func TestApplyAppealAction_Invalid(t *testing.T) {
+	actor := &model.Principal{Role: model.RoleModerator, UserID: "mod"}
+	video := &model.Appeal{ID: 1, TargetType: model.TargetVideo, TargetID: "dQw4w9WgXcQ"}
+	channel := &model.Appeal{ID: 2, TargetType: model.TargetChannel, TargetID: "UCuAXFkgsw1L7xaCfnd5JJOw"}
+
+	tests := []struct {
+		name   string
+		appeal *model.Appeal
+		action string
+	}{
+		{"score override on a channel", channel, model.ActionOverrideScore},
+		{"category override on a channel", channel, model.ActionOverrideCategory},
+		{"hide on a channel", channel, model.ActionHide},
+		{"score override without a score", video, model.ActionOverrideScore},
+		{"hide on a video", video, model.ActionHide},
+		{"shadowban", video, model.ActionShadowban},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Invalid actions are rejected before the moderation service is used
+			req := model.AppealResolveRequest{Status: model.AppealUpheld, Resolution: "ok", Action: tt.action}
+			_, err := applyAppealAction(context.Background(), nil, actor, tt.appeal, req)
+			if !errors.Is(err, ErrInvalidAppealAction) {
+				t.Errorf("err = %v, want ErrInvalidAppealAction", err)
+			}
+		})
+	}
+}
@@ -0,0 +1,106 @@
+package service
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/jackc/pgx/v5"
+
+	"github.com/mathieu-neron/RealTube/realtube-go/internal/model"
+	"github.com/mathieu-neron/RealTube/realtube-go/internal/repository"
+	"github.com/mathieu-neron/RealTube/realtube-go/pkg/hash"
+)
+
+// AdminKeyPrefix starts every admin API key, which tells them apart from the
+// private user IDs VIPs authenticate with.
+const AdminKeyPrefix = "rtk_"
+
+// adminKeyBytes is the entropy of an admin API key. With 256 random bits a
+// single unsalted SHA256 is enough to store the key.
+const adminKeyBytes = 32
+
+// adminKeyShownLen is how much of a key is stored in clear as key_prefix.
+const adminKeyShownLen = len(AdminKeyPrefix) + 8
+
+// ErrInvalidRole is returned when creating a key with an unknown role.
+var ErrInvalidRole = errors.New("invalid role")
+
+// AuthService authenticates callers of the privileged endpoints and manages
+// admin API keys.
+type AuthService struct {
+	keys  *repository.AdminKeyRepo
+	users *repository.UserRepo
+}
+
+func NewAuthService(keys *repository.AdminKeyRepo, users *repository.UserRepo) *AuthService {
+	return &AuthService{keys: keys, users: users}
+}
+
+// Authenticate resolves a bearer token to a principal, or returns nil if the
+// token is not a valid credential.
+//
+// Tokens starting with AdminKeyPrefix are admin API keys and get the key's
+// role. Any other token is taken as a private local user ID: the public ID
+// is derived with hash.HashUserID, as the extension does, and VIPs in good
+// standing are moderators.
+func (s *AuthService) Authenticate(ctx context.Context, token string) (*model.Principal, error) {
+	if strings.HasPrefix(token, AdminKeyPrefix) {
+		k, err := s.keys.Use(ctx, hash.SHA256Hex(token))
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+		return &model.Principal{Role: k.Role, KeyID: k.ID}, nil
+	}
+
+	userID := hash.HashUserID(token)
+	vip, err := s.users.IsVIP(ctx, userID)
+	if err != nil || !vip {
+		return nil, err
+	}
+	return &model.Principal{Role: model.RoleModerator, UserID: userID}, nil
+}
+
+// CreateKey generates a key with the given role and stores its hash. The
+// returned key can't be recovered later.
+func (s *AuthService) CreateKey(ctx context.Context, name, role string) (string, *model.AdminKey, error) {
+	if !model.ValidRoles[role] {
+		return "", nil, fmt.Errorf("%w %q", ErrInvalidRole, role)
+	}
+
+	key, err := GenerateAdminKey()
+	if err != nil {
+		return "", nil, err
+	}
+	k, err := s.keys.Create(ctx, name, key[:adminKeyShownLen], hash.SHA256Hex(key), role)
+	if err != nil {
+		return "", nil, err
+	}
+	return key, k, nil
+}
+
+// ListKeys returns all admin keys, revoked ones included.
+func (s *AuthService) ListKeys(ctx context.Context) ([]model.AdminKey, error) {
+	return s.keys.List(ctx)
+}
+
+// RevokeKey revokes an admin key. Returns pgx.ErrNoRows if there is no
+// active key with that ID.
+func (s *AuthService) RevokeKey(ctx context.Context, id int64) error {
+	return s.keys.Revoke(ctx, id)
+}
+
+// GenerateAdminKey returns a new random admin API key.
+func GenerateAdminKey() (string, error) {
+	b := make([]byte, adminKeyBytes)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return AdminKeyPrefix + base64.RawURLEncoding.EncodeToString(b), nil
+}
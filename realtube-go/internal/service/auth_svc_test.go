@@ -0,0 +1,35 @@
+package service
+
+import (
+	"encoding/base64"
+	"strings"
+	"testing"
+)
+
+func TestGenerateAdminKey(t *testing.T) {
+	key, err := GenerateAdminKey()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.HasPrefix(key, AdminKeyPrefix) {
+		t.Fatalf("key %q does not start with %q", key, AdminKeyPrefix)
+	}
+	raw, err := base64.RawURLEncoding.DecodeString(strings.TrimPrefix(key, AdminKeyPrefix))
+	if err != nil {
+		t.Fatalf("key %q is not base64url: %v", key, err)
+	}
+	if len(raw) != adminKeyBytes {
+		t.Errorf("key has %d random bytes, want %d", len(raw), adminKeyBytes)
+	}
+	if len(key) <= adminKeyShownLen {
+		t.Errorf("key %q is not longer than its stored prefix", key)
+	}
+
+	other, err := GenerateAdminKey()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if other == key {
+		t.Error("two generated keys are equal")
+	}
+}
@@ -0,0 +1,285 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"log"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+
+	"github.com/mathieu-neron/RealTube/realtube-go/internal/model"
+	"github.com/mathieu-neron/RealTube/realtube-go/internal/repository"
+)
+
+// ErrBrigadeStatus is returned when holding or releasing a brigade that is
+// no longer frozen.
+var ErrBrigadeStatus = errors.New("brigade is not frozen")
+
+// BrigadeService detects vote spikes on a video, or on a channel's videos
+// together, and freezes the affected videos at their pre-spike score, the
+// score of their votes cast before the detection window. The score worker
+// runs detection on every batch before rescoring it. Frozen videos are
+// skipped by rescoring like locked ones, while their votes keep being
+// recorded. Brigades are listed for moderators, who can release a freeze
+// early or hold it until released; unheld freezes expire after FreezeFor.
+// Thawed videos are queued for rescoring with every vote counted.
+type BrigadeService struct {
+	repo      *repository.BrigadeRepo
+	scores    *ScoreService
+	cache     *CacheService
+	detection model.BrigadeDetection
+}
+
+func NewBrigadeService(repo *repository.BrigadeRepo, scores *ScoreService, cache *CacheService,
+	detection model.BrigadeDetection) *BrigadeService {
+	return &BrigadeService{repo: repo, scores: scores, cache: cache, detection: detection}
+}
+
+// Check looks for vote spikes on the videos of videoIDs and their channels
+// within the score worker's transaction, records the brigades found and
+// freezes their videos. Videos of a channel that already has a frozen
+// brigade are frozen too. Frozen videos are rescored counting only their
+// votes cast before their brigade's detection window, so the spike doesn't
+// reach their score. Returns the number of brigades found and the IDs of the videos
+// frozen, which may include videos of a brigaded channel outside videoIDs.
+func (s *BrigadeService) Check(ctx context.Context, tx pgx.Tx, videoIDs []string) (int, []string, error) {
+	if !s.detection.Enabled || len(videoIDs) == 0 {
+		return 0, nil, nil
+	}
+
+	stats, err := s.repo.VideoStats(ctx, tx, videoIDs, s.detection)
+	if err != nil {
+		return 0, nil, err
+	}
+	channelStats, err := s.repo.ChannelStats(ctx, tx, videoIDs, s.detection)
+	if err != nil {
+		return 0, nil, err
+	}
+
+	// A spiking video is frozen at its score before the window
+	var spiking []string
+	var cutoffs []time.Time
+	for _, st := range stats {
+		if s.SpikeReason(st) != "" {
+			spiking = append(spiking, st.TargetID)
+			cutoffs = append(cutoffs, st.CountedAt.Add(-s.detection.Window))
+		}
+	}
+	if len(spiking) > 0 {
+		preSpike, err := s.scores.preSpikeScores(ctx, tx, spiking, cutoffs)
+		if err != nil {
+			return 0, nil, err
+		}
+		for i := range stats {
+			if score, ok := preSpike[stats[i].TargetID]; ok {
+				stats[i].Score = score
+			}
+		}
+	}
+
+	found := 0
+	var frozen []model.FrozenVideo
+	expiresAt := time.Now().Add(s.detection.FreezeFor)
+	for _, st := range append(stats, channelStats...) {
+		reason := s.SpikeReason(st)
+		if reason == "" {
+			continue
+		}
+		id, videos, err := s.repo.Freeze(ctx, tx, st, reason, s.detection.Window, expiresAt)
+		if err != nil {
+			return found, nil, err
+		}
+		if id != 0 {
+			found++
+			frozen = append(frozen, videos...)
+			log.Printf("brigade: froze %s %s at score %.1f (%s: %d votes in window, %d new accounts, %d baseline)",
+				st.TargetType, st.TargetID, st.Score, reason, st.WindowVotes, st.NewAccountVotes, st.BaselineVotes)
+		}
+	}
+
+	videos, err := s.repo.FreezeChannelVideos(ctx, tx, videoIDs)
+	if err != nil {
+		return found, nil, err
+	}
+	frozen = append(frozen, videos...)
+
+	frozenIDs, cutoffs := preSpikeCutoffs(frozen, s.detection.Window)
+	if err := s.scores.freezeScores(ctx, tx, frozenIDs, cutoffs); err != nil {
+		return found, nil, err
+	}
+	return found, frozenIDs, nil
+}
+
+// preSpikeCutoffs returns the IDs of the frozen videos, sorted to keep row
+// lock order stable between concurrent workers, and the time up to which
+// each one's pre-spike score counts votes: the start of the detection
+// window of its brigade. A video frozen later by its channel's brigade gets
+// the same cutoff as the channel, so the spike's votes never count.
+func preSpikeCutoffs(frozen []model.FrozenVideo, window time.Duration) ([]string, []time.Time) {
+	frozen = slices.Clone(frozen)
+	slices.SortFunc(frozen, func(a, b model.FrozenVideo) int { return strings.Compare(a.VideoID, b.VideoID) })
+
+	videoIDs := make([]string, len(frozen))
+	cutoffs := make([]time.Time, len(frozen))
+	for i, f := range frozen {
+		videoIDs[i] = f.VideoID
+		cutoffs[i] = f.DetectedAt.Add(-window)
+	}
+	return videoIDs, cutoffs
+}
+
+// SpikeReason returns why the votes counted in st make a brigade, or "" if
+// they don't: a share of new accounts of at least NewAccountShare, or a
+// vote rate of at least VelocityFactor times the baseline rate. Targets
+// with fewer votes in the window than the minimum are never brigades, and
+// targets without baseline votes, like new videos, only by new accounts:
+// any first votes would otherwise count as a spike.
+func (s *BrigadeService) SpikeReason(st model.BrigadeStats) string {
+	d := s.detection
+	minVotes := d.MinVideoVotes
+	if st.TargetType == model.TargetChannel {
+		minVotes = d.MinChannelVotes
+	}
+	if st.WindowVotes == 0 || st.WindowVotes < minVotes {
+		return ""
+	}
+
+	if d.NewAccountShare > 0 && float64(st.NewAccountVotes)/float64(st.WindowVotes) >= d.NewAccountShare {
+		return model.BrigadeNewAccounts
+	}
+	if d.VelocityFactor > 0 && d.Baseline > 0 && st.BaselineVotes > 0 {
+		expected := float64(st.BaselineVotes) * d.Window.Seconds() / d.Baseline.Seconds()
+		if float64(st.WindowVotes) >= d.VelocityFactor*expected {
+			return model.BrigadeVelocity
+		}
+	}
+	return ""
+}
+
+// Get returns a brigade. Returns pgx.ErrNoRows if it doesn't exist.
+func (s *BrigadeService) Get(ctx context.Context, id int64) (*model.Brigade, error) {
+	return s.repo.FindByID(ctx, id)
+}
+
+// List returns a page of brigades.
+func (s *BrigadeService) List(ctx context.Context, f model.BrigadeFilter) (*model.BrigadeResponse, error) {
+	brigades, next, err := fetchPage(f.Limit, func(limit int) ([]model.Brigade, error) {
+		f.Limit = limit
+		return s.repo.List(ctx, f)
+	}, func(b *model.Brigade) int64 { return b.ID })
+	if err != nil {
+		return nil, err
+	}
+	return &model.BrigadeResponse{Brigades: brigades, NextCursor: next}, nil
+}
+
+// Hold keeps a frozen brigade frozen until a moderator releases it.
+func (s *BrigadeService) Hold(ctx context.Context, actor *model.Principal, id int64) (*model.BrigadeReviewResponse, error) {
+	if _, err := s.repo.FindByID(ctx, id); err != nil {
+		return nil, err
+	}
+
+	var resp model.BrigadeReviewResponse
+	err := inTx(ctx, s.repo, func(tx pgx.Tx) error {
+		b, err := s.repo.Hold(ctx, tx, id, actor)
+		if errors.Is(err, pgx.ErrNoRows) {
+			return ErrBrigadeStatus
+		}
+		if err != nil {
+			return err
+		}
+		resp = model.BrigadeReviewResponse{Brigade: *b}
+		return s.recordAction(ctx, tx, actor, model.ActionBrigadeHold, b, 0)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &resp, nil
+}
+
+// Release thaws a frozen brigade and queues its videos for rescoring.
+func (s *BrigadeService) Release(ctx context.Context, actor *model.Principal, id int64) (*model.BrigadeReviewResponse, error) {
+	if _, err := s.repo.FindByID(ctx, id); err != nil {
+		return nil, err
+	}
+	resp, err := s.thaw(ctx, id, model.BrigadeReleased, actor)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, ErrBrigadeStatus
+	}
+	return resp, err
+}
+
+// Expire thaws the frozen brigades past their expiry. Returns the number of
+// brigades expired and of videos queued for rescoring.
+func (s *BrigadeService) Expire(ctx context.Context) (brigades, videos int, err error) {
+	ids, err := s.repo.Expired(ctx)
+	if err != nil {
+		return 0, 0, err
+	}
+	for _, id := range ids {
+		resp, err := s.thaw(ctx, id, model.BrigadeExpired, nil)
+		if errors.Is(err, pgx.ErrNoRows) {
+			continue // released meanwhile
+		}
+		if err != nil {
+			return brigades, videos, err
+		}
+		brigades++
+		videos += resp.VideosRequeued
+	}
+	return brigades, videos, nil
+}
+
+// thaw moves a frozen brigade to status and unfreezes its videos in one
+// transaction. A thaw by a moderator is recorded as a brigade_release
+// action; expiry, without an actor, is not.
+func (s *BrigadeService) thaw(ctx context.Context, id int64, status string,
+	actor *model.Principal) (*model.BrigadeReviewResponse, error) {
+	var resp model.BrigadeReviewResponse
+	var videoIDs []string
+	err := inTx(ctx, s.repo, func(tx pgx.Tx) error {
+		b, err := s.repo.SetStatus(ctx, tx, id, status, actor)
+		if err != nil {
+			return err
+		}
+		videoIDs, err = s.repo.Thaw(ctx, tx, b)
+		if err != nil {
+			return err
+		}
+		resp = model.BrigadeReviewResponse{Brigade: *b, VideosRequeued: len(videoIDs)}
+		if actor == nil {
+			return nil
+		}
+		return s.recordAction(ctx, tx, actor, model.ActionBrigadeRelease, b, len(videoIDs))
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	// Drop the cached lookups that still show the freeze
+	if s.cache != nil {
+		for _, videoID := range videoIDs {
+			if err := s.cache.InvalidateVideo(ctx, videoID); err != nil {
+				log.Printf("cache: invalidate video error: %v", err)
+			}
+		}
+	}
+	return &resp, nil
+}
+
+// recordAction records a moderator's decision on brigade b in the
+// moderation log, against the brigade's video or channel.
+func (s *BrigadeService) recordAction(ctx context.Context, tx pgx.Tx, actor *model.Principal, actionType string,
+	b *model.Brigade, videosRequeued int) error {
+	a := newVIPAction(actor, b.TargetType, b.TargetID, "", actionType)
+	details, err := json.Marshal(model.BrigadeReviewDetails{BrigadeID: b.ID, VideosRequeued: videosRequeued})
+	if err != nil {
+		return err
+	}
+	a.Details = details
+	return s.repo.RecordAction(ctx, tx, a)
+}
@@ -0,0 +1,74 @@
+package service
+
+import (
+	"testing"
+	"time"
+
+	"github.com/mathieu-neron/RealTube/realtube-go/internal/model"
+)
+
+func TestBrigadeService_SpikeReason(t *testing.T) {
+	s := NewBrigadeService(nil, nil, nil, model.BrigadeDetection{
+		Enabled:         true,
+		Window:          10 * time.Minute,
+		Baseline:        24 * time.Hour,
+		MinVideoVotes:   20,
+		MinChannelVotes: 50,
+		VelocityFactor:  10,
+		NewAccountShare: 0.6,
+	})
+
+	tests := []struct {
+		name  string
+		stats model.BrigadeStats
+		want  string
+	}{
+		{"too few votes", model.BrigadeStats{TargetType: model.TargetVideo, WindowVotes: 19}, ""},
+		// Without a baseline, a new video's first votes aren't a velocity spike
+		{"new video", model.BrigadeStats{TargetType: model.TargetVideo, WindowVotes: 200}, ""},
+		{"new video new accounts", model.BrigadeStats{TargetType: model.TargetVideo, WindowVotes: 20,
+			NewAccountVotes: 12}, model.BrigadeNewAccounts},
+		// 1440 votes a day is 10 per window: 99 is under 10x, 100 is not
+		{"steady busy video", model.BrigadeStats{TargetType: model.TargetVideo, WindowVotes: 99, BaselineVotes: 1440}, ""},
+		{"busy video spike", model.BrigadeStats{TargetType: model.TargetVideo, WindowVotes: 100, BaselineVotes: 1440}, model.BrigadeVelocity},
+		{"new accounts", model.BrigadeStats{TargetType: model.TargetVideo, WindowVotes: 30, BaselineVotes: 1440,
+			NewAccountVotes: 18}, model.BrigadeNewAccounts},
+		{"few new accounts", model.BrigadeStats{TargetType: model.TargetVideo, WindowVotes: 30, BaselineVotes: 1440,
+			NewAccountVotes: 17}, ""},
+		{"channel below its minimum", model.BrigadeStats{TargetType: model.TargetChannel, WindowVotes: 49}, ""},
+		{"channel spike", model.BrigadeStats{TargetType: model.TargetChannel, WindowVotes: 50, BaselineVotes: 144},
+			model.BrigadeVelocity},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := s.SpikeReason(tt.stats); got != tt.want {
+				t.Errorf("SpikeReason() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestPreSpikeCutoffs(t *testing.T) {
+	window := 10 * time.Minute
+	now := time.Date(2026, 2, 6, 12, 0, 0, 0, time.UTC)
+	channelDetected := now.Add(-3 * window)
+
+	// A new video brigade, and a channel video frozen three windows after
+	// its channel's brigade was detected
+	ids, cutoffs := preSpikeCutoffs([]model.FrozenVideo{
+		{VideoID: "zzzzzzzzzzz", DetectedAt: now},
+		{VideoID: "aaaaaaaaaaa", DetectedAt: channelDetected},
+	}, window)
+
+	if len(ids) != 2 || ids[0] != "aaaaaaaaaaa" || ids[1] != "zzzzzzzzzzz" {
+		t.Fatalf("ids = %v, want sorted video IDs", ids)
+	}
+	// The channel video counts votes up to the channel's window, not up to
+	// now - window, which would let in the spike
+	if want := channelDetected.Add(-window); !cutoffs[0].Equal(want) {
+		t.Errorf("channel video cutoff = %s, want %s", cutoffs[0], want)
+	}
+	if want := now.Add(-window); !cutoffs[1].Equal(want) {
+		t.Errorf("video cutoff = %s, want %s", cutoffs[1], want)
+	}
+}
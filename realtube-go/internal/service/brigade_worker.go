@@ -0,0 +1,70 @@
+package service
+
+import (
+	"context"
+	"log"
+	"time"
+)
+
+// BrigadeWorker is a periodic background job that thaws expired brigade
+// freezes with BrigadeService. Detection itself runs in the ScoreWorker.
+type BrigadeWorker struct {
+	svc      *BrigadeService
+	interval time.Duration
+	stopCh   chan struct{}
+}
+
+// NewBrigadeWorker creates a worker that ticks every interval.
+func NewBrigadeWorker(svc *BrigadeService, interval time.Duration) *BrigadeWorker {
+	return &BrigadeWorker{
+		svc:      svc,
+		interval: interval,
+		stopCh:   make(chan struct{}),
+	}
+}
+
+// Start begins the periodic expiry loop.
+// It runs one tick immediately, then every interval.
+func (w *BrigadeWorker) Start(ctx context.Context) {
+	log.Printf("brigade-worker: starting (interval=%s, freeze=%s)", w.interval, w.svc.detection.FreezeFor)
+
+	w.tick(ctx)
+
+	ticker := time.NewTicker(w.interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			w.tick(ctx)
+		case <-ctx.Done():
+			log.Println("brigade-worker: stopping (context cancelled)")
+			return
+		case <-w.stopCh:
+			log.Println("brigade-worker: stopping (stop signal)")
+			return
+		}
+	}
+}
+
+// Stop signals the worker to stop.
+func (w *BrigadeWorker) Stop() {
+	close(w.stopCh)
+}
+
+// tick thaws the expired freezes.
+func (w *BrigadeWorker) tick(ctx context.Context) {
+	start := time.Now()
+
+	brigades, videos, err := w.svc.Expire(ctx)
+	if err != nil {
+		log.Printf("brigade-worker: error: %v", err)
+		return
+	}
+	if brigades == 0 {
+		return
+	}
+
+	log.Printf("brigade-worker: tick complete — %d brigades expired, %d videos requeued (%s)",
+		brigades, videos, time.Since(start).Round(time.Millisecond))
+}
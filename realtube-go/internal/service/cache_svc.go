@@ -120,6 +120,15 @@ func (c *CacheService) InvalidateChannel(ctx context.Context, channelID string)
 	return c.rdb.Del(ctx, channelKey(channelID)).Err()
 }
 
+// ClaimChallenge marks a proof-of-work challenge nonce as used until ttl
+// passes and reports whether it was unused. Always true if cache is disabled.
+func (c *CacheService) ClaimChallenge(ctx context.Context, nonce string, ttl time.Duration) (bool, error) {
+	if c.rdb == nil {
+		return true, nil
+	}
+	return c.rdb.SetNX(ctx, challengeKey(nonce), 1, ttl).Result()
+}
+
 // Close shuts down the Redis connection.
 func (c *CacheService) Close() error {
 	if c.rdb == nil {
@@ -135,3 +144,7 @@ func videoKey(videoID string) string {
 func channelKey(channelID string) string {
 	return fmt.Sprintf("channel:%s", channelID)
 }
+
+func challengeKey(nonce string) string {
+	return fmt.Sprintf("challenge:%s", nonce)
+}
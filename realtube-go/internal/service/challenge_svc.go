@@ -0,0 +1,244 @@
+package service
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"log"
+	"math"
+	"math/bits"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+
+	"github.com/mathieu-neron/RealTube/realtube-go/internal/model"
+	"github.com/mathieu-neron/RealTube/realtube-go/internal/repository"
+)
+
+var (
+	// ErrChallengeRequired is returned for votes without a challenge from
+	// users who must solve one.
+	ErrChallengeRequired = errors.New("challenge required")
+	// ErrChallengeInvalid is returned for votes whose challenge is forged,
+	// expired, already used, issued for another vote or not solved.
+	ErrChallengeInvalid = errors.New("invalid challenge")
+)
+
+// ChallengeService issues and verifies the hashcash-style proof-of-work
+// challenges that new and low-trust users solve before voting, so that
+// fabricating voters costs CPU time.
+//
+// Challenges are stateless: a challenge carries its difficulty, expiry and
+// a random nonce, signed with a secret together with the user ID, video ID
+// and IP hash it was issued for, so any replica sharing the secret can
+// verify it. Each nonce is also claimed on use, so a challenge can only be
+// spent once: in Redis across replicas, or in memory on the replica when
+// Redis is unavailable.
+type ChallengeService struct {
+	users  *repository.UserRepo
+	ips    *IPHashService
+	trust  *TrustService
+	cache  *CacheService
+	secret []byte
+	policy model.ChallengePolicy
+	used   usedNonces
+}
+
+// NewChallengeService creates the service. If secret is empty a random one
+// is generated, and challenges only verify on the replica that issued them.
+func NewChallengeService(users *repository.UserRepo, ips *IPHashService, trust *TrustService, cache *CacheService,
+	secret string, policy model.ChallengePolicy) *ChallengeService {
+	key := []byte(secret)
+	if secret == "" {
+		key = make([]byte, 32)
+		if _, err := rand.Read(key); err != nil {
+			log.Fatalf("challenge: generate secret: %v", err)
+		}
+	}
+	return &ChallengeService{users: users, ips: ips, trust: trust, cache: cache, secret: key, policy: policy,
+		used: usedNonces{expires: make(map[string]time.Time)}}
+}
+
+// Required reports whether userID must solve a challenge to vote: when
+// challenges are enabled, users that don't exist yet and non-VIP users
+// whose trust score is below the threshold must.
+func (s *ChallengeService) Required(ctx context.Context, userID string) (bool, error) {
+	if !s.policy.Enabled {
+		return false, nil
+	}
+	user, err := s.users.FindByUserID(ctx, userID)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return true, nil
+	}
+	if err != nil {
+		return false, err
+	}
+	return !user.IsVIP && s.trust.ComputeTrustScore(user) < s.policy.TrustThreshold, nil
+}
+
+// Issue returns a challenge for userID's vote on videoID from ip, or a
+// response with Required false if the user needn't solve one.
+func (s *ChallengeService) Issue(ctx context.Context, userID, videoID, ip string) (*model.ChallengeResponse, error) {
+	required, err := s.Required(ctx, userID)
+	if err != nil || !required {
+		return &model.ChallengeResponse{}, err
+	}
+
+	ipHash := s.ips.Hash(ip)
+	rate, err := s.ips.VoteRate(ctx, ipHash)
+	if err != nil {
+		return nil, err
+	}
+	difficulty := s.Difficulty(rate)
+
+	nonce := make([]byte, 16)
+	if _, err := rand.Read(nonce); err != nil {
+		return nil, err
+	}
+	expiresAt := time.Now().Add(s.policy.TTL).Truncate(time.Second)
+	return &model.ChallengeResponse{
+		Required:   true,
+		Challenge:  s.sign(difficulty, expiresAt, hex.EncodeToString(nonce), userID, videoID, ipHash),
+		Difficulty: difficulty,
+		ExpiresAt:  &expiresAt,
+	}, nil
+}
+
+// Verify checks the challenge of a vote from the IP hash ipHash, if the
+// user must solve one, and claims it. Returns ErrChallengeRequired if the
+// vote has none and ErrChallengeInvalid if it doesn't hold.
+func (s *ChallengeService) Verify(ctx context.Context, req model.VoteRequest, ipHash string) error {
+	required, err := s.Required(ctx, req.UserID)
+	if err != nil || !required {
+		return err
+	}
+	if req.Challenge == "" || req.Solution == "" {
+		return ErrChallengeRequired
+	}
+
+	nonce, expiresAt, err := s.check(req.Challenge, req.Solution, req.UserID, req.VideoID, ipHash, time.Now())
+	if err != nil {
+		return err
+	}
+	if !s.claim(ctx, nonce, expiresAt) {
+		return ErrChallengeInvalid
+	}
+	return nil
+}
+
+// claim marks a nonce as used until expiresAt and reports whether it was
+// unused, in Redis if available and in memory otherwise.
+func (s *ChallengeService) claim(ctx context.Context, nonce string, expiresAt time.Time) bool {
+	if s.cache != nil && s.cache.Client() != nil {
+		unused, err := s.cache.ClaimChallenge(ctx, nonce, time.Until(expiresAt))
+		if err == nil {
+			return unused
+		}
+		log.Printf("cache: claim challenge error: %v", err)
+	}
+	return s.used.claim(nonce, expiresAt, time.Now())
+}
+
+// Difficulty returns the number of leading zero bits a solution needs when
+// the client's IP hash votes rate times per hour: one more than the base
+// for each doubling of the rate past the velocity step, up to the maximum.
+func (s *ChallengeService) Difficulty(rate float64) int {
+	d := s.policy.BaseDifficulty
+	if s.policy.VelocityStep > 0 && rate > 0 {
+		d += int(math.Log2(1 + rate/s.policy.VelocityStep))
+	}
+	return min(d, s.policy.MaxDifficulty)
+}
+
+// sign builds the challenge "<difficulty>.<expiry>.<nonce>.<mac>", where the
+// MAC also covers the vote it was issued for.
+func (s *ChallengeService) sign(difficulty int, expiresAt time.Time, nonce, userID, videoID, ipHash string) string {
+	payload := fmt.Sprintf("%d.%d.%s", difficulty, expiresAt.Unix(), nonce)
+	return payload + "." + s.mac(payload, userID, videoID, ipHash)
+}
+
+func (s *ChallengeService) mac(payload, userID, videoID, ipHash string) string {
+	m := hmac.New(sha256.New, s.secret)
+	m.Write([]byte(payload + "|" + userID + "|" + videoID + "|" + ipHash))
+	return hex.EncodeToString(m.Sum(nil))
+}
+
+// check verifies that challenge was signed for the vote, has not expired at
+// now and is solved by solution. Returns its nonce and expiry.
+func (s *ChallengeService) check(challenge, solution, userID, videoID, ipHash string,
+	now time.Time) (string, time.Time, error) {
+	parts := strings.Split(challenge, ".")
+	if len(parts) != 4 {
+		return "", time.Time{}, ErrChallengeInvalid
+	}
+	payload := strings.Join(parts[:3], ".")
+	if !hmac.Equal([]byte(parts[3]), []byte(s.mac(payload, userID, videoID, ipHash))) {
+		return "", time.Time{}, ErrChallengeInvalid
+	}
+
+	difficulty, err := strconv.Atoi(parts[0])
+	if err != nil {
+		return "", time.Time{}, ErrChallengeInvalid
+	}
+	expiry, err := strconv.ParseInt(parts[1], 10, 64)
+	if err != nil {
+		return "", time.Time{}, ErrChallengeInvalid
+	}
+	expiresAt := time.Unix(expiry, 0)
+	if !now.Before(expiresAt) {
+		return "", time.Time{}, ErrChallengeInvalid
+	}
+
+	if leadingZeroBits(sha256.Sum256([]byte(challenge+":"+solution))) < difficulty {
+		return "", time.Time{}, ErrChallengeInvalid
+	}
+	return parts[2], expiresAt, nil
+}
+
+// usedNonces remembers the claimed nonces of unexpired challenges.
+type usedNonces struct {
+	mu        sync.Mutex
+	expires   map[string]time.Time
+	nextSweep time.Time
+}
+
+// claim marks nonce as used until expiresAt and reports whether it was
+// unused at now. Expired nonces are swept at most once a minute.
+func (u *usedNonces) claim(nonce string, expiresAt, now time.Time) bool {
+	u.mu.Lock()
+	defer u.mu.Unlock()
+
+	if now.After(u.nextSweep) {
+		for n, exp := range u.expires {
+			if !now.Before(exp) {
+				delete(u.expires, n)
+			}
+		}
+		u.nextSweep = now.Add(time.Minute)
+	}
+
+	if exp, ok := u.expires[nonce]; ok && now.Before(exp) {
+		return false
+	}
+	u.expires[nonce] = expiresAt
+	return true
+}
+
+// leadingZeroBits counts the zero bits at the start of a digest.
+func leadingZeroBits(digest [sha256.Size]byte) int {
+	n := 0
+	for _, b := range digest {
+		if b != 0 {
+			return n + bits.LeadingZeros8(b)
+		}
+		n += 8
+	}
+	return n
+}
@@ -0,0 +1,153 @@
+package service
+
+import (
+	"context"
+	"crypto/sha256"
+	"errors"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/mathieu-neron/RealTube/realtube-go/internal/model"
+)
+
+var testChallengePolicy = model.ChallengePolicy{
+	Enabled:        true,
+	BaseDifficulty: 16,
+	MaxDifficulty:  24,
+	VelocityStep:   10,
+	TTL:            5 * time.Minute,
+}
+
+// solve brute-forces a solution to challenge.
+func solve(t *testing.T, challenge string, difficulty int) string {
+	t.Helper()
+	for i := 0; i < 1<<20; i++ {
+		solution := strconv.Itoa(i)
+		if leadingZeroBits(sha256.Sum256([]byte(challenge+":"+solution))) >= difficulty {
+			return solution
+		}
+	}
+	t.Fatalf("no solution found for %q", challenge)
+	return ""
+}
+
+func TestChallengeService_Check(t *testing.T) {
+	s := NewChallengeService(nil, nil, nil, nil, "secret", testChallengePolicy)
+	now := time.Now()
+	expiresAt := now.Add(5 * time.Minute).Truncate(time.Second)
+	challenge := s.sign(8, expiresAt, "0123abcd", "user1", "video1", "iphash1")
+	solution := solve(t, challenge, 8)
+
+	nonce, gotExpiry, err := s.check(challenge, solution, "user1", "video1", "iphash1", now)
+	if err != nil {
+		t.Fatalf("check() error = %v", err)
+	}
+	if nonce != "0123abcd" || !gotExpiry.Equal(expiresAt) {
+		t.Errorf("check() = %q, %v, want %q, %v", nonce, gotExpiry, "0123abcd", expiresAt)
+	}
+
+	other := NewChallengeService(nil, nil, nil, nil, "other-secret", testChallengePolicy)
+	tests := []struct {
+		name      string
+		svc       *ChallengeService
+		challenge string
+		solution  string
+		userID    string
+		videoID   string
+		ipHash    string
+		now       time.Time
+	}{
+		{"other user", s, challenge, solution, "user2", "video1", "iphash1", now},
+		{"other video", s, challenge, solution, "user1", "video2", "iphash1", now},
+		{"other IP", s, challenge, solution, "user1", "video1", "iphash2", now},
+		{"other secret", other, challenge, solution, "user1", "video1", "iphash1", now},
+		{"expired", s, challenge, solution, "user1", "video1", "iphash1", expiresAt},
+		{"lowered difficulty", s, "0" + challenge[1:], solution, "user1", "video1", "iphash1", now},
+		{"malformed", s, "8.123.abcd", solution, "user1", "video1", "iphash1", now},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, _, err := tt.svc.check(tt.challenge, tt.solution, tt.userID, tt.videoID, tt.ipHash, tt.now)
+			if !errors.Is(err, ErrChallengeInvalid) {
+				t.Errorf("check() error = %v, want ErrChallengeInvalid", err)
+			}
+		})
+	}
+
+	// A wrong solution almost never has 8 leading zero bits; find one that doesn't
+	for i := 0; ; i++ {
+		wrong := "x" + strconv.Itoa(i)
+		if leadingZeroBits(sha256.Sum256([]byte(challenge+":"+wrong))) < 8 {
+			if _, _, err := s.check(challenge, wrong, "user1", "video1", "iphash1", now); !errors.Is(err, ErrChallengeInvalid) {
+				t.Errorf("check() with unsolved challenge error = %v, want ErrChallengeInvalid", err)
+			}
+			break
+		}
+	}
+}
+
+func TestChallengeService_ClaimWithoutCache(t *testing.T) {
+	s := NewChallengeService(nil, nil, nil, nil, "secret", testChallengePolicy)
+	ctx := context.Background()
+	expiresAt := time.Now().Add(time.Minute)
+
+	if !s.claim(ctx, "nonce1", expiresAt) {
+		t.Fatal("first claim should succeed")
+	}
+	if s.claim(ctx, "nonce1", expiresAt) {
+		t.Fatal("second claim of the same nonce should fail")
+	}
+	if !s.claim(ctx, "nonce2", expiresAt) {
+		t.Fatal("claim of another nonce should succeed")
+	}
+}
+
+func TestUsedNonces_Expiry(t *testing.T) {
+	u := usedNonces{expires: make(map[string]time.Time)}
+	now := time.Now()
+
+	u.claim("nonce", now.Add(time.Minute), now)
+	if u.claim("nonce", now.Add(time.Minute), now.Add(30*time.Second)) {
+		t.Fatal("nonce should stay claimed until it expires")
+	}
+
+	// Expired nonces are swept, and may not be replayed anyway since the
+	// challenge itself has expired
+	later := now.Add(2 * time.Minute)
+	u.claim("other", later.Add(time.Minute), later)
+	if _, ok := u.expires["nonce"]; ok {
+		t.Error("expired nonce should have been swept")
+	}
+}
+
+func TestChallengeService_Difficulty(t *testing.T) {
+	s := NewChallengeService(nil, nil, nil, nil, "secret", testChallengePolicy)
+	tests := []struct {
+		rate float64
+		want int
+	}{
+		{0, 16},
+		{9, 16},
+		{10, 17},
+		{30, 18},
+		{70, 19},
+		{1e6, 24},
+	}
+	for _, tt := range tests {
+		if got := s.Difficulty(tt.rate); got != tt.want {
+			t.Errorf("Difficulty(%v) = %d, want %d", tt.rate, got, tt.want)
+		}
+	}
+}
+
+func TestLeadingZeroBits(t *testing.T) {
+	var digest [sha256.Size]byte
+	if got := leadingZeroBits(digest); got != 256 {
+		t.Errorf("all zero: got %d, want 256", got)
+	}
+	digest[2] = 0x10
+	if got := leadingZeroBits(digest); got != 19 {
+		t.Errorf("got %d, want 19", got)
+	}
+}
@@ -41,12 +41,23 @@ func (s *ChannelService) Lookup(ctx context.Context, channelID string) (*model.C
 		return nil, err
 	}
 
-	topCats, err := s.repo.GetTopCategories(ctx, channelID)
+	cats, err := s.repo.GetCategories(ctx, channelID)
 	if err != nil {
 		return nil, err
 	}
-	if topCats == nil {
-		topCats = []string{}
+
+	// Categories are ordered by weighted score; dissent votes are part of
+	// the breakdown but never a top category.
+	topCats := []string{}
+	categories := make(map[string]*model.ChannelCategoryDetail, len(cats))
+	for _, c := range cats {
+		categories[c.Category] = &model.ChannelCategoryDetail{
+			FlaggedVideos: c.FlaggedVideos,
+			WeightedScore: c.WeightedScore,
+		}
+		if c.Category != repository.CategoryNotAI {
+			topCats = append(topCats, c.Category)
+		}
 	}
 
 	resp := &model.ChannelResponse{
@@ -55,7 +66,9 @@ func (s *ChannelService) Lookup(ctx context.Context, channelID string) (*model.C
 		TotalVideos:   ch.TotalVideos,
 		FlaggedVideos: ch.FlaggedVideos,
 		TopCategories: topCats,
+		Categories:    categories,
 		Locked:        ch.Locked,
+		Disputed:      ch.Disputed,
 		LastUpdated:   ch.LastUpdated.Format(time.RFC3339),
 	}
 
@@ -3,6 +3,7 @@ package service
 import (
 	"testing"
 
+	"github.com/mathieu-neron/RealTube/realtube-go/internal/model"
 	"github.com/mathieu-neron/RealTube/realtube-go/internal/repository"
 )
 
@@ -66,3 +67,84 @@ func TestChannelScore_NoFlaggedVideos(t *testing.T) {
 		t.Errorf("score = %.2f, want 0.00 (no flagged videos)", score)
 	}
 }
+
+func TestAutoFlagThresholds(t *testing.T) {
+	tests := []struct {
+		name    string
+		score   float64
+		flagged int
+		want    bool
+	}{
+		{"both thresholds met", 85.0, 25, true},
+		{"exactly at thresholds", 80.0, 20, true},
+		{"score too low", 79.99, 25, false},
+		{"too few flagged videos", 95.0, 19, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := repository.DefaultAutoFlagThresholds.ShouldAutoFlag(tt.score, tt.flagged)
+			if got != tt.want {
+				t.Errorf("ShouldAutoFlag(%.2f, %d) = %t, want %t", tt.score, tt.flagged, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestTopChannelCategory(t *testing.T) {
+	tests := []struct {
+		name       string
+		categories []model.ChannelCategory
+		want       string // "" means nil
+	}{
+		{"no categories", nil, ""},
+		{
+			"highest weighted score wins",
+			[]model.ChannelCategory{
+				{Category: "ai_voiceover", WeightedScore: 30.0},
+				{Category: "fully_ai", WeightedScore: 55.5},
+				{Category: "ai_visuals", WeightedScore: 12.0},
+			},
+			"fully_ai",
+		},
+		{
+			"dissent is never the top category",
+			[]model.ChannelCategory{
+				{Category: "not_ai", WeightedScore: 80.0},
+				{Category: "ai_thumbnails", WeightedScore: 5.0},
+			},
+			"ai_thumbnails",
+		},
+		{
+			"zero scores have no top category",
+			[]model.ChannelCategory{
+				{Category: "fully_ai", WeightedScore: 0},
+				{Category: "not_ai", WeightedScore: 40.0},
+			},
+			"",
+		},
+		{
+			"ties go to the first category by name",
+			[]model.ChannelCategory{
+				{Category: "fully_ai", WeightedScore: 20.0},
+				{Category: "ai_visuals", WeightedScore: 20.0},
+			},
+			"ai_visuals",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := TopChannelCategory(tt.categories)
+			if tt.want == "" {
+				if got != nil {
+					t.Errorf("TopChannelCategory() = %q, want nil", *got)
+				}
+				return
+			}
+			if got == nil || *got != tt.want {
+				t.Errorf("TopChannelCategory() = %v, want %q", got, tt.want)
+			}
+		})
+	}
+}
@@ -2,43 +2,72 @@ package service
 
 import (
 	"context"
+	"errors"
 	"log"
+	"math"
 	"time"
 
+	"github.com/jackc/pgx/v5"
 	"github.com/jackc/pgx/v5/pgxpool"
+
+	"github.com/mathieu-neron/RealTube/realtube-go/internal/model"
+	"github.com/mathieu-neron/RealTube/realtube-go/internal/repository"
 )
 
-// ChannelWorker is a periodic background job that recalculates channel scores
-// and sets auto_flag_new when thresholds are met (trust-system-design.md §10).
+// Maximum number of queued channels claimed per drain.
+const channelDrainBatchSize = 500
+
+// PreliminaryScore is the provisional score of videos nobody has voted on
+// from channels with auto_flag_new set (trust-system-design.md §10).
+const PreliminaryScore = 60.0
+
+// ChannelWorker recalculates channel scores and sets auto_flag_new when
+// thresholds are met (trust-system-design.md §10).
+//
+// Channels are recalculated shortly after their videos are rescored: the
+// score worker queues them in channel_recalc_queue, and the worker drains the
+// queue every drainInterval, so a channel rescored many times within one
+// window is recalculated once. A full sweep of every channel runs every
+// sweepInterval as a safety net.
 type ChannelWorker struct {
-	pool     *pgxpool.Pool
-	interval time.Duration
-	stopCh   chan struct{}
+	pool          *pgxpool.Pool
+	cache         *CacheService
+	drainInterval time.Duration
+	sweepInterval time.Duration
+	stopCh        chan struct{}
 }
 
-// NewChannelWorker creates a worker that ticks every interval.
-func NewChannelWorker(pool *pgxpool.Pool, interval time.Duration) *ChannelWorker {
+// NewChannelWorker creates a worker that drains the channel queue every
+// drainInterval and sweeps all channels every sweepInterval.
+func NewChannelWorker(pool *pgxpool.Pool, cache *CacheService, drainInterval, sweepInterval time.Duration) *ChannelWorker {
 	return &ChannelWorker{
-		pool:     pool,
-		interval: interval,
-		stopCh:   make(chan struct{}),
+		pool:          pool,
+		cache:         cache,
+		drainInterval: drainInterval,
+		sweepInterval: sweepInterval,
+		stopCh:        make(chan struct{}),
 	}
 }
 
-// Start begins the periodic channel score recalculation loop.
-// It runs one tick immediately, then every interval.
+// Start begins the channel recalculation loop.
+// It runs one full sweep immediately, then drains the queue every
+// drainInterval and sweeps again every sweepInterval.
 func (w *ChannelWorker) Start(ctx context.Context) {
-	log.Printf("channel-worker: starting (interval=%s)", w.interval)
+	log.Printf("channel-worker: starting (drain interval=%s, sweep interval=%s)", w.drainInterval, w.sweepInterval)
 
 	// Run once immediately on startup
 	w.tick(ctx)
 
-	ticker := time.NewTicker(w.interval)
-	defer ticker.Stop()
+	drainTicker := time.NewTicker(w.drainInterval)
+	defer drainTicker.Stop()
+	sweepTicker := time.NewTicker(w.sweepInterval)
+	defer sweepTicker.Stop()
 
 	for {
 		select {
-		case <-ticker.C:
+		case <-drainTicker.C:
+			w.drain(ctx)
+		case <-sweepTicker.C:
 			w.tick(ctx)
 		case <-ctx.Done():
 			log.Println("channel-worker: stopping (context cancelled)")
@@ -55,7 +84,7 @@ func (w *ChannelWorker) Stop() {
 	close(w.stopCh)
 }
 
-// tick runs one cycle: recalculate all channel scores and update auto_flag_new.
+// tick runs one full sweep: recalculate all channel scores and update auto_flag_new.
 func (w *ChannelWorker) tick(ctx context.Context) {
 	start := time.Now()
 
@@ -66,10 +95,87 @@ func (w *ChannelWorker) tick(ctx context.Context) {
 	}
 
 	elapsed := time.Since(start)
-	log.Printf("channel-worker: tick complete — %d channels updated, %d auto-flagged, %d preliminary scores set (%s)",
+	log.Printf("channel-worker: sweep complete — %d channels updated, %d auto-flagged, %d preliminary scores set (%s)",
 		updated, autoFlagged, preliminary, elapsed.Round(time.Millisecond))
 }
 
+// drain recalculates the channels queued since the last drain, up to
+// channelDrainBatchSize. Each channel is removed from the queue in the
+// transaction that recalculates it, so it stays queued if the recalculation
+// fails or the worker dies midway; failed channels are retried by the next
+// drain.
+func (w *ChannelWorker) drain(ctx context.Context) {
+	start := time.Now()
+
+	updated := 0
+	failed := []string{} // not nil: skipped with <> ALL($1)
+	for updated+len(failed) < channelDrainBatchSize && ctx.Err() == nil {
+		chID, err := w.drainOne(ctx, failed)
+		if err != nil && chID == "" {
+			log.Printf("channel-worker: drain error: %v", err)
+			break
+		}
+		if err != nil {
+			log.Printf("channel-worker: error recalculating %s: %v", chID, err)
+			failed = append(failed, chID)
+			continue
+		}
+		if chID == "" {
+			break
+		}
+		updated++
+	}
+
+	if updated+len(failed) == 0 {
+		return
+	}
+	elapsed := time.Since(start)
+	log.Printf("channel-worker: drain complete — %d of %d queued channels updated (%s)",
+		updated, updated+len(failed), elapsed.Round(time.Millisecond))
+}
+
+// drainOne claims the oldest queued channel not in skip and recalculates it
+// in one transaction. Rows locked by another drain are skipped. A channel
+// queued again while it is being recalculated is picked up by the next
+// drain. Returns the channel's ID, "" if the queue is empty, and an error
+// with an empty ID if the claim failed.
+func (w *ChannelWorker) drainOne(ctx context.Context, skip []string) (string, error) {
+	tx, err := w.pool.Begin(ctx)
+	if err != nil {
+		return "", err
+	}
+	defer tx.Rollback(ctx)
+
+	var channelID string
+	err = tx.QueryRow(ctx, `
+		DELETE FROM channel_recalc_queue
+		WHERE channel_id = (
+			SELECT channel_id FROM channel_recalc_queue
+			WHERE channel_id <> ALL($1)
+			ORDER BY queued_at
+			LIMIT 1
+			FOR UPDATE SKIP LOCKED
+		)
+		RETURNING channel_id`,
+		skip).Scan(&channelID)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return "", nil
+	}
+	if err != nil {
+		return "", err
+	}
+
+	res, err := w.recalculateChannel(ctx, tx, channelID)
+	if err != nil {
+		return channelID, err
+	}
+	if err := tx.Commit(ctx); err != nil {
+		return channelID, err
+	}
+	w.invalidate(ctx, channelID, res.videoIDs)
+	return channelID, nil
+}
+
 // recalculateAll recalculates scores for all channels with tracked videos.
 func (w *ChannelWorker) recalculateAll(ctx context.Context) (updated, autoFlagged, preliminary int, err error) {
 	// Get all distinct channel IDs that have at least one video with votes
@@ -95,86 +201,221 @@ func (w *ChannelWorker) recalculateAll(ctx context.Context) (updated, autoFlagge
 	}
 
 	for _, chID := range channelIDs {
-		wasAutoFlagged, prelimCount, err := w.recalculateChannel(ctx, chID)
+		var res channelResult
+		err := inTx(ctx, w.pool, func(tx pgx.Tx) (err error) {
+			res, err = w.recalculateChannel(ctx, tx, chID)
+			return err
+		})
 		if err != nil {
 			log.Printf("channel-worker: error recalculating %s: %v", chID, err)
 			continue
 		}
+		w.invalidate(ctx, chID, res.videoIDs)
 		updated++
-		if wasAutoFlagged {
+		if res.autoFlagged {
 			autoFlagged++
 		}
-		preliminary += prelimCount
+		preliminary += res.preliminary
 	}
 
 	return updated, autoFlagged, preliminary, nil
 }
 
-// recalculateChannel recalculates a single channel's score and auto-flag status.
-func (w *ChannelWorker) recalculateChannel(ctx context.Context, channelID string) (autoFlagged bool, preliminaryCount int, err error) {
+// channelResult is the outcome of a channel recalculation.
+type channelResult struct {
+	autoFlagged bool
+	preliminary int      // Videos newly marked provisional
+	videoIDs    []string // Videos whose provisional score was set or taken back
+}
+
+// recalculateChannel recalculates a single channel's score and auto-flag
+// status within tx.
+func (w *ChannelWorker) recalculateChannel(ctx context.Context, tx pgx.Tx, channelID string) (channelResult, error) {
 	// Compute channel stats from its videos
 	var flagged, tracked int
 	var avgFlaggedScore float64
-	err = w.pool.QueryRow(ctx, `
+	err := tx.QueryRow(ctx, `
 		SELECT
-			COUNT(*) FILTER (WHERE score >= 50)          AS flagged_videos,
-			COUNT(*) FILTER (WHERE total_votes > 0)      AS total_tracked_videos,
-			COALESCE(AVG(score) FILTER (WHERE score >= 50), 0) AS avg_flagged_score
+			COUNT(*) FILTER (WHERE score >= 50 AND NOT provisional) AS flagged_videos,
+			COUNT(*) FILTER (WHERE total_votes > 0)                 AS total_tracked_videos,
+			COALESCE(AVG(score) FILTER (WHERE score >= 50 AND NOT provisional), 0) AS avg_flagged_score
 		FROM videos
 		WHERE channel_id = $1`, channelID).Scan(&flagged, &tracked, &avgFlaggedScore)
 	if err != nil {
-		return false, 0, err
+		return channelResult{}, err
 	}
 
 	// Ensure channel exists
-	_, err = w.pool.Exec(ctx, `
+	_, err = tx.Exec(ctx, `
 		INSERT INTO channels (channel_id) VALUES ($1)
 		ON CONFLICT (channel_id) DO NOTHING`, channelID)
 	if err != nil {
-		return false, 0, err
+		return channelResult{}, err
 	}
 
-	// Compute channel score
-	var channelScore float64
-	if tracked >= 3 {
-		channelScore = (float64(flagged) / float64(tracked)) * avgFlaggedScore
-		// Round to 2 decimal places
-		channelScore = float64(int(channelScore*100+0.5)) / 100
-	}
+	channelScore := repository.ComputeChannelScorePure(flagged, tracked, avgFlaggedScore)
 
 	// Determine auto_flag_new status (§10):
 	//   channel_score >= 80 AND flagged_videos >= 20 AND NOT locked
 	var locked bool
-	err = w.pool.QueryRow(ctx, `SELECT locked FROM channels WHERE channel_id = $1`, channelID).Scan(&locked)
+	err = tx.QueryRow(ctx, `SELECT locked FROM channels WHERE channel_id = $1`, channelID).Scan(&locked)
 	if err != nil {
-		return false, 0, err
+		return channelResult{}, err
 	}
 
-	shouldAutoFlag := channelScore >= 80 && flagged >= 20 && !locked
+	shouldAutoFlag := repository.DefaultAutoFlagThresholds.ShouldAutoFlag(channelScore, flagged) && !locked
 
-	// Update channel record
-	_, err = w.pool.Exec(ctx, `
-		UPDATE channels
-		SET score = $1, flagged_videos = $2, total_videos = $3,
-		    auto_flag_new = $4, last_updated = NOW()
-		WHERE channel_id = $5`,
-		channelScore, flagged, tracked, shouldAutoFlag, channelID)
+	categories, err := w.categoryStats(ctx, tx, channelID, tracked)
 	if err != nil {
-		return false, 0, err
+		return channelResult{}, err
 	}
 
-	// Apply preliminary score to new videos from auto-flagged channels
+	// Update channel record and replace its category breakdown
+	if err := w.saveChannel(ctx, tx, channelID, channelScore, flagged, tracked, shouldAutoFlag, categories); err != nil {
+		return channelResult{}, err
+	}
+
+	videoIDs, err := w.applyPreliminary(ctx, tx, channelID, shouldAutoFlag)
+	if err != nil {
+		return channelResult{}, err
+	}
+	res := channelResult{autoFlagged: shouldAutoFlag, videoIDs: videoIDs}
 	if shouldAutoFlag {
-		tag, err := w.pool.Exec(ctx, `
+		res.preliminary = len(videoIDs)
+	}
+	return res, nil
+}
+
+// invalidate drops the cached lookups of a recalculated channel and of its
+// videos whose provisional score changed.
+func (w *ChannelWorker) invalidate(ctx context.Context, channelID string, videoIDs []string) {
+	if w.cache == nil {
+		return
+	}
+	if err := w.cache.InvalidateChannel(ctx, channelID); err != nil {
+		log.Printf("channel-worker: cache invalidate error for %s: %v", channelID, err)
+	}
+	for _, videoID := range videoIDs {
+		if err := w.cache.InvalidateVideo(ctx, videoID); err != nil {
+			log.Printf("channel-worker: cache invalidate error for %s: %v", videoID, err)
+		}
+	}
+}
+
+// applyPreliminary gives the channel's vote-less videos the provisional
+// PreliminaryScore within tx if the channel is auto-flagged, and takes it
+// back otherwise. It returns the IDs of the videos changed.
+// Unknown videos get the same score at lookup time (VideoService).
+func (w *ChannelWorker) applyPreliminary(ctx context.Context, tx pgx.Tx, channelID string, autoFlag bool) ([]string, error) {
+	query := `
+		UPDATE videos
+		SET score = 0, provisional = FALSE, last_updated = NOW(), score_changed_at = NOW()
+		WHERE channel_id = $1 AND provisional
+		RETURNING video_id`
+	args := []any{channelID}
+	if autoFlag {
+		query = `
 			UPDATE videos
-			SET score = 60, last_updated = NOW()
-			WHERE channel_id = $1 AND total_votes = 0 AND score = 0`,
-			channelID)
-		if err != nil {
-			return true, 0, err
+			SET score = $2, provisional = TRUE, last_updated = NOW(), score_changed_at = NOW()
+			WHERE channel_id = $1 AND total_votes = 0 AND score = 0 AND NOT provisional AND NOT locked
+			RETURNING video_id`
+		args = append(args, PreliminaryScore)
+	}
+
+	rows, err := tx.Query(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	return pgx.CollectRows(rows, pgx.RowTo[string])
+}
+
+// categoryStats aggregates the weighted scores of a channel's tracked videos
+// per category (see migration 009 for the definitions).
+func (w *ChannelWorker) categoryStats(ctx context.Context, tx pgx.Tx, channelID string, tracked int) ([]model.ChannelCategory, error) {
+	rows, err := tx.Query(ctx, `
+		SELECT vc.category,
+		       COUNT(*) FILTER (WHERE vc.weighted_score >= $2) AS flagged_videos,
+		       COALESCE(SUM(vc.weighted_score), 0)             AS score_sum
+		FROM video_categories vc
+		JOIN videos v ON v.video_id = vc.video_id
+		WHERE v.channel_id = $1 AND v.total_votes > 0
+		GROUP BY vc.category`,
+		channelID, FlagThreshold)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var categories []model.ChannelCategory
+	for rows.Next() {
+		c := model.ChannelCategory{ChannelID: channelID}
+		var sum float64
+		if err := rows.Scan(&c.Category, &c.FlaggedVideos, &sum); err != nil {
+			return nil, err
+		}
+		if tracked > 0 {
+			c.WeightedScore = math.Round(sum/float64(tracked)*100) / 100
 		}
-		preliminaryCount = int(tag.RowsAffected())
+		categories = append(categories, c)
+	}
+	return categories, rows.Err()
+}
+
+// saveChannel writes a channel's scores, auto-flag status, category
+// breakdown and top category within tx.
+func (w *ChannelWorker) saveChannel(ctx context.Context, tx pgx.Tx, channelID string, score float64, flagged, tracked int,
+	autoFlag bool, categories []model.ChannelCategory) error {
+	_, err := tx.Exec(ctx, `
+		UPDATE channels
+		SET score = $1, flagged_videos = $2, total_videos = $3,
+		    auto_flag_new = $4, top_category = $5, last_updated = NOW()
+		WHERE channel_id = $6`,
+		score, flagged, tracked, autoFlag, TopChannelCategory(categories), channelID)
+	if err != nil {
+		return err
+	}
+
+	_, err = tx.Exec(ctx, `DELETE FROM channel_categories WHERE channel_id = $1`, channelID)
+	if err != nil {
+		return err
+	}
+
+	if len(categories) == 0 {
+		return nil
 	}
+	names := make([]string, len(categories))
+	counts := make([]int32, len(categories))
+	scores := make([]float64, len(categories))
+	for i, c := range categories {
+		names[i] = c.Category
+		counts[i] = int32(c.FlaggedVideos)
+		scores[i] = c.WeightedScore
+	}
+	_, err = tx.Exec(ctx, `
+		INSERT INTO channel_categories (channel_id, category, flagged_videos, weighted_score)
+		SELECT $1, d.category, d.flagged_videos, d.weighted_score
+		FROM unnest($2::text[], $3::int[], $4::float8[]) AS d(category, flagged_videos, weighted_score)`,
+		channelID, names, counts, scores)
+	return err
+}
 
-	return shouldAutoFlag, preliminaryCount, nil
+// TopChannelCategory returns the AI category with the highest weighted score,
+// or nil if no AI category has a positive score. Dissent votes are never the
+// top category; ties go to the alphabetically first category.
+func TopChannelCategory(categories []model.ChannelCategory) *string {
+	var top *model.ChannelCategory
+	for i := range categories {
+		c := &categories[i]
+		if c.Category == repository.CategoryNotAI || c.WeightedScore <= 0 {
+			continue
+		}
+		if top == nil || c.WeightedScore > top.WeightedScore ||
+			(c.WeightedScore == top.WeightedScore && c.Category < top.Category) {
+			top = c
+		}
+	}
+	if top == nil {
+		return nil
+	}
+	return &top.Category
 }
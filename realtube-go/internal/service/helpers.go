@@ -0,0 +1,48 @@
+package service
+
+import (
+	"context"
+
+	"github.com/jackc/pgx/v5"
+)
+
+// txBeginner is satisfied by the repositories that start their own
+// transactions.
+type txBeginner interface {
+	Begin(ctx context.Context) (pgx.Tx, error)
+}
+
+// inTx runs apply in a transaction of repo, committed if apply succeeds.
+func inTx(ctx context.Context, repo txBeginner, apply func(pgx.Tx) error) error {
+	tx, err := repo.Begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback(ctx)
+
+	if err := apply(tx); err != nil {
+		return err
+	}
+	return tx.Commit(ctx)
+}
+
+// fetchPage returns a page of at most limit items from fetch, and the ID of
+// its last item as the cursor of the next page, or 0 on the last page. The
+// page is never nil, so it encodes as an empty JSON array.
+func fetchPage[T any](limit int, fetch func(limit int) ([]T, error), id func(*T) int64) ([]T, int64, error) {
+	// Fetch one extra item to know whether there is a next page
+	items, err := fetch(limit + 1)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	var next int64
+	if len(items) > limit {
+		items = items[:limit]
+		next = id(&items[limit-1])
+	}
+	if items == nil {
+		items = []T{}
+	}
+	return items, next, nil
+}
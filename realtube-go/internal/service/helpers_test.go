@@ -0,0 +1,41 @@
+package service
+
+import "testing"
+
+func TestFetchPage(t *testing.T) {
+	rows := []int64{1, 2, 3, 4, 5}
+	fetch := func(limit int) ([]int64, error) {
+		return rows[:min(limit, len(rows))], nil
+	}
+	id := func(v *int64) int64 { return *v }
+
+	tests := []struct {
+		name     string
+		limit    int
+		wantLen  int
+		wantNext int64
+	}{
+		{"first page", 2, 2, 2},
+		{"exact fit", 5, 5, 0},
+		{"last page", 10, 5, 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			page, next, err := fetchPage(tt.limit, fetch, id)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(page) != tt.wantLen || next != tt.wantNext {
+				t.Errorf("fetchPage(%d) = %d items, next %d, want %d items, next %d",
+					tt.limit, len(page), next, tt.wantLen, tt.wantNext)
+			}
+		})
+	}
+}
+
+func TestFetchPage_Empty(t *testing.T) {
+	page, next, err := fetchPage(10, func(int) ([]int64, error) { return nil, nil }, func(v *int64) int64 { return *v })
+	if err != nil || page == nil || len(page) != 0 || next != 0 {
+		t.Errorf("fetchPage() = %v, %d, %v, want an empty non-nil page", page, next, err)
+	}
+}
@@ -0,0 +1,88 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"log"
+
+	"github.com/mathieu-neron/RealTube/realtube-go/internal/repository"
+	"github.com/mathieu-neron/RealTube/realtube-go/pkg/hash"
+)
+
+// ErrIPRateLimited is returned for votes from a rate-limited IP hash.
+var ErrIPRateLimited = errors.New("ip rate limited")
+
+// IPHashService hashes client IPs with a secret salt, so that raw IPs are
+// never stored, and enforces the daily vote limit of each IP hash.
+//
+// The salt can be rotated by moving the current salt to the previous salt
+// and setting a new one. New votes are hashed with the new salt only; while
+// the previous salt is set, a rate limit on the previous hash of an IP still
+// applies. Hashes made with an older salt can no longer be linked to new
+// ones.
+type IPHashService struct {
+	repo         *repository.IPHashRepo
+	salt         string
+	previousSalt string
+	voteLimit    int
+}
+
+// NewIPHashService creates the service. voteLimit is the number of votes an
+// IP hash may cast in 24 hours before it is rate limited; 0 disables the
+// limit.
+func NewIPHashService(repo *repository.IPHashRepo, salt, previousSalt string, voteLimit int) *IPHashService {
+	return &IPHashService{repo: repo, salt: salt, previousSalt: previousSalt, voteLimit: voteLimit}
+}
+
+// Hash returns the salted hash of ip.
+func (s *IPHashService) Hash(ip string) string {
+	return hash.HashIP(ip, s.salt)
+}
+
+// RecordVote counts a vote attempt by userID from ip, whose hash is ipHash,
+// in ip_hashes. Returns ErrIPRateLimited if the IP is rate limited.
+func (s *IPHashService) RecordVote(ctx context.Context, ip, ipHash, userID string) error {
+	limited, err := s.repo.RecordVote(ctx, ipHash, userID, s.voteLimit)
+	if err != nil {
+		return err
+	}
+	if !limited && s.previousSalt != "" {
+		limited, err = s.repo.IsRateLimited(ctx, hash.HashIP(ip, s.previousSalt))
+		if err != nil {
+			return err
+		}
+	}
+	if limited {
+		return ErrIPRateLimited
+	}
+	return nil
+}
+
+// VoteRate returns the recent votes per hour from an IP hash.
+func (s *IPHashService) VoteRate(ctx context.Context, ipHash string) (float64, error) {
+	return s.repo.VoteRate(ctx, ipHash)
+}
+
+// HashStoredIPs replaces the raw IPs that earlier versions stored in
+// votes.ip_hash with their salted hashes, batchSize distinct IPs at a time.
+// Returns the number of votes rewritten.
+func (s *IPHashService) HashStoredIPs(ctx context.Context, batchSize int) (int64, error) {
+	var total int64
+	for {
+		ips, err := s.repo.RawVoteIPs(ctx, batchSize)
+		if err != nil || len(ips) == 0 {
+			return total, err
+		}
+
+		hashes := make(map[string]string, len(ips))
+		for _, ip := range ips {
+			hashes[ip] = s.Hash(ip)
+		}
+		n, err := s.repo.ReplaceVoteIPs(ctx, hashes)
+		total += n
+		if err != nil {
+			return total, err
+		}
+		log.Printf("iphash: hashed %d IPs (%d votes so far)", len(ips), total)
+	}
+}
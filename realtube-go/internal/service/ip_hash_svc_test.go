@@ -0,0 +1,27 @@
+package service
+
+import (
+	"regexp"
+	"testing"
+)
+
+// storedHash is the form iphash leaves alone in votes.ip_hash.
+var storedHash = regexp.MustCompile(`^[0-9a-f]{64}$`)
+
+func TestIPHashService_Hash(t *testing.T) {
+	s := NewIPHashService(nil, "salt-a", "", 0)
+	rotated := NewIPHashService(nil, "salt-b", "salt-a", 0)
+
+	for _, ip := range []string{"203.0.113.7", "2001:db8::1"} {
+		h := s.Hash(ip)
+		if !storedHash.MatchString(h) {
+			t.Errorf("Hash(%q) = %q, not a 64-char hex digest", ip, h)
+		}
+		if h != s.Hash(ip) {
+			t.Errorf("Hash(%q) is not stable", ip)
+		}
+		if h == rotated.Hash(ip) {
+			t.Errorf("Hash(%q) did not change with the salt", ip)
+		}
+	}
+}
@@ -0,0 +1,123 @@
+package service
+
+import (
+	"context"
+	"hash/fnv"
+	"log"
+	"sync"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// LeaderElector runs background jobs in only one replica at a time.
+//
+// The leader holds a session-level Postgres advisory lock on a dedicated
+// connection for as long as it runs the jobs. If the leader process dies or
+// loses its database connection, Postgres releases the lock and another
+// replica takes over within one retry interval.
+type LeaderElector struct {
+	pool  *pgxpool.Pool
+	name  string
+	key   int64
+	retry time.Duration
+}
+
+// NewLeaderElector creates an elector for the named role. Replicas
+// campaigning for the same name compete for the same lock.
+func NewLeaderElector(pool *pgxpool.Pool, name string, retry time.Duration) *LeaderElector {
+	return &LeaderElector{
+		pool:  pool,
+		name:  name,
+		key:   advisoryLockKey(name),
+		retry: retry,
+	}
+}
+
+// Run campaigns for leadership until ctx is cancelled and, while leader,
+// runs each job in its own goroutine. Jobs must return once their context is
+// cancelled, which happens when leadership is lost or ctx is cancelled.
+func (e *LeaderElector) Run(ctx context.Context, jobs ...func(context.Context)) {
+	log.Printf("leader-election: %s campaigning (retry=%s)", e.name, e.retry)
+
+	for {
+		if err := e.lead(ctx, jobs); err != nil && ctx.Err() == nil {
+			log.Printf("leader-election: %s error: %v", e.name, err)
+		}
+
+		select {
+		case <-time.After(e.retry):
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+// lead tries to take the lock once and, if it succeeds, runs the jobs until
+// leadership is lost, the jobs return, or ctx is cancelled.
+func (e *LeaderElector) lead(ctx context.Context, jobs []func(context.Context)) error {
+	conn, err := e.pool.Acquire(ctx)
+	if err != nil {
+		return err
+	}
+	defer conn.Release() // no-op once hijacked
+
+	var acquired bool
+	err = conn.QueryRow(ctx, `SELECT pg_try_advisory_lock($1)`, e.key).Scan(&acquired)
+	if err != nil || !acquired {
+		return err
+	}
+
+	// The lock belongs to this session: take the connection out of the pool
+	// so it can't be handed to another caller, and close it to step down.
+	lockConn := conn.Hijack()
+	defer lockConn.Close(context.Background())
+
+	log.Printf("leader-election: %s acquired leadership", e.name)
+
+	jobCtx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	var wg sync.WaitGroup
+	for _, job := range jobs {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			job(jobCtx)
+		}()
+	}
+	done := make(chan struct{})
+	go func() {
+		wg.Wait()
+		close(done)
+	}()
+
+	ticker := time.NewTicker(e.retry)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			if err := lockConn.Ping(ctx); err != nil && ctx.Err() == nil {
+				cancel()
+				<-done
+				log.Printf("leader-election: %s lost leadership", e.name)
+				return err
+			}
+		case <-done:
+			log.Printf("leader-election: %s jobs stopped, releasing leadership", e.name)
+			return nil
+		case <-ctx.Done():
+			<-done
+			log.Printf("leader-election: %s stepping down", e.name)
+			return nil
+		}
+	}
+}
+
+// advisoryLockKey maps a role name to a stable advisory lock key.
+func advisoryLockKey(name string) int64 {
+	h := fnv.New64a()
+	h.Write([]byte("realtube:" + name))
+	return int64(h.Sum64())
+}
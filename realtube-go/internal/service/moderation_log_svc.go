@@ -0,0 +1,67 @@
+package service
+
+import (
+	"context"
+	"strconv"
+
+	"github.com/mathieu-neron/RealTube/realtube-go/internal/model"
+	"github.com/mathieu-neron/RealTube/realtube-go/internal/repository"
+	"github.com/mathieu-neron/RealTube/realtube-go/pkg/hash"
+)
+
+// ModerationLogService serves the moderation transparency log from
+// vip_actions.
+type ModerationLogService struct {
+	repo     *repository.ModerationRepo
+	salt     string
+	redacted []string
+}
+
+// NewModerationLogService creates the log service. salt keys the moderator
+// pseudonyms; redacted lists the action types left out of the public log.
+func NewModerationLogService(repo *repository.ModerationRepo, salt string, redacted []string) *ModerationLogService {
+	return &ModerationLogService{repo: repo, salt: salt, redacted: redacted}
+}
+
+// Log returns a page of the log matching f. The public log leaves out the
+// redacted action types whatever the filter asks for.
+func (s *ModerationLogService) Log(ctx context.Context, f model.ModerationLogFilter, public bool) (*model.ModerationLogResponse, error) {
+	if public {
+		f.Exclude = s.redacted
+	}
+
+	actions, next, err := fetchPage(f.Limit, func(limit int) ([]model.VIPAction, error) {
+		f.Limit = limit
+		return s.repo.ListActions(ctx, f)
+	}, func(a *model.VIPAction) int64 { return a.ID })
+	if err != nil {
+		return nil, err
+	}
+
+	resp := &model.ModerationLogResponse{Entries: []model.ModerationLogEntry{}, NextCursor: next}
+	for _, a := range actions {
+		resp.Entries = append(resp.Entries, model.ModerationLogEntry{
+			ID:         a.ID,
+			Moderator:  ModeratorPseudonym(s.salt, &a),
+			ActionType: a.ActionType,
+			TargetType: a.TargetType,
+			TargetID:   a.TargetID,
+			Reason:     a.Reason,
+			Details:    a.Details,
+			CreatedAt:  a.CreatedAt,
+		})
+	}
+	return resp, nil
+}
+
+// ModeratorPseudonym returns the public identifier of the VIP or admin key
+// that took an action. It is keyed with a server-side salt because VIP
+// public user IDs are not secret: without it, anyone could hash known IDs
+// and unmask moderators.
+func ModeratorPseudonym(salt string, a *model.VIPAction) string {
+	actor := "user:" + a.VIPUserID
+	if a.AdminKeyID != 0 {
+		actor = "key:" + strconv.FormatInt(a.AdminKeyID, 10)
+	}
+	return "mod_" + hash.SHA256Hex(salt + "|" + actor)[:12]
+}
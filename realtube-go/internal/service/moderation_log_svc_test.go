@@ -0,0 +1,39 @@
+package service
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/mathieu-neron/RealTube/realtube-go/internal/model"
+)
+
+func TestModeratorPseudonym(t *testing.T) {
+	vip := &model.VIPAction{VIPUserID: "a1b2c3"}
+	otherVIP := &model.VIPAction{VIPUserID: "d4e5f6"}
+	key := &model.VIPAction{AdminKeyID: 7}
+
+	got := ModeratorPseudonym("salt", vip)
+	if !strings.HasPrefix(got, "mod_") || len(got) != len("mod_")+12 {
+		t.Errorf("pseudonym %q, want mod_ followed by 12 hex characters", got)
+	}
+	if strings.Contains(got, vip.VIPUserID) {
+		t.Errorf("pseudonym %q contains the user ID", got)
+	}
+	if again := ModeratorPseudonym("salt", &model.VIPAction{VIPUserID: "a1b2c3", ActionType: model.ActionHide}); again != got {
+		t.Errorf("pseudonym not stable across actions: %q vs %q", again, got)
+	}
+
+	distinct := map[string]string{
+		"vip":       got,
+		"other vip": ModeratorPseudonym("salt", otherVIP),
+		"key":       ModeratorPseudonym("salt", key),
+		"new salt":  ModeratorPseudonym("pepper", vip),
+	}
+	seen := map[string]string{}
+	for name, p := range distinct {
+		if prev, ok := seen[p]; ok {
+			t.Errorf("%s and %s share pseudonym %q", name, prev, p)
+		}
+		seen[p] = name
+	}
+}
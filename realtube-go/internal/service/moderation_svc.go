@@ -0,0 +1,293 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+
+	"github.com/jackc/pgx/v5"
+
+	"github.com/mathieu-neron/RealTube/realtube-go/internal/model"
+	"github.com/mathieu-neron/RealTube/realtube-go/internal/repository"
+)
+
+// VIPLockScore is the score of a video a VIP overrides to an AI category
+// without giving a score (trust-system-design.md §9, Thresholds).
+const VIPLockScore = 95.0
+
+// ErrInvalidOverride is returned for score/category override combinations
+// that contradict each other.
+var ErrInvalidOverride = errors.New("invalid override")
+
+// ModerationService applies moderation actions on behalf of a VIP or an
+// admin key. Every action is written to vip_actions together with the change
+// itself, video changes are appended to sync_cache for delta syncs, and the
+// affected cache entries are invalidated.
+type ModerationService struct {
+	repo     *repository.ModerationRepo
+	users    *repository.UserRepo
+	scoreSvc *ScoreService
+	trust    *TrustService
+	cache    *CacheService
+
+	// after runs in the transaction of each action, once it is recorded
+	after func(ctx context.Context, tx pgx.Tx, a *model.VIPAction) error
+}
+
+func NewModerationService(repo *repository.ModerationRepo, users *repository.UserRepo, scoreSvc *ScoreService,
+	trust *TrustService, cache *CacheService) *ModerationService {
+	return &ModerationService{repo: repo, users: users, scoreSvc: scoreSvc, trust: trust, cache: cache}
+}
+
+// SetVideoLocked locks or unlocks a video. While locked, votes are rejected
+// and rescoring leaves the video alone. Unlocking rescores the video from
+// its votes right away.
+func (s *ModerationService) SetVideoLocked(ctx context.Context, actor *model.Principal, videoID, reason string, locked bool) (*model.VIPAction, error) {
+	a := newVIPAction(actor, model.TargetVideo, videoID, reason, pick(locked, model.ActionLock, model.ActionUnlock))
+	err := s.moderateVideo(ctx, a, func(tx pgx.Tx) error {
+		if err := s.repo.SetVideoLocked(ctx, tx, videoID, locked); err != nil {
+			return err
+		}
+		if locked {
+			return nil
+		}
+		return s.scoreSvc.recalculateScores(ctx, tx, []string{videoID})
+	})
+	if err != nil {
+		return nil, err
+	}
+	return a, nil
+}
+
+// SetVideoHidden hides or unhides a video from lookups and sync.
+func (s *ModerationService) SetVideoHidden(ctx context.Context, actor *model.Principal, videoID, reason string, hidden bool) (*model.VIPAction, error) {
+	a := newVIPAction(actor, model.TargetVideo, videoID, reason, pick(hidden, model.ActionHide, model.ActionUnhide))
+	err := s.moderateVideo(ctx, a, func(tx pgx.Tx) error {
+		return s.repo.SetVideoHidden(ctx, tx, videoID, hidden)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return a, nil
+}
+
+// OverrideScore sets a video's score and locks it.
+func (s *ModerationService) OverrideScore(ctx context.Context, actor *model.Principal, videoID, reason string, score float64) (*model.VIPAction, error) {
+	a := newVIPAction(actor, model.TargetVideo, videoID, reason, model.ActionOverrideScore)
+	err := s.moderateVideo(ctx, a, func(tx pgx.Tx) error {
+		previous, err := s.repo.OverrideVideo(ctx, tx, videoID, score, nil)
+		if err != nil {
+			return err
+		}
+		a.Details, err = json.Marshal(model.ScoreOverride{Score: score, PreviousScore: previous})
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	return a, nil
+}
+
+// OverrideCategory sets a video's category breakdown to a single category
+// and locks it (see CategoryOverride for the resulting scores).
+func (s *ModerationService) OverrideCategory(ctx context.Context, actor *model.Principal, videoID, reason, category string, score *float64) (*model.VIPAction, error) {
+	videoScore, categories, err := CategoryOverride(category, score)
+	if err != nil {
+		return nil, err
+	}
+
+	a := newVIPAction(actor, model.TargetVideo, videoID, reason, model.ActionOverrideCategory)
+	err = s.moderateVideo(ctx, a, func(tx pgx.Tx) error {
+		previous, err := s.repo.OverrideVideo(ctx, tx, videoID, videoScore, categories)
+		if err != nil {
+			return err
+		}
+		a.Details, err = json.Marshal(model.ScoreOverride{Category: category, Score: videoScore, PreviousScore: previous})
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	return a, nil
+}
+
+// SetChannelLocked locks or unlocks a channel. Locked channels are never
+// auto-flagged.
+func (s *ModerationService) SetChannelLocked(ctx context.Context, actor *model.Principal, channelID, reason string, locked bool) (*model.VIPAction, error) {
+	a := newVIPAction(actor, model.TargetChannel, channelID, reason, pick(locked, model.ActionLock, model.ActionUnlock))
+	err := s.moderateChannel(ctx, a, func(tx pgx.Tx) error {
+		return s.repo.SetChannelLocked(ctx, tx, channelID, locked)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return a, nil
+}
+
+// SetChannelHidden hides or unhides a channel from channel lookups and sync.
+func (s *ModerationService) SetChannelHidden(ctx context.Context, actor *model.Principal, channelID, reason string, hidden bool) (*model.VIPAction, error) {
+	a := newVIPAction(actor, model.TargetChannel, channelID, reason, pick(hidden, model.ActionHide, model.ActionUnhide))
+	err := s.moderateChannel(ctx, a, func(tx pgx.Tx) error {
+		return s.repo.SetChannelHidden(ctx, tx, channelID, hidden)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return a, nil
+}
+
+// SetUserShadowbanned shadowbans or unbans a user. A shadowbanned user's
+// votes, past and future, weigh 0: their past votes are re-weighted now and
+// the affected videos are rescored by the ScoreWorker. The user is not told:
+// public user and trust endpoints keep answering as for any other user.
+func (s *ModerationService) SetUserShadowbanned(ctx context.Context, actor *model.Principal, userID, reason string, banned bool) (*model.VIPAction, error) {
+	var banReason *string
+	if banned {
+		banReason = &reason
+	}
+
+	a := newVIPAction(actor, model.TargetUser, userID, reason, pick(banned, model.ActionShadowban, model.ActionUnshadowban))
+	if err := s.updateUserStatus(ctx, a, nil, &banned, banReason); err != nil {
+		return nil, err
+	}
+	return a, nil
+}
+
+// SetUserVIP grants or revokes a user's VIP status. Their past votes are
+// re-weighted to the new base weight now and the affected videos are
+// rescored by the ScoreWorker.
+func (s *ModerationService) SetUserVIP(ctx context.Context, actor *model.Principal, userID, reason string, vip bool) (*model.VIPAction, error) {
+	a := newVIPAction(actor, model.TargetUser, userID, reason, pick(vip, model.ActionGrantVIP, model.ActionRevokeVIP))
+	if err := s.updateUserStatus(ctx, a, &vip, nil, nil); err != nil {
+		return nil, err
+	}
+	return a, nil
+}
+
+// updateUserStatus sets the user's VIP or shadowban flag, re-weights their
+// votes and records the action in one transaction.
+func (s *ModerationService) updateUserStatus(ctx context.Context, a *model.VIPAction, isVIP, isShadowbanned *bool,
+	banReason *string) error {
+	return s.inTx(ctx, a, func(tx pgx.Tx) error {
+		videoIDs, err := s.users.UpdateStatus(ctx, tx, a.TargetID, isVIP, isShadowbanned, banReason, s.trust)
+		if err != nil {
+			return err
+		}
+		a.Details, err = json.Marshal(model.UserStatusDetails{VideosRequeued: len(videoIDs)})
+		return err
+	})
+}
+
+// CategoryOverride returns the video score and category weighted scores of
+// a category override. An AI category gets the given score, VIPLockScore by
+// default, which must flag the video. not_ai marks the video as confirmed
+// human: the score, 0 by default, must stay below FlagThreshold, and not_ai
+// gets the complementary weighted score.
+func CategoryOverride(category string, score *float64) (float64, map[string]float64, error) {
+	if !repository.ValidCategories[category] {
+		return 0, nil, fmt.Errorf("%w: unknown category %q", ErrInvalidOverride, category)
+	}
+
+	if category == repository.CategoryNotAI {
+		videoScore := 0.0
+		if score != nil {
+			videoScore = *score
+		}
+		if videoScore >= FlagThreshold {
+			return 0, nil, fmt.Errorf("%w: a not_ai override needs a score below %.0f", ErrInvalidOverride, FlagThreshold)
+		}
+		return videoScore, map[string]float64{category: 100 - videoScore}, nil
+	}
+
+	videoScore := VIPLockScore
+	if score != nil {
+		videoScore = *score
+	}
+	if videoScore < FlagThreshold {
+		return 0, nil, fmt.Errorf("%w: an AI category override needs a score of at least %.0f", ErrInvalidOverride, FlagThreshold)
+	}
+	return videoScore, map[string]float64{category: videoScore}, nil
+}
+
+// moderateVideo runs apply and records the action and a sync change in one
+// transaction, then invalidates the video's cache entry.
+func (s *ModerationService) moderateVideo(ctx context.Context, a *model.VIPAction, apply func(pgx.Tx) error) error {
+	err := s.inTx(ctx, a, func(tx pgx.Tx) error {
+		if err := apply(tx); err != nil {
+			return err
+		}
+		return s.repo.RecordSyncChange(ctx, tx, a.TargetID)
+	})
+	if err != nil {
+		return err
+	}
+
+	if s.cache != nil {
+		if err := s.cache.InvalidateVideo(ctx, a.TargetID); err != nil {
+			log.Printf("cache: invalidate video error: %v", err)
+		}
+	}
+	return nil
+}
+
+// moderateChannel runs apply and records the action in one transaction,
+// then invalidates the channel's cache entry. Channel changes reach delta
+// syncs through channels.last_updated.
+func (s *ModerationService) moderateChannel(ctx context.Context, a *model.VIPAction, apply func(pgx.Tx) error) error {
+	if err := s.inTx(ctx, a, apply); err != nil {
+		return err
+	}
+
+	if s.cache != nil {
+		if err := s.cache.InvalidateChannel(ctx, a.TargetID); err != nil {
+			log.Printf("cache: invalidate channel error: %v", err)
+		}
+	}
+	return nil
+}
+
+// withAfter returns a copy of the service whose actions also run after in
+// their transaction, once the action is recorded. Appeal resolutions use it
+// to commit the resolution together with the moderation action.
+func (s *ModerationService) withAfter(after func(ctx context.Context, tx pgx.Tx, a *model.VIPAction) error) *ModerationService {
+	c := *s
+	c.after = after
+	return &c
+}
+
+// inTx runs apply and records the action in one transaction.
+func (s *ModerationService) inTx(ctx context.Context, a *model.VIPAction, apply func(pgx.Tx) error) error {
+	return inTx(ctx, s.repo, func(tx pgx.Tx) error {
+		if err := apply(tx); err != nil {
+			return err
+		}
+		if err := s.repo.RecordAction(ctx, tx, a); err != nil {
+			return err
+		}
+		if s.after != nil {
+			return s.after(ctx, tx, a)
+		}
+		return nil
+	})
+}
+
+func newVIPAction(actor *model.Principal, targetType, targetID, reason, actionType string) *model.VIPAction {
+	return &model.VIPAction{
+		VIPUserID:  actor.UserID,
+		AdminKeyID: actor.KeyID,
+		ActionType: actionType,
+		TargetType: targetType,
+		TargetID:   targetID,
+		Reason:     reason,
+	}
+}
+
+// pick returns on if cond is true and off otherwise.
+func pick(cond bool, on, off string) string {
+	if cond {
+		return on
+	}
+	return off
+}
@@ -0,0 +1,48 @@
+package service
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestCategoryOverride(t *testing.T) {
+	score := func(v float64) *float64 { return &v }
+
+	tests := []struct {
+		name       string
+		category   string
+		score      *float64
+		wantScore  float64
+		wantWeight float64
+		wantErr    bool
+	}{
+		{"AI category defaults to VIP lock score", "fully_ai", nil, VIPLockScore, VIPLockScore, false},
+		{"AI category with score", "ai_voiceover", score(72), 72, 72, false},
+		{"AI category at flag threshold", "ai_visuals", score(FlagThreshold), FlagThreshold, FlagThreshold, false},
+		{"AI category below flag threshold", "ai_assisted", score(49.9), 0, 0, true},
+		{"not_ai defaults to confirmed human", "not_ai", nil, 0, 100, false},
+		{"not_ai with low score", "not_ai", score(20), 20, 80, false},
+		{"not_ai at flag threshold", "not_ai", score(FlagThreshold), 0, 0, true},
+		{"unknown category", "deepfake", nil, 0, 0, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, categories, err := CategoryOverride(tt.category, tt.score)
+			if tt.wantErr {
+				if !errors.Is(err, ErrInvalidOverride) {
+					t.Fatalf("err = %v, want ErrInvalidOverride", err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if got != tt.wantScore {
+				t.Errorf("score = %.2f, want %.2f", got, tt.wantScore)
+			}
+			if len(categories) != 1 || categories[tt.category] != tt.wantWeight {
+				t.Errorf("categories = %v, want only %s = %.2f", categories, tt.category, tt.wantWeight)
+			}
+		})
+	}
+}
@@ -0,0 +1,45 @@
+package service
+
+import (
+	"context"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+
+	"github.com/mathieu-neron/RealTube/realtube-go/internal/model"
+)
+
+// OpsService reports operational state for the read-only ops endpoints.
+type OpsService struct {
+	pool *pgxpool.Pool
+}
+
+func NewOpsService(pool *pgxpool.Pool) *OpsService {
+	return &OpsService{pool: pool}
+}
+
+// QueueStats returns the depth and the age of the oldest entry of the score
+// and channel recalculation queues.
+func (s *OpsService) QueueStats(ctx context.Context) ([]model.QueueStats, error) {
+	rows, err := s.pool.Query(ctx, `
+		SELECT 'score_recalc_queue', COUNT(*),
+		       COALESCE(EXTRACT(EPOCH FROM NOW() - MIN(queued_at)), 0)::float8
+		FROM score_recalc_queue
+		UNION ALL
+		SELECT 'channel_recalc_queue', COUNT(*),
+		       COALESCE(EXTRACT(EPOCH FROM NOW() - MIN(queued_at)), 0)::float8
+		FROM channel_recalc_queue`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var stats []model.QueueStats
+	for rows.Next() {
+		var q model.QueueStats
+		if err := rows.Scan(&q.Name, &q.Depth, &q.OldestAgeSeconds); err != nil {
+			return nil, err
+		}
+		stats = append(stats, q)
+	}
+	return stats, rows.Err()
+}
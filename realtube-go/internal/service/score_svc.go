@@ -2,10 +2,18 @@ package service
 
 import (
 	"context"
+	"time"
 
+	"github.com/jackc/pgx/v5"
 	"github.com/jackc/pgx/v5/pgxpool"
 )
 
+// scoreChangeEpsilon is how far a rescore must move a video's score, in
+// points, to count as a change of score (videos.score_changed_at). Smaller
+// moves, like those from trust re-weighting, don't unsettle the video
+// unless they cross FlagThreshold.
+const scoreChangeEpsilon = 1.0
+
 // CategoryScore holds the weighted score for a single category.
 type CategoryScore struct {
 	Category      string
@@ -15,142 +23,264 @@ type CategoryScore struct {
 
 // ScoreService recalculates video and category scores after vote changes.
 type ScoreService struct {
-	pool *pgxpool.Pool
+	pool     *pgxpool.Pool
+	strategy ScoringStrategy
+}
+
+func NewScoreService(pool *pgxpool.Pool, strategy ScoringStrategy) *ScoreService {
+	return &ScoreService{pool: pool, strategy: strategy}
 }
 
-func NewScoreService(pool *pgxpool.Pool) *ScoreService {
-	return &ScoreService{pool: pool}
+// Strategy returns the scoring strategy in use.
+func (s *ScoreService) Strategy() ScoringStrategy {
+	return s.strategy
 }
 
 // RecalculateVideoScore computes per-category weighted scores and the overall
-// video score for a given video. The algorithm:
-//
-//	For each category C:
-//	  C_score = (sum of trust_weight for votes in C) / (sum of trust_weight for ALL votes) * 100
-//	  video.score = max(C_score for all categories)
+// video score for a given video using the configured ScoringStrategy, and
+// persists them.
 func (s *ScoreService) RecalculateVideoScore(ctx context.Context, videoID string) error {
+	return s.RecalculateVideoScores(ctx, []string{videoID})
+}
+
+// RecalculateVideoScores recalculates and persists the scores of a batch of
+// videos in one transaction, with one query to load the category weights and
+// one UPDATE each for videos and video_categories. Videos without counted
+// votes, and categories that no longer have votes, are reset to 0. Videos
+// locked by a VIP or frozen by a brigade are skipped. The videos' channels
+// are queued in channel_recalc_queue.
+func (s *ScoreService) RecalculateVideoScores(ctx context.Context, videoIDs []string) error {
+	if len(videoIDs) == 0 {
+		return nil
+	}
+
 	tx, err := s.pool.Begin(ctx)
 	if err != nil {
 		return err
 	}
 	defer tx.Rollback(ctx)
 
-	// Get total trust weight across all votes for this video
-	var totalWeight float64
-	err = tx.QueryRow(ctx, `
-		SELECT COALESCE(SUM(trust_weight), 0) FROM votes WHERE video_id = $1`,
-		videoID).Scan(&totalWeight)
-	if err != nil {
+	if err := s.recalculateScores(ctx, tx, videoIDs); err != nil {
 		return err
 	}
+	return tx.Commit(ctx)
+}
 
-	// No votes → reset score to 0
-	if totalWeight == 0 {
-		_, err = tx.Exec(ctx, `UPDATE videos SET score = 0, last_updated = NOW() WHERE video_id = $1`, videoID)
-		if err != nil {
-			return err
-		}
-		_, err = tx.Exec(ctx, `UPDATE video_categories SET weighted_score = 0 WHERE video_id = $1`, videoID)
-		if err != nil {
-			return err
-		}
-		return tx.Commit(ctx)
+// recalculateScores rescores the unlocked, unfrozen videos of videoIDs
+// within tx.
+func (s *ScoreService) recalculateScores(ctx context.Context, tx pgx.Tx, videoIDs []string) error {
+	videoIDs, err := lockUnlockedVideos(ctx, tx, videoIDs)
+	if err != nil || len(videoIDs) == 0 {
+		return err
 	}
 
-	// Get per-category trust weight sums
-	rows, err := tx.Query(ctx, `
-		SELECT category, COALESCE(SUM(trust_weight), 0) AS weight_sum
-		FROM votes
-		WHERE video_id = $1
-		GROUP BY category`,
-		videoID)
+	weights, err := batchCategoryWeights(ctx, tx, videoIDs)
 	if err != nil {
 		return err
 	}
-	defer rows.Close()
+	return s.writeScores(ctx, tx, videoIDs, weights)
+}
 
-	var maxScore float64
-	var categories []CategoryScore
+// preSpikeScores returns the scores of the videos of videoIDs counting only
+// their votes cast up to the matching cutoff, before a vote spike.
+func (s *ScoreService) preSpikeScores(ctx context.Context, tx pgx.Tx, videoIDs []string,
+	cutoffs []time.Time) (map[string]float64, error) {
+	weights, err := batchCategoryWeightsBefore(ctx, tx, videoIDs, cutoffs)
+	if err != nil {
+		return nil, err
+	}
+	scores := make(map[string]float64, len(videoIDs))
+	for _, videoID := range videoIDs {
+		scores[videoID] = s.Score(weights[videoID])
+	}
+	return scores, nil
+}
 
-	for rows.Next() {
-		var cs CategoryScore
-		if err := rows.Scan(&cs.Category, &cs.WeightSum); err != nil {
-			return err
-		}
-		cs.WeightedScore = (cs.WeightSum / totalWeight) * 100
-		if cs.WeightedScore > maxScore {
-			maxScore = cs.WeightedScore
-		}
-		categories = append(categories, cs)
+// freezeScores rescores the videos of videoIDs within tx counting only their
+// votes cast up to the matching cutoff, so that videos frozen by a brigade
+// keep their pre-spike score.
+func (s *ScoreService) freezeScores(ctx context.Context, tx pgx.Tx, videoIDs []string, cutoffs []time.Time) error {
+	if len(videoIDs) == 0 {
+		return nil
 	}
-	if err := rows.Err(); err != nil {
+	weights, err := batchCategoryWeightsBefore(ctx, tx, videoIDs, cutoffs)
+	if err != nil {
 		return err
 	}
+	return s.writeScores(ctx, tx, videoIDs, weights)
+}
 
-	// Update per-category weighted scores
-	for _, cs := range categories {
-		_, err = tx.Exec(ctx, `
-			UPDATE video_categories SET weighted_score = $1
-			WHERE video_id = $2 AND category = $3`,
-			cs.WeightedScore, videoID, cs.Category)
-		if err != nil {
-			return err
+// writeScores scores the videos of videoIDs from their category weights and
+// persists the video and category scores within tx. The videos' channels
+// are queued in channel_recalc_queue.
+func (s *ScoreService) writeScores(ctx context.Context, tx pgx.Tx, videoIDs []string,
+	weights map[string][]CategoryScore) error {
+	scores := make([]float64, len(videoIDs))
+	var catVideoIDs, catNames []string
+	var catScores []float64
+	for i, videoID := range videoIDs {
+		categories := weights[videoID]
+		scores[i] = s.Score(categories)
+		for _, cs := range categories {
+			catVideoIDs = append(catVideoIDs, videoID)
+			catNames = append(catNames, cs.Category)
+			catScores = append(catScores, cs.WeightedScore)
 		}
 	}
 
-	// Update overall video score (max across categories)
-	_, err = tx.Exec(ctx, `UPDATE videos SET score = $1, last_updated = NOW() WHERE video_id = $2`,
-		maxScore, videoID)
+	// Videos first, then categories: the same lock order as SubmitVote.
+	_, err := tx.Exec(ctx, `
+		UPDATE videos v
+		SET score = d.score, provisional = FALSE, last_updated = NOW(),
+		    score_changed_at = CASE
+		        WHEN ABS(v.score - d.score) >= $3 OR (v.score >= $4) <> (d.score >= $4) THEN NOW()
+		        ELSE v.score_changed_at
+		    END
+		FROM unnest($1::text[], $2::float8[]) AS d(video_id, score)
+		WHERE v.video_id = d.video_id`,
+		videoIDs, scores, scoreChangeEpsilon, FlagThreshold)
 	if err != nil {
 		return err
 	}
 
-	return tx.Commit(ctx)
+	_, err = tx.Exec(ctx, `
+		WITH d AS (
+			SELECT * FROM unnest($2::text[], $3::text[], $4::float8[]) AS d(video_id, category, score)
+		)
+		UPDATE video_categories vc
+		SET weighted_score = COALESCE(d.score, 0)
+		FROM video_categories cur
+		LEFT JOIN d ON d.video_id = cur.video_id AND d.category = cur.category
+		WHERE cur.video_id = ANY($1)
+		  AND vc.video_id = cur.video_id AND vc.category = cur.category`,
+		videoIDs, catVideoIDs, catNames, catScores)
+	if err != nil {
+		return err
+	}
+
+	// Queue the videos' channels for the channel worker
+	_, err = tx.Exec(ctx, `
+		INSERT INTO channel_recalc_queue (channel_id)
+		SELECT DISTINCT channel_id FROM videos
+		WHERE video_id = ANY($1) AND channel_id IS NOT NULL
+		ORDER BY channel_id
+		ON CONFLICT (channel_id) DO NOTHING`,
+		videoIDs)
+	return err
+}
+
+// lockUnlockedVideos row-locks the videos of videoIDs that are not locked by
+// a VIP or frozen by a brigade and returns their IDs. Holding the row locks
+// until the end of the transaction keeps a concurrent VIP lock from being
+// overwritten.
+func lockUnlockedVideos(ctx context.Context, tx pgx.Tx, videoIDs []string) ([]string, error) {
+	rows, err := tx.Query(ctx, `
+		SELECT video_id FROM videos
+		WHERE video_id = ANY($1) AND NOT locked AND NOT frozen
+		ORDER BY video_id
+		FOR UPDATE`,
+		videoIDs)
+	if err != nil {
+		return nil, err
+	}
+	return pgx.CollectRows(rows, pgx.RowTo[string])
 }
 
 // ComputeCategoryScores returns the per-category scores for a video without
 // persisting them. Used for testing and read-only queries.
 func (s *ScoreService) ComputeCategoryScores(ctx context.Context, videoID string) ([]CategoryScore, float64, error) {
-	var totalWeight float64
-	err := s.pool.QueryRow(ctx, `
-		SELECT COALESCE(SUM(trust_weight), 0) FROM votes WHERE video_id = $1`,
-		videoID).Scan(&totalWeight)
+	categories, err := categoryWeights(ctx, s.pool, videoID)
 	if err != nil {
 		return nil, 0, err
 	}
 
-	if totalWeight == 0 {
+	if totalWeight(categories) == 0 {
 		return nil, 0, nil
 	}
 
-	rows, err := s.pool.Query(ctx, `
+	return categories, s.Score(categories), nil
+}
+
+// Score fills the weighted score of each category and returns the overall
+// video score, without touching the database. Videos whose votes carry no
+// weight score 0.
+func (s *ScoreService) Score(categories []CategoryScore) float64 {
+	if totalWeight(categories) == 0 {
+		for i := range categories {
+			categories[i].WeightedScore = 0
+		}
+		return 0
+	}
+	return s.strategy.Score(categories)
+}
+
+// querier is satisfied by both *pgxpool.Pool and pgx.Tx.
+type querier interface {
+	Query(ctx context.Context, sql string, args ...any) (pgx.Rows, error)
+}
+
+// batchCategoryWeights returns the per-category trust weight sums of each
+// video in videoIDs. Videos without votes are absent from the map.
+func batchCategoryWeights(ctx context.Context, q querier, videoIDs []string) (map[string][]CategoryScore, error) {
+	return collectCategoryWeights(q.Query(ctx, `
+		SELECT video_id, category, COALESCE(SUM(trust_weight), 0) AS weight_sum
+		FROM votes
+		WHERE video_id = ANY($1)
+		GROUP BY video_id, category`,
+		videoIDs))
+}
+
+// batchCategoryWeightsBefore is batchCategoryWeights counting only the votes
+// of each video cast or changed up to its cutoff in cutoffs.
+func batchCategoryWeightsBefore(ctx context.Context, q querier, videoIDs []string,
+	cutoffs []time.Time) (map[string][]CategoryScore, error) {
+	return collectCategoryWeights(q.Query(ctx, `
+		SELECT vo.video_id, vo.category, COALESCE(SUM(vo.trust_weight), 0) AS weight_sum
+		FROM votes vo
+		JOIN unnest($1::text[], $2::timestamptz[]) AS c(video_id, cutoff) ON c.video_id = vo.video_id
+		WHERE vo.created_at <= c.cutoff
+		GROUP BY vo.video_id, vo.category`,
+		videoIDs, cutoffs))
+}
+
+func collectCategoryWeights(rows pgx.Rows, err error) (map[string][]CategoryScore, error) {
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	weights := make(map[string][]CategoryScore)
+	for rows.Next() {
+		var videoID string
+		var cs CategoryScore
+		if err := rows.Scan(&videoID, &cs.Category, &cs.WeightSum); err != nil {
+			return nil, err
+		}
+		weights[videoID] = append(weights[videoID], cs)
+	}
+	return weights, rows.Err()
+}
+
+// categoryWeights returns the per-category trust weight sums for a video.
+func categoryWeights(ctx context.Context, q querier, videoID string) ([]CategoryScore, error) {
+	rows, err := q.Query(ctx, `
 		SELECT category, COALESCE(SUM(trust_weight), 0) AS weight_sum
 		FROM votes
 		WHERE video_id = $1
 		GROUP BY category`,
 		videoID)
 	if err != nil {
-		return nil, 0, err
+		return nil, err
 	}
 	defer rows.Close()
 
-	var maxScore float64
 	var categories []CategoryScore
-
 	for rows.Next() {
 		var cs CategoryScore
 		if err := rows.Scan(&cs.Category, &cs.WeightSum); err != nil {
-			return nil, 0, err
-		}
-		cs.WeightedScore = (cs.WeightSum / totalWeight) * 100
-		if cs.WeightedScore > maxScore {
-			maxScore = cs.WeightedScore
+			return nil, err
 		}
 		categories = append(categories, cs)
 	}
-	if err := rows.Err(); err != nil {
-		return nil, 0, err
-	}
-
-	return categories, maxScore, nil
+	return categories, rows.Err()
 }
@@ -234,3 +234,15 @@ func TestScoreCalculation_MixedWeights(t *testing.T) {
 		t.Errorf("max score = %.2f, want %.2f", maxScore, math.Max(expectedFullyAI, expectedVoiceover))
 	}
 }
+
+func TestScoreService_ScoreZeroWeight(t *testing.T) {
+	svc := NewScoreService(nil, RatioStrategy{})
+	categories := []CategoryScore{{Category: "fully_ai", WeightSum: 0, WeightedScore: 42}}
+
+	if score := svc.Score(categories); score != 0 {
+		t.Errorf("score = %.2f, want 0 for zero-weight votes", score)
+	}
+	if categories[0].WeightedScore != 0 {
+		t.Errorf("fully_ai score = %.2f, want reset to 0", categories[0].WeightedScore)
+	}
+}
@@ -3,39 +3,75 @@ package service
 import (
 	"context"
 	"log"
-	"sync"
+	"slices"
 	"time"
 
+	"github.com/jackc/pgx/v5"
 	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/prometheus/client_golang/prometheus"
 )
 
-// ScoreWorker listens for PostgreSQL NOTIFY on the 'vote_changes' channel
-// and batches score recalculations. If 50 votes hit video X in 5 seconds,
-// it recalculates once (infrastructure-design.md §14).
+const (
+	// Maximum number of videos recalculated per transaction.
+	scoreRecalcChunkSize = 500
+
+	// The queue is drained at least this often even without notifications,
+	// to catch videos queued while the listener was disconnected.
+	scoreQueueSweepInterval = time.Minute
+)
+
+// ScoreWorkerMetrics receives an observation for every drained batch.
+// Nil observers are skipped.
+type ScoreWorkerMetrics struct {
+	Duration  prometheus.Observer // seconds spent recalculating the batch
+	BatchSize prometheus.Observer // videos in the batch
+}
+
+// ScoreWorker drains the score_recalc_queue table, which the vote_inserted
+// trigger and DeleteVote fill, and recalculates the queued videos in batches.
+// If 50 votes hit video X in 5 seconds, it recalculates once
+// (infrastructure-design.md §14).
+//
+// NOTIFY on the 'vote_changes' channel is only a wake-up hint: queued rows
+// are deleted in the transaction that rescores them, so videos queued while
+// the listener is reconnecting or the server is down are picked up by the
+// startup sweep or the periodic sweep. Rows are claimed with
+// FOR UPDATE SKIP LOCKED, so several workers can drain the queue at once.
+//
+// Before a batch is rescored, BrigadeService checks its videos for vote
+// spikes and freezes them at their current score.
 type ScoreWorker struct {
 	pool     *pgxpool.Pool
 	scoreSvc *ScoreService
+	brigades *BrigadeService
 	cache    *CacheService
 	batchMs  time.Duration
+	metrics  ScoreWorkerMetrics
 
-	mu      sync.Mutex
-	pending map[string]struct{} // video IDs waiting for recalculation
+	wake chan struct{} // signalled when a vote_changes notification arrives
 }
 
 // NewScoreWorker creates a score recalculation worker.
-func NewScoreWorker(pool *pgxpool.Pool, scoreSvc *ScoreService, cache *CacheService) *ScoreWorker {
+func NewScoreWorker(pool *pgxpool.Pool, scoreSvc *ScoreService, brigades *BrigadeService, cache *CacheService,
+	metrics ScoreWorkerMetrics) *ScoreWorker {
 	return &ScoreWorker{
 		pool:     pool,
 		scoreSvc: scoreSvc,
+		brigades: brigades,
 		cache:    cache,
 		batchMs:  5 * time.Second,
-		pending:  make(map[string]struct{}),
+		metrics:  metrics,
+		wake:     make(chan struct{}, 1),
 	}
 }
 
-// Start begins listening for vote_changes notifications and processing batches.
+// Start drains anything left in the queue, then listens for vote_changes
+// notifications and drains the queue in batched windows.
 func (w *ScoreWorker) Start(ctx context.Context) {
-	log.Printf("score-worker: starting (batch window=%s)", w.batchMs)
+	log.Printf("score-worker: starting (batch window=%s, sweep interval=%s, strategy=%s)",
+		w.batchMs, scoreQueueSweepInterval, w.scoreSvc.Strategy().Name())
+
+	go w.drainLoop(ctx)
 
 	for {
 		if err := w.listenLoop(ctx); err != nil {
@@ -55,7 +91,7 @@ func (w *ScoreWorker) Start(ctx context.Context) {
 }
 
 // listenLoop acquires a dedicated connection, LISTENs on vote_changes,
-// and processes notifications in batched windows.
+// and wakes the drain loop on every notification.
 func (w *ScoreWorker) listenLoop(ctx context.Context) error {
 	conn, err := w.pool.Acquire(ctx)
 	if err != nil {
@@ -69,77 +105,174 @@ func (w *ScoreWorker) listenLoop(ctx context.Context) error {
 	}
 	log.Println("score-worker: listening on vote_changes")
 
-	// Start the batch flush goroutine
-	flushCtx, flushCancel := context.WithCancel(ctx)
-	defer flushCancel()
-	go w.flushLoop(flushCtx)
-
 	for {
-		notification, err := conn.Conn().WaitForNotification(ctx)
-		if err != nil {
+		if _, err := conn.Conn().WaitForNotification(ctx); err != nil {
 			return err
 		}
 
-		videoID := notification.Payload
-		if videoID == "" {
-			continue
+		select {
+		case w.wake <- struct{}{}:
+		default: // a wake-up is already pending
 		}
-
-		w.mu.Lock()
-		w.pending[videoID] = struct{}{}
-		w.mu.Unlock()
 	}
 }
 
-// flushLoop periodically drains the pending set and recalculates scores.
-func (w *ScoreWorker) flushLoop(ctx context.Context) {
+// drainLoop sweeps the queue on startup, then drains it at the end of every
+// batch window in which a notification arrived, and at least every
+// scoreQueueSweepInterval.
+func (w *ScoreWorker) drainLoop(ctx context.Context) {
+	w.drain(ctx)
+	lastDrain := time.Now()
+
 	ticker := time.NewTicker(w.batchMs)
 	defer ticker.Stop()
 
 	for {
 		select {
 		case <-ticker.C:
-			w.flush(ctx)
+			woken := false
+			select {
+			case <-w.wake:
+				woken = true
+			default:
+			}
+			if woken || time.Since(lastDrain) >= scoreQueueSweepInterval {
+				w.drain(ctx)
+				lastDrain = time.Now()
+			}
 		case <-ctx.Done():
-			// Final flush before exit
-			w.flush(context.Background())
 			return
 		}
 	}
 }
 
-// flush drains the pending set and recalculates each video's score.
-func (w *ScoreWorker) flush(ctx context.Context) {
-	w.mu.Lock()
-	if len(w.pending) == 0 {
-		w.mu.Unlock()
-		return
-	}
-
-	// Swap out the pending map
-	batch := w.pending
-	w.pending = make(map[string]struct{})
-	w.mu.Unlock()
+// drain recalculates queued videos, scoreRecalcChunkSize per transaction,
+// until the queue is empty. A failed chunk stays queued for the next drain.
+func (w *ScoreWorker) drain(ctx context.Context) {
+	start := time.Now()
 
 	recalculated := 0
-	for videoID := range batch {
-		if err := w.scoreSvc.RecalculateVideoScore(ctx, videoID); err != nil {
-			log.Printf("score-worker: recalculate error for %s: %v", videoID, err)
-			continue
+	for {
+		claimed, videoIDs, err := w.drainChunk(ctx)
+		if err != nil {
+			log.Printf("score-worker: recalculate error: %v", err)
+			break
 		}
+		recalculated += claimed
 
 		// Invalidate Redis cache so next read gets fresh data
 		if w.cache != nil {
-			if err := w.cache.InvalidateVideo(ctx, videoID); err != nil {
-				log.Printf("score-worker: cache invalidate error for %s: %v", videoID, err)
+			for _, videoID := range videoIDs {
+				if err := w.cache.InvalidateVideo(ctx, videoID); err != nil {
+					log.Printf("score-worker: cache invalidate error for %s: %v", videoID, err)
+				}
 			}
 		}
 
-		recalculated++
+		if claimed < scoreRecalcChunkSize || ctx.Err() != nil {
+			break
+		}
+	}
+
+	if recalculated == 0 {
+		return
+	}
+
+	elapsed := time.Since(start)
+	if w.metrics.Duration != nil {
+		w.metrics.Duration.Observe(elapsed.Seconds())
+	}
+	if w.metrics.BatchSize != nil {
+		w.metrics.BatchSize.Observe(float64(recalculated))
+	}
+	log.Printf("score-worker: batch complete — %d videos recalculated (%s)",
+		recalculated, elapsed.Round(time.Millisecond))
+}
+
+// drainChunk claims up to scoreRecalcChunkSize queued videos, rescores them
+// and removes them from the queue in a single transaction. Returns the
+// number of videos claimed and the IDs of the videos whose score changed,
+// which include the videos of a brigaded channel frozen outside the chunk.
+func (w *ScoreWorker) drainChunk(ctx context.Context) (int, []string, error) {
+	tx, err := w.pool.Begin(ctx)
+	if err != nil {
+		return 0, nil, err
+	}
+	defer tx.Rollback(ctx)
+
+	rows, err := tx.Query(ctx, `
+		DELETE FROM score_recalc_queue
+		WHERE video_id IN (
+			SELECT video_id FROM score_recalc_queue
+			ORDER BY queued_at
+			LIMIT $1
+			FOR UPDATE SKIP LOCKED
+		)
+		RETURNING video_id`,
+		scoreRecalcChunkSize)
+	if err != nil {
+		return 0, nil, err
+	}
+
+	var videoIDs []string
+	for rows.Next() {
+		var videoID string
+		if err := rows.Scan(&videoID); err != nil {
+			rows.Close()
+			return 0, nil, err
+		}
+		videoIDs = append(videoIDs, videoID)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return 0, nil, err
+	}
+
+	if len(videoIDs) == 0 {
+		return 0, nil, nil
+	}
+
+	// Sorted IDs keep row lock order stable between concurrent workers
+	slices.Sort(videoIDs)
+	frozen := w.checkBrigades(ctx, tx, videoIDs)
+	if err := w.scoreSvc.recalculateScores(ctx, tx, videoIDs); err != nil {
+		return 0, nil, err
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return 0, nil, err
+	}
+	changed := slices.Clone(videoIDs)
+	for _, videoID := range frozen {
+		if _, found := slices.BinarySearch(videoIDs, videoID); !found {
+			changed = append(changed, videoID)
+		}
+	}
+	return len(videoIDs), changed, nil
+}
+
+// checkBrigades freezes the videos of the batch hit by a vote spike before
+// they are rescored, and returns the IDs of the videos frozen. It runs in a
+// savepoint, so a failed check is logged and the batch is rescored anyway.
+func (w *ScoreWorker) checkBrigades(ctx context.Context, tx pgx.Tx, videoIDs []string) []string {
+	if w.brigades == nil {
+		return nil
+	}
+	sp, err := tx.Begin(ctx)
+	if err != nil {
+		log.Printf("score-worker: brigade check error: %v", err)
+		return nil
 	}
+	defer sp.Rollback(ctx)
 
-	if recalculated > 0 {
-		log.Printf("score-worker: batch complete — %d videos recalculated (from %d notifications)",
-			recalculated, len(batch))
+	_, frozen, err := w.brigades.Check(ctx, sp, videoIDs)
+	if err != nil {
+		log.Printf("score-worker: brigade check error: %v", err)
+		return nil
+	}
+	if err := sp.Commit(ctx); err != nil {
+		log.Printf("score-worker: brigade check error: %v", err)
+		return nil
 	}
+	return frozen
 }
@@ -0,0 +1,122 @@
+package service
+
+import (
+	"fmt"
+	"math"
+
+	"github.com/mathieu-neron/RealTube/realtube-go/internal/repository"
+)
+
+// ScoringStrategy turns per-category trust weight sums into per-category
+// weighted scores (0-100) and the overall video score.
+type ScoringStrategy interface {
+	// Name returns the config identifier of the strategy.
+	Name() string
+	// Score fills WeightedScore on each category from its WeightSum and
+	// returns the overall video score.
+	Score(categories []CategoryScore) float64
+}
+
+// Scoring strategy names accepted by NewScoringStrategy.
+const (
+	ScoringRatio  = "ratio"
+	ScoringWilson = "wilson"
+)
+
+// NewScoringStrategy returns the strategy registered under name.
+// confidenceZ is the z-score used by confidence-aware strategies.
+func NewScoringStrategy(name string, confidenceZ float64) (ScoringStrategy, error) {
+	switch name {
+	case ScoringRatio:
+		return RatioStrategy{}, nil
+	case ScoringWilson:
+		if confidenceZ <= 0 {
+			return nil, fmt.Errorf("wilson scoring requires a positive z-score, got %v", confidenceZ)
+		}
+		return WilsonStrategy{Z: confidenceZ}, nil
+	default:
+		return nil, fmt.Errorf("unknown scoring strategy %q", name)
+	}
+}
+
+// RatioStrategy is the original scoring algorithm (trust-system-design.md §11),
+// with not_ai dissent weight subtracted from every AI category:
+//
+//	C_score = max(C_weight - not_ai_weight, 0) / (sum of trust_weight for ALL votes) * 100
+//	video.score = max(C_score for all AI categories)
+//
+// A single AI vote produces a 100% score.
+type RatioStrategy struct{}
+
+func (RatioStrategy) Name() string { return ScoringRatio }
+
+func (RatioStrategy) Score(categories []CategoryScore) float64 {
+	return scoreCategories(categories, func(support, total float64) float64 {
+		return support / total
+	})
+}
+
+// WilsonStrategy scores each category with the lower bound of the Wilson
+// score interval, treating the summed trust weight as the sample size:
+//
+//	p = max(C_weight - not_ai_weight, 0) / total_weight, n = total_weight
+//	C_score = (p + z²/2n - z·√(p(1-p)/n + z²/4n²)) / (1 + z²/n) * 100
+//
+// Scores converge to the ratio as trusted weight accumulates, while one or
+// two votes stay well below the flag threshold.
+type WilsonStrategy struct {
+	Z float64
+}
+
+func (WilsonStrategy) Name() string { return ScoringWilson }
+
+func (s WilsonStrategy) Score(categories []CategoryScore) float64 {
+	z2 := s.Z * s.Z
+	return scoreCategories(categories, func(support, n float64) float64 {
+		p := support / n
+		lower := (p + z2/(2*n) - s.Z*math.Sqrt(p*(1-p)/n+z2/(4*n*n))) / (1 + z2/n)
+		return math.Max(lower, 0)
+	})
+}
+
+// scoreCategories applies a proportion function to each category and returns
+// the maximum AI category score. AI categories are scored on their support net
+// of not_ai dissent; the not_ai category is scored on its own weight so it
+// shows up in the per-category breakdown, but never raises the video score.
+func scoreCategories(categories []CategoryScore, proportion func(support, total float64) float64) float64 {
+	var total, dissent float64
+	for _, cs := range categories {
+		total += cs.WeightSum
+		if cs.Category == repository.CategoryNotAI {
+			dissent += cs.WeightSum
+		}
+	}
+
+	var maxScore float64
+	for i := range categories {
+		cs := &categories[i]
+		cs.WeightedScore = 0
+		if total <= 0 {
+			continue
+		}
+
+		if cs.Category == repository.CategoryNotAI {
+			cs.WeightedScore = proportion(cs.WeightSum, total) * 100
+			continue
+		}
+
+		if support := cs.WeightSum - dissent; support > 0 {
+			cs.WeightedScore = proportion(support, total) * 100
+		}
+		maxScore = math.Max(maxScore, cs.WeightedScore)
+	}
+	return maxScore
+}
+
+func totalWeight(categories []CategoryScore) float64 {
+	var total float64
+	for _, cs := range categories {
+		total += cs.WeightSum
+	}
+	return total
+}
@@ -0,0 +1,174 @@
+package service
+
+import "testing"
+
+func TestNewScoringStrategy(t *testing.T) {
+	tests := []struct {
+		name     string
+		strategy string
+		z        float64
+		wantName string
+		wantErr  bool
+	}{
+		{"ratio", "ratio", 0, ScoringRatio, false},
+		{"wilson", "wilson", 1.96, ScoringWilson, false},
+		{"wilson without z", "wilson", 0, "", true},
+		{"unknown", "median", 1.96, "", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := NewScoringStrategy(tt.strategy, tt.z)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("NewScoringStrategy(%q) error = %v, wantErr %v", tt.strategy, err, tt.wantErr)
+			}
+			if err == nil && got.Name() != tt.wantName {
+				t.Errorf("Name() = %q, want %q", got.Name(), tt.wantName)
+			}
+		})
+	}
+}
+
+func TestRatioStrategy_MatchesOriginalAlgorithm(t *testing.T) {
+	categories := []CategoryScore{
+		{Category: "fully_ai", WeightSum: 2.0},
+		{Category: "ai_voiceover", WeightSum: 0.5},
+		{Category: "ai_visuals", WeightSum: 0.5},
+	}
+
+	score := RatioStrategy{}.Score(categories)
+
+	if !almostEqual(categories[0].WeightedScore, 66.67, 0.01) {
+		t.Errorf("fully_ai score = %.2f, want ~66.67", categories[0].WeightedScore)
+	}
+	if !almostEqual(categories[1].WeightedScore, 16.67, 0.01) {
+		t.Errorf("ai_voiceover score = %.2f, want ~16.67", categories[1].WeightedScore)
+	}
+	if !almostEqual(score, 66.67, 0.01) {
+		t.Errorf("score = %.2f, want ~66.67", score)
+	}
+}
+
+func TestRatioStrategy_SingleVoteIsFullScore(t *testing.T) {
+	categories := []CategoryScore{{Category: "fully_ai", WeightSum: 0.25}}
+
+	if score := (RatioStrategy{}).Score(categories); score != 100 {
+		t.Errorf("score = %.2f, want 100.00", score)
+	}
+}
+
+func TestWilsonStrategy_FewVotesStayBelowThreshold(t *testing.T) {
+	s := WilsonStrategy{Z: 1.96}
+
+	tests := []struct {
+		name   string
+		weight float64
+	}{
+		{"one new account", 0.25},
+		{"one veteran", 1.0},
+		{"two veterans", 2.0},
+		{"ten new accounts", 2.5},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			categories := []CategoryScore{{Category: "fully_ai", WeightSum: tt.weight}}
+			if score := s.Score(categories); score >= FlagThreshold {
+				t.Errorf("score = %.2f, want < %.0f", score, FlagThreshold)
+			}
+		})
+	}
+}
+
+func TestWilsonStrategy_ConvergesToRatio(t *testing.T) {
+	s := WilsonStrategy{Z: 1.96}
+	categories := []CategoryScore{
+		{Category: "fully_ai", WeightSum: 900},
+		{Category: "ai_voiceover", WeightSum: 100},
+	}
+
+	score := s.Score(categories)
+
+	// Ratio would be 90%; with 1000 units of weight the lower bound is close
+	if score < 87 || score > 90 {
+		t.Errorf("score = %.2f, want within [87, 90]", score)
+	}
+	if categories[1].WeightedScore >= 10 {
+		t.Errorf("ai_voiceover score = %.2f, want < 10 (lower bound)", categories[1].WeightedScore)
+	}
+}
+
+func TestWilsonStrategy_MoreWeightMoreConfidence(t *testing.T) {
+	s := WilsonStrategy{Z: 1.96}
+
+	prev := 0.0
+	for _, w := range []float64{1, 5, 20, 100} {
+		score := s.Score([]CategoryScore{{Category: "fully_ai", WeightSum: w}})
+		if score <= prev {
+			t.Errorf("score(%.0f) = %.2f, want > %.2f", w, score, prev)
+		}
+		prev = score
+	}
+}
+
+func TestScoringStrategies_ZeroWeight(t *testing.T) {
+	for _, s := range []ScoringStrategy{RatioStrategy{}, WilsonStrategy{Z: 1.96}} {
+		categories := []CategoryScore{{Category: "fully_ai", WeightSum: 0}}
+		if score := s.Score(categories); score != 0 {
+			t.Errorf("%s: score = %.2f, want 0 for zero-weight votes", s.Name(), score)
+		}
+	}
+}
+
+func TestRatioStrategy_DissentCountsAgainstAICategories(t *testing.T) {
+	categories := []CategoryScore{
+		{Category: "fully_ai", WeightSum: 3.0},
+		{Category: "ai_voiceover", WeightSum: 1.0},
+		{Category: "not_ai", WeightSum: 1.0},
+	}
+
+	score := RatioStrategy{}.Score(categories)
+
+	// Total weight = 5.0
+	// fully_ai = (3.0 - 1.0) / 5.0 * 100 = 40.0
+	// ai_voiceover = max(1.0 - 1.0, 0) / 5.0 * 100 = 0
+	// not_ai = 1.0 / 5.0 * 100 = 20.0 (reported, not part of the max)
+	if !almostEqual(categories[0].WeightedScore, 40.0, 0.01) {
+		t.Errorf("fully_ai score = %.2f, want 40.00", categories[0].WeightedScore)
+	}
+	if categories[1].WeightedScore != 0 {
+		t.Errorf("ai_voiceover score = %.2f, want 0.00", categories[1].WeightedScore)
+	}
+	if !almostEqual(categories[2].WeightedScore, 20.0, 0.01) {
+		t.Errorf("not_ai score = %.2f, want 20.00", categories[2].WeightedScore)
+	}
+	if !almostEqual(score, 40.0, 0.01) {
+		t.Errorf("score = %.2f, want 40.00", score)
+	}
+}
+
+func TestScoringStrategies_OnlyDissentScoresZero(t *testing.T) {
+	for _, s := range []ScoringStrategy{RatioStrategy{}, WilsonStrategy{Z: 1.96}} {
+		categories := []CategoryScore{{Category: "not_ai", WeightSum: 5.0}}
+		if score := s.Score(categories); score != 0 {
+			t.Errorf("%s: score = %.2f, want 0 when every vote is not_ai", s.Name(), score)
+		}
+		if categories[0].WeightedScore <= 0 {
+			t.Errorf("%s: not_ai score = %.2f, want > 0 in the breakdown", s.Name(), categories[0].WeightedScore)
+		}
+	}
+}
+
+func TestWilsonStrategy_DissentLowersScore(t *testing.T) {
+	s := WilsonStrategy{Z: 1.96}
+
+	undisputed := s.Score([]CategoryScore{{Category: "fully_ai", WeightSum: 20}})
+	disputed := s.Score([]CategoryScore{
+		{Category: "fully_ai", WeightSum: 20},
+		{Category: "not_ai", WeightSum: 5},
+	})
+
+	if disputed >= undisputed {
+		t.Errorf("disputed score %.2f should be below undisputed %.2f", disputed, undisputed)
+	}
+}
@@ -0,0 +1,137 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"strconv"
+
+	"github.com/jackc/pgx/v5"
+
+	"github.com/mathieu-neron/RealTube/realtube-go/internal/model"
+	"github.com/mathieu-neron/RealTube/realtube-go/internal/repository"
+)
+
+// ErrSybilStatus is returned when dampening a cluster that is not pending,
+// or dismissing one that is already dismissed.
+var ErrSybilStatus = errors.New("sybil cluster status does not allow this")
+
+// SybilService finds clusters of user IDs that vote from the same IP hash on
+// the same videos and, once a cluster is dampened, divides its members' vote
+// weight by the cluster size so that together they weigh as one voter.
+// Re-weighted votes fire the vote_changes trigger, which queues their videos
+// for the ScoreWorker. Clusters are dampened on detection if autoDampen is
+// set, and otherwise left pending for a moderator; either way they are
+// listed for moderators, who can dismiss false positives. Moderator
+// decisions are recorded in vip_actions.
+type SybilService struct {
+	repo       *repository.SybilRepo
+	trust      *TrustService
+	detection  model.SybilDetection
+	autoDampen bool
+}
+
+func NewSybilService(repo *repository.SybilRepo, trust *TrustService, detection model.SybilDetection,
+	autoDampen bool) *SybilService {
+	return &SybilService{repo: repo, trust: trust, detection: detection, autoDampen: autoDampen}
+}
+
+// Detect runs one detection pass and records the clusters found, applying
+// the weight split of dampened clusters that are new or gained members.
+// Returns the number of such clusters and of videos queued for rescoring.
+func (s *SybilService) Detect(ctx context.Context) (clusters, videos int, err error) {
+	candidates, err := s.repo.Detect(ctx, s.detection)
+	if err != nil {
+		return 0, 0, err
+	}
+
+	status := pick(s.autoDampen, model.SybilDampened, model.SybilPending)
+	for _, c := range candidates {
+		var changed bool
+		var videoIDs []string
+		err := inTx(ctx, s.repo, func(tx pgx.Tx) error {
+			var id int64
+			var err error
+			id, changed, err = s.repo.Record(ctx, tx, c, status)
+			if err != nil || !changed {
+				return err
+			}
+			videoIDs, err = s.repo.ApplySplits(ctx, tx, id, s.trust)
+			return err
+		})
+		if err != nil {
+			return clusters, videos, err
+		}
+		if changed {
+			clusters++
+			videos += len(videoIDs)
+		}
+	}
+	return clusters, videos, nil
+}
+
+// Get returns a cluster with its members. Returns pgx.ErrNoRows if it
+// doesn't exist.
+func (s *SybilService) Get(ctx context.Context, id int64) (*model.SybilCluster, error) {
+	return s.repo.FindByID(ctx, id)
+}
+
+// List returns a page of clusters.
+func (s *SybilService) List(ctx context.Context, f model.SybilClusterFilter) (*model.SybilClusterResponse, error) {
+	clusters, next, err := fetchPage(f.Limit, func(limit int) ([]model.SybilCluster, error) {
+		f.Limit = limit
+		return s.repo.List(ctx, f)
+	}, func(c *model.SybilCluster) int64 { return c.ID })
+	if err != nil {
+		return nil, err
+	}
+	return &model.SybilClusterResponse{Clusters: clusters, NextCursor: next}, nil
+}
+
+// Dampen splits the weight of a pending cluster between its members.
+func (s *SybilService) Dampen(ctx context.Context, actor *model.Principal, id int64) (*model.SybilReviewResponse, error) {
+	return s.review(ctx, actor, id, model.SybilDampened, model.ActionSybilDampen, model.SybilPending)
+}
+
+// Dismiss marks a pending or dampened cluster as a false positive, such as
+// users behind a shared network, and restores its members' weight. The
+// same users are not clustered again for that IP hash.
+func (s *SybilService) Dismiss(ctx context.Context, actor *model.Principal, id int64) (*model.SybilReviewResponse, error) {
+	return s.review(ctx, actor, id, model.SybilDismissed, model.ActionSybilDismiss, model.SybilPending, model.SybilDampened)
+}
+
+// review moves a cluster to status, re-weights its members' votes and
+// records the moderator's action in one transaction.
+func (s *SybilService) review(ctx context.Context, actor *model.Principal, id int64, status, actionType string,
+	from ...string) (*model.SybilReviewResponse, error) {
+	if _, err := s.repo.FindByID(ctx, id); err != nil {
+		return nil, err
+	}
+
+	var resp model.SybilReviewResponse
+	a := newVIPAction(actor, model.TargetSybilCluster, strconv.FormatInt(id, 10), "", actionType)
+	err := inTx(ctx, s.repo, func(tx pgx.Tx) error {
+		cluster, err := s.repo.SetStatus(ctx, tx, id, status, actor, from...)
+		if errors.Is(err, pgx.ErrNoRows) {
+			return ErrSybilStatus
+		}
+		if err != nil {
+			return err
+		}
+		videoIDs, err := s.repo.ApplySplits(ctx, tx, id, s.trust)
+		if err != nil {
+			return err
+		}
+		resp = model.SybilReviewResponse{Cluster: *cluster, VideosRequeued: len(videoIDs)}
+
+		a.Details, err = json.Marshal(model.SybilReviewDetails{UserCount: cluster.UserCount, VideosRequeued: len(videoIDs)})
+		if err != nil {
+			return err
+		}
+		return s.repo.RecordAction(ctx, tx, a)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &resp, nil
+}
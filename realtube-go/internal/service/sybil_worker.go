@@ -0,0 +1,68 @@
+package service
+
+import (
+	"context"
+	"log"
+	"time"
+)
+
+// SybilWorker is a periodic background job that runs sybil cluster
+// detection with SybilService.
+type SybilWorker struct {
+	svc      *SybilService
+	interval time.Duration
+	stopCh   chan struct{}
+}
+
+// NewSybilWorker creates a worker that ticks every interval.
+func NewSybilWorker(svc *SybilService, interval time.Duration) *SybilWorker {
+	return &SybilWorker{
+		svc:      svc,
+		interval: interval,
+		stopCh:   make(chan struct{}),
+	}
+}
+
+// Start begins the periodic detection loop.
+// It runs one tick immediately, then every interval.
+func (w *SybilWorker) Start(ctx context.Context) {
+	log.Printf("sybil-worker: starting (interval=%s, window=%s, auto-dampen=%t)",
+		w.interval, w.svc.detection.Window, w.svc.autoDampen)
+
+	w.tick(ctx)
+
+	ticker := time.NewTicker(w.interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			w.tick(ctx)
+		case <-ctx.Done():
+			log.Println("sybil-worker: stopping (context cancelled)")
+			return
+		case <-w.stopCh:
+			log.Println("sybil-worker: stopping (stop signal)")
+			return
+		}
+	}
+}
+
+// Stop signals the worker to stop.
+func (w *SybilWorker) Stop() {
+	close(w.stopCh)
+}
+
+// tick runs one detection pass.
+func (w *SybilWorker) tick(ctx context.Context) {
+	start := time.Now()
+
+	clusters, videos, err := w.svc.Detect(ctx)
+	if err != nil {
+		log.Printf("sybil-worker: error: %v", err)
+		return
+	}
+
+	log.Printf("sybil-worker: tick complete — %d clusters new or grown, %d videos requeued (%s)",
+		clusters, videos, time.Since(start).Round(time.Millisecond))
+}
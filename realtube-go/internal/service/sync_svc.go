@@ -25,7 +25,7 @@ func NewSyncService(pool *pgxpool.Pool, videoSvc *VideoService, channelSvc *Chan
 func (s *SyncService) DeltaSync(ctx context.Context, since time.Time) (*model.SyncDeltaResponse, error) {
 	// Fetch changed videos from sync_cache
 	videoQuery := `
-		SELECT video_id, score, categories, channel_id, action
+		SELECT video_id, score, categories, channel_id, disputed, action
 		FROM sync_cache
 		WHERE changed_at > $1
 		ORDER BY changed_at ASC
@@ -42,7 +42,7 @@ func (s *SyncService) DeltaSync(ctx context.Context, since time.Time) (*model.Sy
 		var entry model.SyncVideoEntry
 		var categoriesJSON []byte
 		var channelID *string
-		err := rows.Scan(&entry.VideoID, &entry.Score, &categoriesJSON, &channelID, &entry.Action)
+		err := rows.Scan(&entry.VideoID, &entry.Score, &categoriesJSON, &channelID, &entry.Disputed, &entry.Action)
 		if err != nil {
 			return nil, err
 		}
@@ -62,7 +62,7 @@ func (s *SyncService) DeltaSync(ctx context.Context, since time.Time) (*model.Sy
 
 	// Fetch changed channels (those updated since the given timestamp)
 	channelQuery := `
-		SELECT channel_id, score
+		SELECT channel_id, score, disputed, CASE WHEN hidden THEN 'remove' ELSE 'update' END
 		FROM channels
 		WHERE last_updated > $1
 		ORDER BY last_updated ASC
@@ -77,11 +77,10 @@ func (s *SyncService) DeltaSync(ctx context.Context, since time.Time) (*model.Sy
 	var channels []model.SyncChannelEntry
 	for channelRows.Next() {
 		var entry model.SyncChannelEntry
-		err := channelRows.Scan(&entry.ChannelID, &entry.Score)
+		err := channelRows.Scan(&entry.ChannelID, &entry.Score, &entry.Disputed, &entry.Action)
 		if err != nil {
 			return nil, err
 		}
-		entry.Action = "update"
 		channels = append(channels, entry)
 	}
 	if err := channelRows.Err(); err != nil {
@@ -107,7 +106,7 @@ func (s *SyncService) FullSync(ctx context.Context) (*model.SyncFullResponse, er
 	// Fetch all non-hidden videos with score > 0
 	videoQuery := `
 		SELECT video_id, channel_id, title, score, total_votes, locked, hidden, shadow_hidden,
-		       video_duration, is_short, first_reported, last_updated, service
+		       video_duration, is_short, provisional, disputed, frozen, first_reported, last_updated, service
 		FROM videos
 		WHERE hidden = false AND shadow_hidden = false AND score > 0
 		ORDER BY last_updated DESC
@@ -125,7 +124,7 @@ func (s *SyncService) FullSync(ctx context.Context) (*model.SyncFullResponse, er
 		err := rows.Scan(
 			&v.VideoID, &v.ChannelID, &v.Title, &v.Score, &v.TotalVotes,
 			&v.Locked, &v.Hidden, &v.ShadowHidden,
-			&v.VideoDuration, &v.IsShort, &v.FirstReported, &v.LastUpdated, &v.Service,
+			&v.VideoDuration, &v.IsShort, &v.Provisional, &v.Disputed, &v.Frozen, &v.FirstReported, &v.LastUpdated, &v.Service,
 		)
 		if err != nil {
 			return nil, err
@@ -138,6 +137,9 @@ func (s *SyncService) FullSync(ctx context.Context) (*model.SyncFullResponse, er
 			TotalVotes:  v.TotalVotes,
 			Locked:      v.Locked,
 			ChannelID:   v.ChannelID,
+			Provisional: v.Provisional,
+			Disputed:    v.Disputed,
+			Frozen:      v.Frozen,
 			LastUpdated: v.LastUpdated,
 		})
 	}
@@ -147,9 +149,9 @@ func (s *SyncService) FullSync(ctx context.Context) (*model.SyncFullResponse, er
 
 	// Fetch all channels with score > 0
 	channelQuery := `
-		SELECT channel_id, score, total_videos, flagged_videos, top_category, locked, last_updated
+		SELECT channel_id, score, total_videos, flagged_videos, top_category, locked, disputed, last_updated
 		FROM channels
-		WHERE score > 0
+		WHERE score > 0 AND NOT hidden
 		ORDER BY last_updated DESC
 		LIMIT 50000`
 
@@ -165,7 +167,7 @@ func (s *SyncService) FullSync(ctx context.Context) (*model.SyncFullResponse, er
 		var topCategory *string
 		var lastUpdated time.Time
 		err := channelRows.Scan(&ch.ChannelID, &ch.Score, &ch.TotalVideos,
-			&ch.FlaggedVideos, &topCategory, &ch.Locked, &lastUpdated)
+			&ch.FlaggedVideos, &topCategory, &ch.Locked, &ch.Disputed, &lastUpdated)
 		if err != nil {
 			return nil, err
 		}
@@ -28,10 +28,49 @@ const (
 	BaseWeightShadowbanned = 0.0
 )
 
-type TrustService struct{}
+// TrustDecay configures the inactivity decay of vote weight. Users inactive
+// for longer than GraceDays lose half their weight every HalfLifeDays; when
+// they vote again, the weight recovers linearly over RecoveryDays.
+// A zero HalfLifeDays disables decay.
+type TrustDecay struct {
+	GraceDays    float64
+	HalfLifeDays float64
+	RecoveryDays float64
+}
+
+// TrustWeights are the weights of the age, accuracy and volume factors in
+// the trust score. They are expected to sum to 1.
+type TrustWeights struct {
+	Age      float64
+	Accuracy float64
+	Volume   float64
+}
+
+// DefaultTrustWeights are the factor weights of trust-system-design.md §9.
+var DefaultTrustWeights = TrustWeights{
+	Age:      ageWeight,
+	Accuracy: accuracyWeight,
+	Volume:   volumeWeight,
+}
+
+type TrustService struct {
+	decay   TrustDecay
+	weights TrustWeights
+}
 
-func NewTrustService() *TrustService {
-	return &TrustService{}
+func NewTrustService(decay TrustDecay) *TrustService {
+	return &TrustService{decay: decay, weights: DefaultTrustWeights}
+}
+
+// WithWeights returns a copy of the service that uses the given factor
+// weights. Used to simulate alternate trust parameters.
+func (s *TrustService) WithWeights(weights TrustWeights) *TrustService {
+	return &TrustService{decay: s.decay, weights: weights}
+}
+
+// Weights returns the factor weights in use.
+func (s *TrustService) Weights() TrustWeights {
+	return s.weights
 }
 
 // ComputeTrustScore calculates the trust score for a user based on the algorithm:
@@ -41,7 +80,7 @@ func (s *TrustService) ComputeTrustScore(user *model.User) float64 {
 	accuracyFactor := s.AccuracyFactor(user.AccuracyRate, user.TotalVotes)
 	volumeFactor := s.VolumeFactor(user.TotalVotes)
 
-	score := (ageFactor * ageWeight) + (accuracyFactor * accuracyWeight) + (volumeFactor * volumeWeight)
+	score := (ageFactor * s.weights.Age) + (accuracyFactor * s.weights.Accuracy) + (volumeFactor * s.weights.Volume)
 	return math.Min(score, 1.0)
 }
 
@@ -68,10 +107,61 @@ func (s *TrustService) VolumeFactor(totalVotes int) float64 {
 }
 
 // EffectiveWeight calculates the effective vote weight for a user.
-//   effective_weight = trust_score * base_weight
+//   effective_weight = trust_score * base_weight * activity_factor * sybil_factor
 func (s *TrustService) EffectiveWeight(user *model.User) float64 {
 	baseWeight := s.BaseWeight(user)
-	return s.ComputeTrustScore(user) * baseWeight
+	return s.ComputeTrustScore(user) * baseWeight * s.ActivityFactor(user) * s.SybilFactor(user)
+}
+
+// SybilFactor returns 1/N for a member of a dampened sybil cluster of N user
+// IDs, so that the cluster's votes weigh as much as one user's, and 1.0
+// otherwise.
+func (s *TrustService) SybilFactor(user *model.User) float64 {
+	if user.SybilSplit <= 1 {
+		return 1.0
+	}
+	return 1.0 / float64(user.SybilSplit)
+}
+
+// ActivityFactor returns a value between 0.0 and 1.0 that decays while a
+// user is inactive beyond the grace period, and recovers linearly after a
+// dormant user becomes active again.
+func (s *TrustService) ActivityFactor(user *model.User) float64 {
+	if s.decay.HalfLifeDays <= 0 {
+		return 1.0
+	}
+
+	inactiveDays := time.Since(user.LastActive).Hours() / 24
+	if inactiveDays > s.decay.GraceDays {
+		return s.dormancyDecay(inactiveDays)
+	}
+
+	if user.ReactivatedAt != nil && s.decay.RecoveryDays > 0 {
+		recovered := time.Since(*user.ReactivatedAt).Hours() / 24 / s.decay.RecoveryDays
+		if recovered < 1 {
+			return user.DormancyFactor + (1-user.DormancyFactor)*math.Max(recovered, 0)
+		}
+	}
+	return 1.0
+}
+
+// RecordActivity marks the user as active at now. If the user was dormant,
+// the decay reached so far is frozen as their dormancy factor and recovery
+// starts from now.
+func (s *TrustService) RecordActivity(user *model.User, now time.Time) {
+	if s.decay.HalfLifeDays > 0 {
+		inactiveDays := now.Sub(user.LastActive).Hours() / 24
+		if inactiveDays > s.decay.GraceDays {
+			user.DormancyFactor = s.dormancyDecay(inactiveDays)
+			user.ReactivatedAt = &now
+		}
+	}
+	user.LastActive = now
+}
+
+// dormancyDecay halves the factor every HalfLifeDays past the grace period.
+func (s *TrustService) dormancyDecay(inactiveDays float64) float64 {
+	return math.Pow(0.5, (inactiveDays-s.decay.GraceDays)/s.decay.HalfLifeDays)
 }
 
 // BaseWeight returns the base vote weight multiplier for a user.
@@ -84,3 +174,37 @@ func (s *TrustService) BaseWeight(user *model.User) float64 {
 	}
 	return BaseWeightRegular
 }
+
+// Explain returns the per-factor breakdown of a user's trust score and vote
+// weight, including how far each factor is from its maximum.
+func (s *TrustService) Explain(user *model.User) *model.TrustBreakdownResponse {
+	ageDays := time.Since(user.FirstSeen).Hours() / 24
+
+	return &model.TrustBreakdownResponse{
+		UserID:     user.UserID,
+		TrustScore: roundWeight(s.ComputeTrustScore(user)),
+		AgeFactor: model.TrustAgeFactor{
+			Value:      roundWeight(s.AgeFactor(user.FirstSeen)),
+			Weight:     s.weights.Age,
+			AccountAge: int(math.Floor(ageDays)),
+			DaysToMax:  int(math.Ceil(math.Max(ageDaysMax-ageDays, 0))),
+		},
+		AccuracyFactor: model.TrustAccuracyFactor{
+			Value:          roundWeight(s.AccuracyFactor(user.AccuracyRate, user.TotalVotes)),
+			Weight:         s.weights.Accuracy,
+			AccuracyRate:   roundWeight(user.AccuracyRate),
+			DefaultApplied: user.TotalVotes < minVotesForAccuracy,
+			MinVotes:       minVotesForAccuracy,
+			VotesToMinimum: max(minVotesForAccuracy-user.TotalVotes, 0),
+		},
+		VolumeFactor: model.TrustVolumeFactor{
+			Value:      roundWeight(s.VolumeFactor(user.TotalVotes)),
+			Weight:     s.weights.Volume,
+			TotalVotes: user.TotalVotes,
+			VotesToMax: max(int(volumeVotesMax)-user.TotalVotes, 0),
+		},
+		BaseWeight:      s.BaseWeight(user),
+		ActivityFactor:  roundWeight(s.ActivityFactor(user)),
+		EffectiveWeight: roundWeight(s.EffectiveWeight(user)),
+	}
+}
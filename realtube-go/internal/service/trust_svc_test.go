@@ -9,7 +9,7 @@ import (
 )
 
 func TestAgeFactor(t *testing.T) {
-	svc := NewTrustService()
+	svc := NewTrustService(TrustDecay{})
 
 	tests := []struct {
 		name      string
@@ -36,7 +36,7 @@ func TestAgeFactor(t *testing.T) {
 }
 
 func TestAccuracyFactor(t *testing.T) {
-	svc := NewTrustService()
+	svc := NewTrustService(TrustDecay{})
 
 	tests := []struct {
 		name         string
@@ -62,7 +62,7 @@ func TestAccuracyFactor(t *testing.T) {
 }
 
 func TestVolumeFactor(t *testing.T) {
-	svc := NewTrustService()
+	svc := NewTrustService(TrustDecay{})
 
 	tests := []struct {
 		name       string
@@ -90,7 +90,7 @@ func almostEqual(a, b, epsilon float64) bool {
 }
 
 func TestComputeTrustScore(t *testing.T) {
-	svc := NewTrustService()
+	svc := NewTrustService(TrustDecay{})
 
 	tests := []struct {
 		name     string
@@ -147,7 +147,7 @@ func TestComputeTrustScore(t *testing.T) {
 }
 
 func TestEffectiveWeight(t *testing.T) {
-	svc := NewTrustService()
+	svc := NewTrustService(TrustDecay{})
 
 	veteranUser := model.User{
 		FirstSeen:    time.Now().AddDate(0, 0, -120),
@@ -184,6 +184,15 @@ func TestEffectiveWeight(t *testing.T) {
 			}(),
 			want: 0.0,
 		},
+		{
+			name: "member of a dampened sybil cluster of 4",
+			user: func() model.User {
+				u := veteranUser
+				u.SybilSplit = 4
+				return u
+			}(),
+			want: trust * BaseWeightRegular / 4,
+		},
 	}
 
 	for _, tt := range tests {
@@ -197,7 +206,7 @@ func TestEffectiveWeight(t *testing.T) {
 }
 
 func TestBaseWeight(t *testing.T) {
-	svc := NewTrustService()
+	svc := NewTrustService(TrustDecay{})
 
 	tests := []struct {
 		name string
@@ -219,3 +228,175 @@ func TestBaseWeight(t *testing.T) {
 		})
 	}
 }
+
+func TestExplain(t *testing.T) {
+	svc := NewTrustService(TrustDecay{})
+
+	t.Run("new user below minimums", func(t *testing.T) {
+		user := model.User{
+			UserID:       "abc",
+			FirstSeen:    time.Now().AddDate(0, 0, -15),
+			AccuracyRate: 0.9,
+			TotalVotes:   4,
+		}
+		got := svc.Explain(&user)
+
+		if got.AgeFactor.AccountAge != 15 || got.AgeFactor.DaysToMax != 45 {
+			t.Errorf("age = %d days, %d to max; want 15, 45", got.AgeFactor.AccountAge, got.AgeFactor.DaysToMax)
+		}
+		if !got.AccuracyFactor.DefaultApplied || got.AccuracyFactor.Value != defaultAccuracy {
+			t.Errorf("accuracy = %.2f (default=%v), want default %.2f", got.AccuracyFactor.Value, got.AccuracyFactor.DefaultApplied, defaultAccuracy)
+		}
+		if got.AccuracyFactor.VotesToMinimum != 6 {
+			t.Errorf("votesToMinimum = %d, want 6", got.AccuracyFactor.VotesToMinimum)
+		}
+		if got.VolumeFactor.VotesToMax != 96 {
+			t.Errorf("votesToMax = %d, want 96", got.VolumeFactor.VotesToMax)
+		}
+		if !almostEqual(got.TrustScore, svc.ComputeTrustScore(&user), 0.001) {
+			t.Errorf("trustScore = %.4f, want %.4f", got.TrustScore, svc.ComputeTrustScore(&user))
+		}
+	})
+
+	t.Run("veteran VIP maxed out", func(t *testing.T) {
+		user := model.User{
+			FirstSeen:    time.Now().AddDate(0, 0, -120),
+			AccuracyRate: 0.95,
+			TotalVotes:   200,
+			IsVIP:        true,
+		}
+		got := svc.Explain(&user)
+
+		if got.AgeFactor.DaysToMax != 0 || got.AccuracyFactor.VotesToMinimum != 0 || got.VolumeFactor.VotesToMax != 0 {
+			t.Errorf("remaining = (%d days, %d votes, %d votes), want all 0",
+				got.AgeFactor.DaysToMax, got.AccuracyFactor.VotesToMinimum, got.VolumeFactor.VotesToMax)
+		}
+		if got.AccuracyFactor.DefaultApplied {
+			t.Error("defaultApplied = true, want false with 200 votes")
+		}
+		if got.BaseWeight != BaseWeightVIP {
+			t.Errorf("baseWeight = %.1f, want %.1f", got.BaseWeight, BaseWeightVIP)
+		}
+		if !almostEqual(got.EffectiveWeight, got.TrustScore*BaseWeightVIP, 0.001) {
+			t.Errorf("effectiveWeight = %.4f, want %.4f", got.EffectiveWeight, got.TrustScore*BaseWeightVIP)
+		}
+	})
+}
+
+func TestActivityFactor(t *testing.T) {
+	svc := NewTrustService(TrustDecay{GraceDays: 90, HalfLifeDays: 30, RecoveryDays: 30})
+	daysAgo := func(d int) time.Time { return time.Now().AddDate(0, 0, -d) }
+	ptr := func(t time.Time) *time.Time { return &t }
+
+	tests := []struct {
+		name    string
+		user    model.User
+		wantMin float64
+		wantMax float64
+	}{
+		{"active user", model.User{LastActive: daysAgo(1)}, 1.0, 1.0},
+		{"within grace period", model.User{LastActive: daysAgo(89)}, 1.0, 1.0},
+		{"one half-life past grace", model.User{LastActive: daysAgo(120)}, 0.49, 0.51},
+		{"two half-lives past grace", model.User{LastActive: daysAgo(150)}, 0.24, 0.26},
+		{
+			"reactivated, halfway recovered",
+			model.User{LastActive: daysAgo(0), DormancyFactor: 0.2, ReactivatedAt: ptr(daysAgo(15))},
+			0.59, 0.61,
+		},
+		{
+			"reactivated, fully recovered",
+			model.User{LastActive: daysAgo(0), DormancyFactor: 0.2, ReactivatedAt: ptr(daysAgo(45))},
+			1.0, 1.0,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := svc.ActivityFactor(&tt.user)
+			if got < tt.wantMin || got > tt.wantMax {
+				t.Errorf("ActivityFactor() = %.4f, want [%.2f, %.2f]", got, tt.wantMin, tt.wantMax)
+			}
+		})
+	}
+}
+
+func TestActivityFactor_DecayDisabled(t *testing.T) {
+	svc := NewTrustService(TrustDecay{})
+	user := model.User{LastActive: time.Now().AddDate(-2, 0, 0)}
+	if got := svc.ActivityFactor(&user); got != 1.0 {
+		t.Errorf("ActivityFactor() = %.4f, want 1.0 with decay disabled", got)
+	}
+}
+
+func TestRecordActivity(t *testing.T) {
+	svc := NewTrustService(TrustDecay{GraceDays: 90, HalfLifeDays: 30, RecoveryDays: 30})
+	now := time.Now()
+
+	t.Run("dormant user freezes decay", func(t *testing.T) {
+		user := model.User{LastActive: now.AddDate(0, 0, -120), DormancyFactor: 1.0}
+		svc.RecordActivity(&user, now)
+
+		if !user.LastActive.Equal(now) {
+			t.Errorf("LastActive = %v, want %v", user.LastActive, now)
+		}
+		if user.ReactivatedAt == nil || !user.ReactivatedAt.Equal(now) {
+			t.Fatalf("ReactivatedAt = %v, want %v", user.ReactivatedAt, now)
+		}
+		if !almostEqual(user.DormancyFactor, 0.5, 0.01) {
+			t.Errorf("DormancyFactor = %.4f, want 0.50", user.DormancyFactor)
+		}
+		if got := svc.ActivityFactor(&user); !almostEqual(got, 0.5, 0.01) {
+			t.Errorf("ActivityFactor() right after reactivation = %.4f, want 0.50", got)
+		}
+	})
+
+	t.Run("active user keeps state", func(t *testing.T) {
+		user := model.User{LastActive: now.AddDate(0, 0, -3), DormancyFactor: 1.0}
+		svc.RecordActivity(&user, now)
+
+		if user.ReactivatedAt != nil {
+			t.Errorf("ReactivatedAt = %v, want nil", user.ReactivatedAt)
+		}
+		if user.DormancyFactor != 1.0 {
+			t.Errorf("DormancyFactor = %.4f, want 1.0", user.DormancyFactor)
+		}
+	})
+}
+
+func TestEffectiveWeight_AppliesDecay(t *testing.T) {
+	svc := NewTrustService(TrustDecay{GraceDays: 90, HalfLifeDays: 30, RecoveryDays: 30})
+	user := model.User{
+		FirstSeen:    time.Now().AddDate(0, 0, -400),
+		LastActive:   time.Now().AddDate(0, 0, -120),
+		AccuracyRate: 0.95,
+		TotalVotes:   200,
+	}
+
+	want := svc.ComputeTrustScore(&user) * 0.5
+	if got := svc.EffectiveWeight(&user); !almostEqual(got, want, 0.01) {
+		t.Errorf("EffectiveWeight() = %.4f, want %.4f", got, want)
+	}
+}
+
+func TestWithWeights(t *testing.T) {
+	svc := NewTrustService(TrustDecay{})
+	alt := svc.WithWeights(TrustWeights{Age: 0.2, Accuracy: 0.6, Volume: 0.2})
+
+	user := model.User{
+		FirstSeen:    time.Now().AddDate(0, 0, -30),
+		AccuracyRate: 0.7,
+		TotalVotes:   50,
+	}
+
+	// age=0.5, accuracy=0.7, volume=0.5
+	// 0.5*0.2 + 0.7*0.6 + 0.5*0.2 = 0.10 + 0.42 + 0.10 = 0.62
+	if got := alt.ComputeTrustScore(&user); !almostEqual(got, 0.62, 0.01) {
+		t.Errorf("alternate ComputeTrustScore() = %.4f, want 0.62", got)
+	}
+	if got := svc.ComputeTrustScore(&user); !almostEqual(got, 0.60, 0.01) {
+		t.Errorf("original ComputeTrustScore() = %.4f, want 0.60 (unchanged)", got)
+	}
+	if got := alt.Explain(&user).AccuracyFactor.Weight; got != 0.6 {
+		t.Errorf("Explain().AccuracyFactor.Weight = %v, want 0.6", got)
+	}
+}
@@ -0,0 +1,209 @@
+package service
+
+import (
+	"context"
+	"log"
+	"math"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+
+	"github.com/mathieu-neron/RealTube/realtube-go/internal/model"
+)
+
+const (
+	// Maximum number of users recomputed per transaction.
+	trustBatchSize = 500
+
+	// Votes are only re-weighted when the effective weight moved by at least
+	// this much. The age factor grows continuously, so without a tolerance
+	// every young account would rewrite its votes (and trigger rescoring)
+	// on every tick.
+	trustReweightEpsilon = 0.01
+)
+
+// TrustWorker is a periodic background job that recomputes users.trust_score
+// with TrustService, appends changes to user_trust_history and, when enabled,
+// re-applies the resulting effective weight (including inactivity decay) to
+// the user's existing votes (trust-system-design.md §9).
+//
+// Re-weighted votes fire the vote_changes trigger, which queues their videos
+// for recalculation by the ScoreWorker.
+type TrustWorker struct {
+	pool     *pgxpool.Pool
+	trustSvc *TrustService
+	interval time.Duration
+	reweight bool
+	stopCh   chan struct{}
+}
+
+// NewTrustWorker creates a worker that ticks every interval. If reweight is
+// true, existing votes are updated to the user's current effective weight.
+func NewTrustWorker(pool *pgxpool.Pool, trustSvc *TrustService, interval time.Duration, reweight bool) *TrustWorker {
+	return &TrustWorker{
+		pool:     pool,
+		trustSvc: trustSvc,
+		interval: interval,
+		reweight: reweight,
+		stopCh:   make(chan struct{}),
+	}
+}
+
+// Start begins the periodic trust recomputation loop.
+// It runs one tick immediately, then every interval.
+func (w *TrustWorker) Start(ctx context.Context) {
+	log.Printf("trust-worker: starting (interval=%s, reweight=%t)", w.interval, w.reweight)
+
+	w.tick(ctx)
+
+	ticker := time.NewTicker(w.interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			w.tick(ctx)
+		case <-ctx.Done():
+			log.Println("trust-worker: stopping (context cancelled)")
+			return
+		case <-w.stopCh:
+			log.Println("trust-worker: stopping (stop signal)")
+			return
+		}
+	}
+}
+
+// Stop signals the worker to stop.
+func (w *TrustWorker) Stop() {
+	close(w.stopCh)
+}
+
+// tick recomputes trust for every user who has voted, in user_id order.
+func (w *TrustWorker) tick(ctx context.Context) {
+	start := time.Now()
+
+	users, votes := 0, 0
+	after := ""
+	for {
+		n, v, last, err := w.recomputeBatch(ctx, after)
+		if err != nil {
+			log.Printf("trust-worker: error: %v", err)
+			return
+		}
+		users += n
+		votes += v
+		if n < trustBatchSize || ctx.Err() != nil {
+			break
+		}
+		after = last
+	}
+
+	elapsed := time.Since(start)
+	log.Printf("trust-worker: tick complete — %d users recomputed, %d votes re-weighted (%s)",
+		users, votes, elapsed.Round(time.Millisecond))
+}
+
+// recomputeBatch recomputes trust for up to trustBatchSize users whose ID
+// sorts after the given cursor. It returns the number of users and votes
+// updated and the last user ID processed.
+func (w *TrustWorker) recomputeBatch(ctx context.Context, after string) (users, votes int, last string, err error) {
+	tx, err := w.pool.Begin(ctx)
+	if err != nil {
+		return 0, 0, "", err
+	}
+	defer tx.Rollback(ctx)
+
+	rows, err := tx.Query(ctx, `
+		SELECT user_id, accuracy_rate, total_votes, first_seen, last_active,
+		       is_vip, is_shadowbanned, dormancy_factor, reactivated_at, sybil_split
+		FROM users
+		WHERE total_votes > 0 AND user_id > $1
+		ORDER BY user_id
+		LIMIT $2`,
+		after, trustBatchSize)
+	if err != nil {
+		return 0, 0, "", err
+	}
+	defer rows.Close()
+
+	var userIDs []string
+	var trustScores, activity, weights []float64
+
+	for rows.Next() {
+		var u model.User
+		err := rows.Scan(&u.UserID, &u.AccuracyRate, &u.TotalVotes, &u.FirstSeen, &u.LastActive,
+			&u.IsVIP, &u.IsShadowbanned, &u.DormancyFactor, &u.ReactivatedAt, &u.SybilSplit)
+		if err != nil {
+			return 0, 0, "", err
+		}
+
+		userIDs = append(userIDs, u.UserID)
+		trustScores = append(trustScores, roundWeight(w.trustSvc.ComputeTrustScore(&u)))
+		activity = append(activity, roundWeight(w.trustSvc.ActivityFactor(&u)))
+		weights = append(weights, roundWeight(w.trustSvc.EffectiveWeight(&u)))
+	}
+	if err := rows.Err(); err != nil {
+		return 0, 0, "", err
+	}
+
+	if len(userIDs) == 0 {
+		return 0, 0, "", nil
+	}
+
+	// Record a history point for users whose trust or activity moved since
+	// their last recorded point (or who have none yet)
+	_, err = tx.Exec(ctx, `
+		INSERT INTO user_trust_history (user_id, trust_score, accuracy_rate, total_votes, activity_factor)
+		SELECT d.user_id, d.trust_score, u.accuracy_rate, u.total_votes, d.activity
+		FROM unnest($1::text[], $2::float8[], $3::float8[]) AS d(user_id, trust_score, activity)
+		JOIN users u ON u.user_id = d.user_id
+		LEFT JOIN LATERAL (
+			SELECT h.trust_score, h.activity_factor
+			FROM user_trust_history h
+			WHERE h.user_id = d.user_id
+			ORDER BY h.recorded_at DESC
+			LIMIT 1
+		) last ON true
+		WHERE last.trust_score IS NULL
+		   OR abs(last.trust_score - d.trust_score) >= $4
+		   OR abs(last.activity_factor - d.activity) >= $4`,
+		userIDs, trustScores, activity, trustReweightEpsilon)
+	if err != nil {
+		return 0, 0, "", err
+	}
+
+	_, err = tx.Exec(ctx, `
+		UPDATE users u
+		SET trust_score = d.trust_score
+		FROM unnest($1::text[], $2::float8[]) AS d(user_id, trust_score)
+		WHERE u.user_id = d.user_id`,
+		userIDs, trustScores)
+	if err != nil {
+		return 0, 0, "", err
+	}
+
+	if w.reweight {
+		tag, err := tx.Exec(ctx, `
+			UPDATE votes v
+			SET trust_weight = d.weight
+			FROM unnest($1::text[], $2::float8[]) AS d(user_id, weight)
+			WHERE v.user_id = d.user_id
+			  AND abs(v.trust_weight - d.weight) >= $3`,
+			userIDs, weights, trustReweightEpsilon)
+		if err != nil {
+			return 0, 0, "", err
+		}
+		votes = int(tag.RowsAffected())
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return 0, 0, "", err
+	}
+	return len(userIDs), votes, userIDs[len(userIDs)-1], nil
+}
+
+// roundWeight rounds a trust score or vote weight to 4 decimal places so
+// stored values stay stable between recomputations.
+func roundWeight(w float64) float64 {
+	return math.Round(w*10000) / 10000
+}
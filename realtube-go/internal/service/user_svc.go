@@ -2,19 +2,23 @@ package service
 
 import (
 	"context"
+	"errors"
 	"math"
 	"time"
 
+	"github.com/jackc/pgx/v5"
+
 	"github.com/mathieu-neron/RealTube/realtube-go/internal/model"
 	"github.com/mathieu-neron/RealTube/realtube-go/internal/repository"
 )
 
 type UserService struct {
-	repo *repository.UserRepo
+	repo  *repository.UserRepo
+	trust *TrustService
 }
 
-func NewUserService(repo *repository.UserRepo) *UserService {
-	return &UserService{repo: repo}
+func NewUserService(repo *repository.UserRepo, trust *TrustService) *UserService {
+	return &UserService{repo: repo, trust: trust}
 }
 
 // Lookup returns the user response for a given user ID.
@@ -51,6 +55,42 @@ func (s *UserService) LookupOrCreate(ctx context.Context, userID string) (*model
 	return s.Lookup(ctx, userID)
 }
 
+// ExplainTrust returns the trust score breakdown for a user, auto-creating a
+// default user if not found (same semantics as LookupOrCreate).
+//
+// Shadowbanned and sybil-dampened users are explained as regular users: the
+// breakdown must not reveal either, or they would simply mint new IDs.
+func (s *UserService) ExplainTrust(ctx context.Context, userID string) (*model.TrustBreakdownResponse, error) {
+	u, err := s.repo.FindByUserID(ctx, userID)
+	if errors.Is(err, pgx.ErrNoRows) {
+		if err := s.repo.CreateIfNotExists(ctx, userID); err != nil {
+			return nil, err
+		}
+		u, err = s.repo.FindByUserID(ctx, userID)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	public := *u
+	public.IsShadowbanned = false
+	public.SybilSplit = 0
+	return s.trust.Explain(&public), nil
+}
+
+// TrustHistory returns up to limit trust history entries for a user,
+// newest first.
+func (s *UserService) TrustHistory(ctx context.Context, userID string, limit int) ([]model.TrustHistoryEntry, error) {
+	entries, err := s.repo.GetTrustHistory(ctx, userID, limit)
+	if err != nil {
+		return nil, err
+	}
+	if entries == nil {
+		entries = []model.TrustHistoryEntry{}
+	}
+	return entries, nil
+}
+
 // GetStats returns aggregate platform statistics.
 func (s *UserService) GetStats(ctx context.Context) (*model.StatsResponse, error) {
 	return s.repo.GetStats(ctx)
@@ -3,33 +3,54 @@ package service
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"log"
 
+	"github.com/jackc/pgx/v5"
+
 	"github.com/mathieu-neron/RealTube/realtube-go/internal/model"
 	"github.com/mathieu-neron/RealTube/realtube-go/internal/repository"
 )
 
 type VideoService struct {
-	repo  *repository.VideoRepo
-	cache *CacheService
+	repo     *repository.VideoRepo
+	channels *repository.ChannelRepo
+	cache    *CacheService
 }
 
-func NewVideoService(repo *repository.VideoRepo, cache *CacheService) *VideoService {
-	return &VideoService{repo: repo, cache: cache}
+func NewVideoService(repo *repository.VideoRepo, channels *repository.ChannelRepo, cache *CacheService) *VideoService {
+	return &VideoService{repo: repo, channels: channels, cache: cache}
 }
 
 // LookupByHashPrefix finds videos by hash prefix and builds API responses with categories.
-func (s *VideoService) LookupByHashPrefix(ctx context.Context, prefix string) ([]model.VideoResponse, error) {
+// If channelID is set and that channel has auto_flag_new, a provisional entry
+// keyed by the channel, with an empty video ID, is appended for the client
+// to apply to the channel's videos that are not in the response.
+func (s *VideoService) LookupByHashPrefix(ctx context.Context, prefix, channelID string) ([]model.VideoResponse, error) {
 	videos, err := s.repo.FindByHashPrefix(ctx, prefix)
 	if err != nil {
 		return nil, err
 	}
-	return s.buildResponses(ctx, videos)
+	responses, err := s.buildResponses(ctx, videos)
+	if err != nil || channelID == "" {
+		return responses, err
+	}
+
+	ch, err := s.channels.FindAutoFlagged(ctx, channelID)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return responses, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return append(responses, provisionalResponse("", ch)), nil
 }
 
 // LookupByVideoID finds a single video by exact ID and builds its API response.
 // Uses cache-aside: check Redis first, fall back to DB, then populate cache.
-func (s *VideoService) LookupByVideoID(ctx context.Context, videoID string) (*model.VideoResponse, error) {
+// An unknown video gets a provisional response if channelID is set and that
+// channel has auto_flag_new; provisional responses are not cached.
+func (s *VideoService) LookupByVideoID(ctx context.Context, videoID, channelID string) (*model.VideoResponse, error) {
 	// Try cache first
 	if s.cache != nil {
 		cached, err := s.cache.GetVideo(ctx, videoID)
@@ -45,6 +66,9 @@ func (s *VideoService) LookupByVideoID(ctx context.Context, videoID string) (*mo
 
 	// Cache miss — fetch from DB
 	video, err := s.repo.FindByVideoID(ctx, videoID)
+	if errors.Is(err, pgx.ErrNoRows) && channelID != "" {
+		return s.lookupProvisional(ctx, videoID, channelID)
+	}
 	if err != nil {
 		return nil, err
 	}
@@ -64,6 +88,39 @@ func (s *VideoService) LookupByVideoID(ctx context.Context, videoID string) (*mo
 	return &resp, nil
 }
 
+// lookupProvisional returns a provisional response for a video that has no
+// row, hidden or not, on an auto-flagged channel, or pgx.ErrNoRows.
+func (s *VideoService) lookupProvisional(ctx context.Context, videoID, channelID string) (*model.VideoResponse, error) {
+	exists, err := s.repo.Exists(ctx, videoID)
+	if err != nil {
+		return nil, err
+	}
+	if exists {
+		return nil, pgx.ErrNoRows
+	}
+
+	ch, err := s.channels.FindAutoFlagged(ctx, channelID)
+	if err != nil {
+		return nil, err
+	}
+	resp := provisionalResponse(videoID, ch)
+	return &resp, nil
+}
+
+// provisionalResponse gives a video nobody has voted on the preliminary
+// score of its auto-flagged channel.
+func provisionalResponse(videoID string, ch *model.Channel) model.VideoResponse {
+	return model.VideoResponse{
+		VideoID:      videoID,
+		Score:        PreliminaryScore,
+		Categories:   map[string]*model.CategoryDetail{},
+		ChannelID:    &ch.ChannelID,
+		ChannelScore: ch.Score,
+		Provisional:  true,
+		LastUpdated:  ch.LastUpdated,
+	}
+}
+
 func (s *VideoService) buildResponses(ctx context.Context, videos []model.Video) ([]model.VideoResponse, error) {
 	responses := make([]model.VideoResponse, 0, len(videos))
 	for _, v := range videos {
@@ -96,7 +153,10 @@ func (s *VideoService) buildResponse(ctx context.Context, v model.Video) (model.
 		Categories:  categories,
 		TotalVotes:  v.TotalVotes,
 		Locked:      v.Locked,
+		Disputed:    v.Disputed,
+		Frozen:      v.Frozen,
 		ChannelID:   v.ChannelID,
+		Provisional: v.Provisional,
 		LastUpdated: v.LastUpdated,
 	}, nil
 }
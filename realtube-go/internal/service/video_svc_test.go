@@ -0,0 +1,47 @@
+package service
+
+import (
+	"testing"
+	"time"
+
+	"github.com/mathieu-neron/RealTube/realtube-go/internal/model"
+)
+
+func TestProvisionalResponse(t *testing.T) {
+	updated := time.Date(2026, 2, 6, 12, 0, 0, 0, time.UTC)
+	ch := &model.Channel{ChannelID: "UCuAXFkgsw1L7xaCfnd5JJOw", Score: 86.4, AutoFlagNew: true, LastUpdated: updated}
+
+	resp := provisionalResponse("dQw4w9WgXcQ", ch)
+	if !resp.Provisional {
+		t.Error("Provisional = false, want true")
+	}
+	if resp.VideoID != "dQw4w9WgXcQ" {
+		t.Errorf("VideoID = %q, want dQw4w9WgXcQ", resp.VideoID)
+	}
+	if resp.Score != PreliminaryScore {
+		t.Errorf("Score = %.2f, want %.2f", resp.Score, PreliminaryScore)
+	}
+	if resp.TotalVotes != 0 || len(resp.Categories) != 0 {
+		t.Errorf("TotalVotes = %d, categories = %d, want no votes", resp.TotalVotes, len(resp.Categories))
+	}
+	if resp.Categories == nil {
+		t.Error("Categories = nil, want empty map")
+	}
+	if resp.ChannelID == nil || *resp.ChannelID != ch.ChannelID {
+		t.Errorf("ChannelID = %v, want %s", resp.ChannelID, ch.ChannelID)
+	}
+	if resp.ChannelScore != 86.4 || !resp.LastUpdated.Equal(updated) {
+		t.Errorf("ChannelScore = %.2f, LastUpdated = %s, want channel's", resp.ChannelScore, resp.LastUpdated)
+	}
+}
+
+func TestProvisionalResponse_PrefixEntry(t *testing.T) {
+	ch := &model.Channel{ChannelID: "UCuAXFkgsw1L7xaCfnd5JJOw", AutoFlagNew: true}
+
+	// Hash-prefix lookups don't know the video: the entry is keyed by channel
+	resp := provisionalResponse("", ch)
+	if resp.VideoID != "" || !resp.Provisional || resp.ChannelID == nil || *resp.ChannelID != ch.ChannelID {
+		t.Errorf("VideoID = %q, Provisional = %v, ChannelID = %v, want channel-wide provisional entry",
+			resp.VideoID, resp.Provisional, resp.ChannelID)
+	}
+}
@@ -10,21 +10,37 @@ import (
 )
 
 type VoteService struct {
-	repo  *repository.VoteRepo
-	cache *CacheService
+	repo       *repository.VoteRepo
+	ips        *IPHashService
+	challenges *ChallengeService
+	trust      *TrustService
+	cache      *CacheService
 }
 
-func NewVoteService(repo *repository.VoteRepo, cache *CacheService) *VoteService {
-	return &VoteService{repo: repo, cache: cache}
+func NewVoteService(repo *repository.VoteRepo, ips *IPHashService, challenges *ChallengeService, trust *TrustService,
+	cache *CacheService) *VoteService {
+	return &VoteService{repo: repo, ips: ips, challenges: challenges, trust: trust, cache: cache}
 }
 
-// Submit processes a vote submission request.
-func (s *VoteService) Submit(ctx context.Context, req model.VoteRequest, ipHash string) (*model.VoteResponse, error) {
+// Submit processes a vote submission request from the client IP ip. Only the
+// salted hash of the IP is stored. Returns ErrChallengeRequired or
+// ErrChallengeInvalid if the user must solve a challenge and hasn't, and
+// ErrIPRateLimited if the IP has gone over its daily vote limit.
+func (s *VoteService) Submit(ctx context.Context, req model.VoteRequest, ip string) (*model.VoteResponse, error) {
 	if !repository.ValidCategories[req.Category] {
 		return nil, fmt.Errorf("invalid category: %s", req.Category)
 	}
 
-	trustWeight, err := s.repo.SubmitVote(ctx, req.VideoID, req.UserID, req.Category, ipHash, req.UserAgent)
+	ipHash := s.ips.Hash(ip)
+	if err := s.challenges.Verify(ctx, req, ipHash); err != nil {
+		return nil, err
+	}
+	if err := s.ips.RecordVote(ctx, ip, ipHash, req.UserID); err != nil {
+		return nil, err
+	}
+
+	trustScore, err := s.repo.SubmitVote(ctx, req.VideoID, req.UserID, req.Category, ipHash, req.UserAgent,
+		videoMetadata(req), s.trust)
 	if err != nil {
 		return nil, err
 	}
@@ -45,10 +61,24 @@ func (s *VoteService) Submit(ctx context.Context, req model.VoteRequest, ipHash
 	return &model.VoteResponse{
 		Success:   true,
 		NewScore:  score,
-		UserTrust: trustWeight,
+		UserTrust: trustScore,
 	}, nil
 }
 
+// videoMetadata extracts the optional video metadata of a validated request.
+func videoMetadata(req model.VoteRequest) model.VideoMetadata {
+	var meta model.VideoMetadata
+	if req.ChannelID != "" {
+		meta.ChannelID = &req.ChannelID
+	}
+	if req.Title != "" {
+		meta.Title = &req.Title
+	}
+	meta.VideoDuration = req.VideoDuration
+	meta.IsShort = req.IsShort
+	return meta
+}
+
 // Delete removes a user's vote and recalculates the video score.
 func (s *VoteService) Delete(ctx context.Context, req model.VoteDeleteRequest) error {
 	if err := s.repo.DeleteVote(ctx, req.VideoID, req.UserID); err != nil {